@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/builder"
+)
+
+func TestParseAddresses(t *testing.T) {
+	testCases := []struct {
+		args      []string
+		addresses []uint64
+		err       string
+	}{
+		{args: nil, addresses: nil},
+		{args: []string{"0x2b9"}, addresses: []uint64{0x2b9}},
+		{args: []string{"2b9", "0x1F3", "0x151"}, addresses: []uint64{0x2b9, 0x1f3, 0x151}},
+		{args: []string{"0xffffffffffffffff"}, addresses: []uint64{0xffffffffffffffff}},
+		{args: []string{"0x"}, err: `invalid address "0x"`},
+		{args: []string{"0x10", "main.main"}, err: `invalid address "main.main"`},
+		{args: []string{"0x10000000000000000"}, err: `invalid address "0x10000000000000000"`},
+	}
+	for _, tc := range testCases {
+		addresses, err := parseAddresses(tc.args)
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("parseAddresses(%q): expected error %q, got %v", tc.args, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAddresses(%q): unexpected error: %v", tc.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(addresses, tc.addresses) {
+			t.Errorf("parseAddresses(%q): expected %#x, got %#x", tc.args, tc.addresses, addresses)
+		}
+	}
+}
+
+func TestParseBacktrace(t *testing.T) {
+	testCases := []struct {
+		line      string
+		addresses []uint64
+	}{
+		{line: "backtrace: 0x2b9 0x1f3 0x151", addresses: []uint64{0x2b9, 0x1f3, 0x151}},
+		{line: "panic: oops backtrace: 0x2b9", addresses: []uint64{0x2b9}},
+		{line: "backtrace:"},
+		{line: "no backtrace: here"},
+		{line: "hello world"},
+	}
+	for _, tc := range testCases {
+		addresses, ok := parseBacktrace(tc.line)
+		if ok != (tc.addresses != nil) || !reflect.DeepEqual(addresses, tc.addresses) {
+			t.Errorf("parseBacktrace(%q): expected %#x, got %#x (ok=%v)", tc.line, tc.addresses, addresses, ok)
+		}
+	}
+}
+
+// buildAddr2LineProgram compiles builder/testdata/program.c (like the tests in
+// the builder package) and returns the executable with the return addresses of
+// the calls from compute to add and from _start to compute.
+func buildAddr2LineProgram(t *testing.T) (string, []uint64) {
+	dir := t.TempDir()
+	object := filepath.Join(dir, "program.o")
+	err := builder.RunTool("clang", "--target=x86_64-unknown-linux", "-g", "-O1", "-fno-inline", "-fno-pic", "-ffreestanding", "-fno-stack-protector", "-c", "-o", object, filepath.Join("builder", "testdata", "program.c"))
+	if err != nil {
+		t.Fatal("could not compile program.c:", err)
+	}
+	executable := filepath.Join(dir, "program.elf")
+	err = builder.RunTool("ld.lld", "-static", "-e", "_start", "-o", executable, object)
+	if err != nil {
+		t.Fatal("could not link program.c:", err)
+	}
+
+	f, err := elf.Open(executable)
+	if err != nil {
+		t.Fatal("could not open ELF file:", err)
+	}
+	defer f.Close()
+	symbols, err := f.Symbols()
+	if err != nil {
+		t.Fatal("could not read symbols:", err)
+	}
+	functions := make(map[string]elf.Symbol)
+	for _, symbol := range symbols {
+		functions[symbol.Name] = symbol
+	}
+	var addresses []uint64
+	for _, call := range [][2]string{{"compute", "add"}, {"_start", "compute"}} {
+		caller, callee := functions[call[0]], functions[call[1]]
+		code := make([]byte, caller.Size)
+		section := f.Sections[caller.Section]
+		if _, err := section.ReadAt(code, int64(caller.Value-section.Addr)); err != nil {
+			t.Fatal("could not read code:", err)
+		}
+		// Look for a call instruction with a 32-bit relative address (0xe8).
+		for i := 0; i+5 <= len(code); i++ {
+			returnAddress := caller.Value + uint64(i) + 5
+			offset := int32(binary.LittleEndian.Uint32(code[i+1:]))
+			if code[i] == 0xe8 && returnAddress+uint64(int64(offset)) == callee.Value {
+				addresses = append(addresses, returnAddress)
+				break
+			}
+		}
+	}
+	if len(addresses) != 2 {
+		t.Fatalf("could not find the calls in program.c, found return addresses %#x", addresses)
+	}
+	return executable, addresses
+}
+
+func TestAddr2Line(t *testing.T) {
+	executable, addresses := buildAddr2LineProgram(t)
+	input := fmt.Sprintf("panic: oops\nbacktrace: %#x %#x\nexit\n", addresses[0], addresses[1])
+	buf := &bytes.Buffer{}
+	err := Addr2Line(executable, nil, strings.NewReader(input), buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			// Only keep the file name, the directory depends on where the
+			// executable was built.
+			index := strings.LastIndex(line, "program.c:")
+			if index < 0 {
+				t.Fatalf("unexpected source location: %q", line)
+			}
+			line = "\t" + line[index:]
+		}
+		lines = append(lines, line)
+	}
+	expected := []string{
+		"panic: oops",
+		"compute()",
+		"\tprogram.c:18",
+		"_start()",
+		"\tprogram.c:24",
+		"exit",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("unexpected output:\nexpected:\n%s\nactual:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
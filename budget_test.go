@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test that a program that doesn't fit in the memory of the target fails to
+// build with a report of the size of each package.
+func TestMemoryBudget(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("only tested on cortex-m-qemu, which is skipped with -short")
+	}
+
+	// The program doesn't fit in the 4kB flash region of the linker script,
+	// so the linker fails before the size can be checked.
+	t.Run("LinkerRegion", func(t *testing.T) {
+		t.Parallel()
+		checkMemoryBudget(t, filepath.Join(TESTDATA, "memory-budget.json"), "of flash which exceeds the budget of 4096 bytes")
+	})
+
+	// The program fits in flash, but not in the budget.
+	t.Run("Budget", func(t *testing.T) {
+		t.Parallel()
+		target := filepath.Join(t.TempDir(), "target.json")
+		err := os.WriteFile(target, []byte(`{"inherits": ["cortex-m-qemu"], "ram-budget": 1024}`), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkMemoryBudget(t, target, "of RAM which exceeds the budget of 1024 bytes")
+	})
+}
+
+// checkMemoryBudget builds testdata/stdlib.go for the given target and checks
+// that it fails with the expected memory budget error.
+func checkMemoryBudget(t *testing.T, target, expected string) {
+	options := optionsFromTarget(target, sema)
+	err := Build("./"+TESTDATA+"/stdlib.go", filepath.Join(t.TempDir(), "stdlib.elf"), &options)
+	if err == nil {
+		t.Fatal("expected build to fail")
+	}
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "program uses ") || !strings.Contains(msg, expected) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{"| runtime\n", "| total"} {
+		if !strings.Contains(msg, line) {
+			t.Errorf("expected %q in the package table:\n%s", line, msg)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package builder
+
+import (
+	"debug/elf"
+	"sort"
+)
+
+// AddressLocation is the function and source location of a single address in
+// a program, as far as it is known.
+type AddressLocation struct {
+	Address  uint64
+	Function string
+	File     string
+	Line     int
+}
+
+// LookupReturnAddresses maps the given return addresses (as printed in a
+// backtrace on panic) back to the function and source location of the call
+// instruction, using the symbol table and the DWARF line tables of the given
+// ELF file.
+func LookupReturnAddresses(executable string, addresses []uint64) ([]AddressLocation, error) {
+	file, err := elf.Open(executable)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	funcs, err := readFunctionSymbols(file)
+	if err != nil {
+		return nil, err
+	}
+	var lines []lineChunk
+	if data, err := file.DWARF(); err == nil {
+		lines, err = readLineTable(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	locations := make([]AddressLocation, len(addresses))
+	for i, address := range addresses {
+		locations[i].Address = address
+
+		// A return address points to the instruction after the call, which
+		// may be on a different line. Look up the call itself instead.
+		pc := address
+		if file.Machine == elf.EM_ARM {
+			// Remove the Thumb bit.
+			pc &^= 1
+		}
+		pc--
+
+		index := sort.Search(len(funcs), func(i int) bool {
+			return funcs[i].Address > pc
+		})
+		if index > 0 && pc < funcs[index-1].Address+funcs[index-1].Size {
+			locations[i].Function = funcs[index-1].Name
+		}
+		index = sort.Search(len(lines), func(i int) bool {
+			return lines[i].Address > pc
+		})
+		if index > 0 && pc < lines[index-1].Address+lines[index-1].Length {
+			locations[i].File = lines[index-1].File
+			locations[i].Line = lines[index-1].Line
+		}
+	}
+	return locations, nil
+}
@@ -0,0 +1,94 @@
+package builder
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+// buildTestProgram compiles testdata/program.c to a small x86-64 executable
+// with debug information, using the clang and ld.lld that come with TinyGo.
+func buildTestProgram(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	object := filepath.Join(dir, "program.o")
+	err := RunTool("clang", "--target=x86_64-unknown-linux", "-g", "-O1", "-fno-inline", "-fno-pic", "-ffreestanding", "-fno-stack-protector", "-c", "-o", object, filepath.Join("testdata", "program.c"))
+	if err != nil {
+		t.Fatal("could not compile testdata/program.c:", err)
+	}
+	executable := filepath.Join(dir, "program.elf")
+	err = RunTool("ld.lld", "-static", "-e", "_start", "-o", executable, object)
+	if err != nil {
+		t.Fatal("could not link testdata/program.c:", err)
+	}
+	return executable
+}
+
+// findCall returns the return address of the first call from caller to callee
+// in the given x86-64 executable.
+func findCall(t *testing.T, executable, caller, callee string) uint64 {
+	t.Helper()
+	f, err := elf.Open(executable)
+	if err != nil {
+		t.Fatal("could not open ELF file:", err)
+	}
+	defer f.Close()
+	symbols, err := f.Symbols()
+	if err != nil {
+		t.Fatal("could not read symbols:", err)
+	}
+	var callerSymbol, calleeSymbol elf.Symbol
+	for _, symbol := range symbols {
+		switch symbol.Name {
+		case caller:
+			callerSymbol = symbol
+		case callee:
+			calleeSymbol = symbol
+		}
+	}
+	code := make([]byte, callerSymbol.Size)
+	_, err = f.Sections[callerSymbol.Section].ReadAt(code, int64(callerSymbol.Value-f.Sections[callerSymbol.Section].Addr))
+	if err != nil {
+		t.Fatal("could not read code:", err)
+	}
+	// Look for a call instruction with a 32-bit relative address (0xe8).
+	for i := 0; i+5 <= len(code); i++ {
+		returnAddress := callerSymbol.Value + uint64(i) + 5
+		offset := int32(binary.LittleEndian.Uint32(code[i+1:]))
+		if code[i] == 0xe8 && returnAddress+uint64(int64(offset)) == calleeSymbol.Value {
+			return returnAddress
+		}
+	}
+	t.Fatalf("no call from %s to %s", caller, callee)
+	return 0
+}
+
+func TestLookupReturnAddresses(t *testing.T) {
+	executable := buildTestProgram(t)
+	addresses := []uint64{
+		findCall(t, executable, "compute", "add"),
+		findCall(t, executable, "_start", "compute"),
+		1 << 40, // outside of any function
+	}
+	locations, err := LookupReturnAddresses(executable, addresses)
+	if err != nil {
+		t.Fatal("could not read ELF file:", err)
+	}
+	expected := []AddressLocation{
+		{Address: addresses[0], Function: "compute", File: "program.c", Line: 18},
+		{Address: addresses[1], Function: "_start", File: "program.c", Line: 24},
+		{Address: addresses[2]},
+	}
+	if len(locations) != len(expected) {
+		t.Fatalf("expected %d locations, got %d", len(expected), len(locations))
+	}
+	for i, location := range locations {
+		if location.File != "" {
+			location.File = filepath.Base(location.File)
+		}
+		if location != expected[i] {
+			t.Errorf("unexpected location for %#x:\nexpected: %+v\nactual:   %+v", addresses[i], expected[i], location)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package builder
 
 import (
+	"bytes"
 	"crypto/sha512"
 	"debug/elf"
 	"encoding/binary"
@@ -41,6 +42,16 @@ type BuildResult struct {
 	// if it should be kept it must be copied or moved away.
 	Binary string
 
+	// A path to the executable (usually an ELF file) the binary was created
+	// from, for example to decode backtraces. Like Binary, it is removed after
+	// Build returns.
+	Executable string
+
+	// A path to the generated C header of a library built with
+	// -buildmode=c-archive or -buildmode=c-shared, or the empty string for
+	// other build modes. Like Binary, it is removed after Build returns.
+	Header string
+
 	// The directory of the main package. This is useful for testing as the test
 	// binary must be run in the directory of the tested package.
 	MainDir string
@@ -172,6 +183,8 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		AutomaticStackSize: config.AutomaticStackSize(),
 		DefaultStackSize:   config.Target.DefaultStackSize,
 		NeedsStackObjects:  config.NeedsStackObjects(),
+		ReflectMethods:     config.Options.ReflectMethods,
+		FramePointers:      config.FramePointers(),
 		Debug:              true,
 	}
 
@@ -199,6 +212,23 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	// program so it's pretty fast and doesn't need to be parallelized.
 	program := lprogram.LoadSSA()
 
+	// Libraries export the //export functions of the main package, which are
+	// declared in a generated C header.
+	var libraryExports []libraryExport
+	header := ""
+	if config.IsLibrary() {
+		err := checkLibraryCGo(config, lprogram)
+		if err != nil {
+			return err
+		}
+		libraryExports = findLibraryExports(lprogram.MainPkg())
+		header = filepath.Join(dir, "main.h")
+		err = writeLibraryHeader(header, lprogram.MainPkg(), libraryExports, program.Fset, compiler.Sizes(machine), config.BuildMode())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Add jobs to compile each package.
 	// Packages that have a cache hit will not be compiled again.
 	var packageJobs []*compileJob
@@ -218,6 +248,10 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		}
 		config.Options.GlobalValues["runtime"]["buildVersion"] = version
 	}
+	if slice := config.PreemptTimeSlice(); slice != 0 {
+		// The time slice is passed in microseconds, see preempt_cortexm.go.
+		config.Options.GlobalValues["runtime"]["preemptTimeSlice"] = strconv.FormatInt(slice.Microseconds(), 10)
+	}
 
 	for _, pkg := range lprogram.Sorted() {
 		pkg := pkg // necessary to avoid a race condition
@@ -427,6 +461,7 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	// Add job that links and optimizes all packages together.
 	var mod llvm.Module
 	var stackSizeLoads []string
+	var usesPCTable bool
 	programJob := &compileJob{
 		description:  "link+optimize packages (LTO)",
 		dependencies: packageJobs,
@@ -491,6 +526,27 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 				}
 			}
 
+			// Libraries only expose the functions exported from the main
+			// package, see internalizeLibrarySymbols.
+			if config.IsLibrary() {
+				internalizeLibrarySymbols(mod, libraryExports)
+			}
+
+			// The PC table is only added when linking (see addPCTable). If
+			// it won't be added, define an empty table instead so that
+			// lookups are optimized away.
+			if pcTable := mod.NamedGlobal("runtime.pcTable"); !pcTable.IsNil() {
+				switch filepath.Ext(outpath) {
+				case ".o", ".bc", ".ll":
+					// Not linked by TinyGo.
+					defineEmptyPCTable(pcTable)
+				default:
+					if !config.PCTable() {
+						defineEmptyPCTable(pcTable)
+					}
+				}
+			}
+
 			if config.Options.PrintIR {
 				fmt.Println("; Generated LLVM IR:")
 				fmt.Println(mod.String())
@@ -503,6 +559,13 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 				return err
 			}
 
+			// The PC table (and with it the second link in addPCTable) is
+			// only needed when the optimized program still refers to it.
+			if config.PCTable() {
+				pcTable := mod.NamedGlobal("runtime.pcTable")
+				usesPCTable = !pcTable.IsNil() && !pcTable.FirstUse().IsNil()
+			}
+
 			// Make sure stack sizes are loaded from a separate section so they can be
 			// modified after linking.
 			if config.AutomaticStackSize() {
@@ -578,12 +641,22 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	if config.GOOS() == "windows" {
 		executable += ".exe"
 	}
+	switch config.BuildMode() {
+	case "c-archive":
+		executable = filepath.Join(dir, "main.a")
+	case "c-shared":
+		executable = filepath.Join(dir, "main.so")
+	}
 	tmppath := executable // final file
 	ldflags := append(config.LDFlags(), "-o", executable)
+	if config.BuildMode() == "c-shared" {
+		ldflags = append(ldflags, "-shared")
+	}
 
 	// Add compiler-rt dependency if needed. Usually this is a simple load from
-	// a cache.
-	if config.Target.RTLib == "compiler-rt" {
+	// a cache. Static libraries use the one of the C program they're linked
+	// into.
+	if config.Target.RTLib == "compiler-rt" && config.BuildMode() != "c-archive" {
 		job, unlock, err := CompilerRT.load(config, dir)
 		if err != nil {
 			return err
@@ -596,6 +669,9 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	// contain things like the interrupt vector table and low level operations
 	// such as stack switching.
 	for _, path := range config.ExtraFiles() {
+		if config.IsLibrary() && !isLibraryExtraFile(path) {
+			continue
+		}
 		abspath := filepath.Join(root, path)
 		job := &compileJob{
 			description: "compile extra file " + path,
@@ -633,8 +709,11 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		ldflags = append(ldflags, lprogram.LDFlags...)
 	}
 
-	// Add libc dependencies, if they exist.
-	linkerDependencies = append(linkerDependencies, libcDependencies...)
+	// Add libc dependencies, if they exist. Libraries use the libc of the C
+	// program instead.
+	if !config.IsLibrary() {
+		linkerDependencies = append(linkerDependencies, libcDependencies...)
+	}
 
 	// Strip debug information with -no-debug.
 	if !config.Debug() {
@@ -672,17 +751,50 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		}
 	}
 
+	// Used to attribute the size of the program to packages.
+	packagePathMap := make(map[string]string, len(lprogram.Packages))
+	for _, pkg := range lprogram.Sorted() {
+		packagePathMap[pkg.OriginalDir()] = pkg.Pkg.Path()
+	}
+
+	// Object file with the PC table, see addPCTable.
+	pcTableObject := filepath.Join(dir, "pctable.o")
+
 	// Create a linker job, which links all object files together and does some
 	// extra stuff that can only be done after linking.
 	linkJob := &compileJob{
 		description:  "link",
 		dependencies: linkerDependencies,
 		run: func(job *compileJob) error {
+			var objs []string
 			for _, dependency := range job.dependencies {
 				if dependency.result == "" {
 					return errors.New("dependency without result: " + dependency.description)
 				}
-				ldflags = append(ldflags, dependency.result)
+				objs = append(objs, dependency.result)
+			}
+			if config.BuildMode() == "c-archive" {
+				// Static libraries are not linked, the object files are
+				// bundled in an archive instead.
+				f, err := os.Create(executable)
+				if err != nil {
+					return err
+				}
+				err = makeArchive(f, objs)
+				if err != nil {
+					f.Close()
+					return err
+				}
+				return f.Close()
+			}
+			ldflags = append(ldflags, objs...)
+			if usesPCTable {
+				// Placeholder for the PC table, see addPCTable.
+				err := writePCTableObject(pcTableObject, machine, make([]byte, pcTableHeaderSize(machine)))
+				if err != nil {
+					return err
+				}
+				ldflags = append(ldflags, pcTableObject)
 			}
 			if config.Options.PrintCommands != nil {
 				config.Options.PrintCommands(config.Target.Linker, ldflags...)
@@ -707,9 +819,28 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			}
 			err = link(config.Target.Linker, ldflags...)
 			if err != nil {
+				if budgetErr := checkMemoryBudgetAfterLinkError(config, ldflags, dir, executable, packagePathMap); budgetErr != nil {
+					return budgetErr
+				}
 				return &commandError{"failed to link", executable, err}
 			}
 
+			if usesPCTable {
+				// Add a table to symbolize stack traces. This needs to
+				// happen before any other ELF patches, as it may relink the
+				// program.
+				err = addPCTable(executable, pcTableObject, machine, func() error {
+					err := link(config.Target.Linker, ldflags...)
+					if err != nil {
+						return &commandError{"failed to link", executable, err}
+					}
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("could not add PC table: %w", err)
+				}
+			}
+
 			var calculatedStacks []string
 			var stackSizes map[string]functionStackSize
 			if config.Options.PrintStacks || config.AutomaticStackSize() {
@@ -771,30 +902,27 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			}
 
 			// Print code size if requested.
-			if config.Options.PrintSizes == "short" || config.Options.PrintSizes == "full" {
-				packagePathMap := make(map[string]string, len(lprogram.Packages))
-				for _, pkg := range lprogram.Sorted() {
-					packagePathMap[pkg.OriginalDir()] = pkg.Pkg.Path()
-				}
+			// Also check whether the program fits in the memory budget of
+			// the target, if there is one.
+			printSizes := config.Options.PrintSizes != "" && config.Options.PrintSizes != "none"
+			flashBudget, ramBudget := config.MemoryBudget()
+			if printSizes || flashBudget != 0 || ramBudget != 0 {
 				sizes, err := loadProgramSize(executable, packagePathMap)
 				if err != nil {
 					return err
 				}
-				if config.Options.PrintSizes == "short" {
-					fmt.Printf("   code    data     bss |   flash     ram\n")
-					fmt.Printf("%7d %7d %7d | %7d %7d\n", sizes.Code+sizes.ROData, sizes.Data, sizes.BSS, sizes.Flash(), sizes.RAM())
-				} else {
-					if !config.Debug() {
+				if printSizes {
+					if (config.Options.PrintSizes == "full" || config.Options.PrintSizes == "symbols") && !config.Debug() {
 						fmt.Println("warning: data incomplete, remove the -no-debug flag for more detail")
 					}
-					fmt.Printf("   code  rodata    data     bss |   flash     ram | package\n")
-					fmt.Printf("------------------------------- | --------------- | -------\n")
-					for _, name := range sizes.sortedPackageNames() {
-						pkgSize := sizes.Packages[name]
-						fmt.Printf("%7d %7d %7d %7d | %7d %7d | %s\n", pkgSize.Code, pkgSize.ROData, pkgSize.Data, pkgSize.BSS, pkgSize.Flash(), pkgSize.RAM(), name)
+					err = printProgramSize(os.Stdout, sizes, config.Options.PrintSizes)
+					if err != nil {
+						return err
 					}
-					fmt.Printf("------------------------------- | --------------- | -------\n")
-					fmt.Printf("%7d %7d %7d %7d | %7d %7d | total\n", sizes.Code, sizes.ROData, sizes.Data, sizes.BSS, sizes.Code+sizes.ROData+sizes.Data, sizes.Data+sizes.BSS)
+				}
+				err = checkMemoryBudget(sizes, flashBudget, ramBudget)
+				if err != nil {
+					return err
 				}
 			}
 
@@ -868,12 +996,93 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 
 	return action(BuildResult{
 		Binary:     tmppath,
+		Executable: executable,
+		Header:     header,
 		MainDir:    lprogram.MainPkg().Dir,
 		ModuleRoot: moduleroot,
 		ImportPath: lprogram.MainPkg().ImportPath,
 	})
 }
 
+// checkMemoryBudgetAfterLinkError is called when linking failed. The linker
+// fails when the program doesn't fit in the memory regions of the linker
+// script, with an error that doesn't say what takes up all the space. If the
+// target has a memory budget, this links the program again with larger memory
+// regions (see enlargeMemoryRegions) and returns a *memoryBudgetError with the
+// size of each package if the result exceeds the budget. Otherwise it returns
+// nil and the original linker error should be reported.
+func checkMemoryBudgetAfterLinkError(config *compileopts.Config, ldflags []string, dir, executable string, packagePathMap map[string]string) error {
+	flashBudget, ramBudget := config.MemoryBudget()
+	if flashBudget == 0 && ramBudget == 0 {
+		return nil
+	}
+	sizes, err := linkWithEnlargedMemory(config, ldflags, dir, executable, packagePathMap)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: could not check the memory budget:", err)
+		return nil
+	}
+	return checkMemoryBudget(sizes, flashBudget, ramBudget)
+}
+
+// linkWithEnlargedMemory links the program again with a copy of the linker
+// script where all memory regions are enlarged, and returns the size of the
+// resulting executable.
+func linkWithEnlargedMemory(config *compileopts.Config, ldflags []string, dir, executable string, packagePathMap map[string]string) (*programSize, error) {
+	if config.Target.LinkerScript == "" {
+		return nil, errors.New("target has no linker script")
+	}
+
+	// The linker looks up the linker script in the current directory and then
+	// in TINYGOROOT (which is passed with -L).
+	script, err := ioutil.ReadFile(config.Target.LinkerScript)
+	if os.IsNotExist(err) && !filepath.IsAbs(config.Target.LinkerScript) {
+		script, err = ioutil.ReadFile(filepath.Join(goenv.Get("TINYGOROOT"), config.Target.LinkerScript))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read linker script: %w", err)
+	}
+	enlarged := enlargeMemoryRegions(script)
+	if bytes.Equal(enlarged, script) {
+		return nil, fmt.Errorf("no memory regions found in linker script %s", config.Target.LinkerScript)
+	}
+	enlargedScript := filepath.Join(dir, "enlarged.ld")
+	err = ioutil.WriteFile(enlargedScript, enlarged, 0666)
+	if err != nil {
+		return nil, err
+	}
+	flags, ok := replaceLinkerScript(ldflags, config.Target.LinkerScript, enlargedScript)
+	if !ok {
+		return nil, fmt.Errorf("linker script %s not found in linker flags", config.Target.LinkerScript)
+	}
+	if err := link(config.Target.Linker, flags...); err != nil {
+		return nil, fmt.Errorf("linking with enlarged memory regions failed: %w", err)
+	}
+	return loadProgramSize(executable, packagePathMap)
+}
+
+// replaceLinkerScript returns a copy of the linker flags where the linker
+// script is replaced with another one. It understands the -T and --script
+// flags, with the path either as a separate flag or attached to it. It returns
+// false if the linker script wasn't found.
+func replaceLinkerScript(ldflags []string, script, replacement string) ([]string, bool) {
+	script = filepath.Clean(script)
+	flags := append([]string{}, ldflags...)
+	found := false
+	for i, flag := range flags {
+		for _, prefix := range []string{"-T", "--script="} {
+			if strings.HasPrefix(flag, prefix) && len(flag) > len(prefix) && filepath.Clean(flag[len(prefix):]) == script {
+				flags[i] = prefix + replacement
+				found = true
+			}
+		}
+		if i > 0 && (flags[i-1] == "-T" || flags[i-1] == "--script") && filepath.Clean(flag) == script {
+			flags[i] = replacement
+			found = true
+		}
+	}
+	return flags, found
+}
+
 // optimizeProgram runs a series of optimizations and transformations that are
 // needed to convert a program to its final form. Some transformations are not
 // optional and must be run as the compiler expects them to run.
@@ -1020,22 +1229,10 @@ type functionStackSize struct {
 // goroutines and of the reset vector. The LLVM module is necessary to find
 // functions that call a function pointer.
 func determineStackSizes(mod llvm.Module, executable string) ([]string, map[string]functionStackSize, error) {
-	var callsIndirectFunction []string
+	indirectCalls, knownStackSizes := findIndirectCalls(mod)
 	gowrappers := []string{}
 	gowrapperNames := make(map[string]string)
 	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
-		// Determine which functions call a function pointer.
-		for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
-			for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
-				if inst.IsACallInst().IsNil() {
-					continue
-				}
-				if callee := inst.CalledValue(); callee.IsAFunction().IsNil() && callee.IsAInlineAsm().IsNil() {
-					callsIndirectFunction = append(callsIndirectFunction, fn.Name())
-				}
-			}
-		}
-
 		// Get a list of "go wrappers", small wrapper functions that decode
 		// parameters when starting a new goroutine.
 		attr := fn.GetStringAttributeAtIndex(-1, "tinygo-gowrapper")
@@ -1054,7 +1251,7 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 	defer f.Close()
 
 	// Determine the frame size of each function (if available) and the callgraph.
-	functions, err := stacksize.CallGraph(f, callsIndirectFunction)
+	functions, err := stacksize.CallGraph(f, indirectCalls, knownStackSizes)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not parse executable for stack size analysis: %w", err)
 	}
@@ -1129,6 +1326,97 @@ func determineStackSizes(mod llvm.Module, executable string) ([]string, map[stri
 	return gowrappers, sizes, nil
 }
 
+// findIndirectCalls determines, for each function that calls a function
+// pointer, which functions it may call. Interface method calls are not
+// included as they have already been lowered to direct calls at this point.
+// Function values (closures, method values) are marked by interface lowering
+// with the "tinygo-funcvalue" attribute, so an indirect call may call any of
+// those functions that has the same LLVM function type. Only when there is no
+// such function (for calls that aren't the result of a Go func value), all
+// functions of which the address is taken and that have the same type are
+// used instead. The callees are nil for calls to a fixed address (for example,
+// ROM functions).
+//
+// It also returns the stack sizes that were annotated with //go:stacksize.
+func findIndirectCalls(mod llvm.Module) (map[string][]string, map[string]uint64) {
+	// Collect all functions that can be called indirectly, by type.
+	funcValues := make(map[llvm.Type][]string)
+	addressTaken := make(map[llvm.Type][]string)
+	knownStackSizes := make(map[string]uint64)
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		fnType := fn.Type().ElementType()
+		if !fn.GetStringAttributeAtIndex(-1, "tinygo-funcvalue").IsNil() {
+			funcValues[fnType] = append(funcValues[fnType], fn.Name())
+		}
+		if isAddressTaken(fn) {
+			addressTaken[fnType] = append(addressTaken[fnType], fn.Name())
+		}
+		attr := fn.GetStringAttributeAtIndex(-1, "tinygo-stacksize")
+		if !attr.IsNil() {
+			size, err := strconv.ParseUint(attr.GetStringValue(), 10, 64)
+			if err == nil {
+				knownStackSizes[fn.Name()] = size
+			}
+		}
+	}
+
+	// Find all indirect calls.
+	indirectCalls := make(map[string][]string)
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		calledTypes := make(map[llvm.Type]struct{})
+		for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+			for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+				if inst.IsACallInst().IsNil() {
+					continue
+				}
+				callee := inst.CalledValue()
+				if !callee.IsAFunction().IsNil() || !callee.IsAInlineAsm().IsNil() {
+					continue
+				}
+				if !callee.IsAConstantExpr().IsNil() && callee.Opcode() == llvm.BitCast && !callee.Operand(0).IsAFunction().IsNil() {
+					// Direct call with a different function type.
+					continue
+				}
+				callees, ok := indirectCalls[fn.Name()]
+				if ok && callees == nil {
+					// Already known to call an unknown function.
+					continue
+				}
+				if !callee.IsAConstantExpr().IsNil() && callee.Opcode() == llvm.IntToPtr {
+					indirectCalls[fn.Name()] = nil
+					continue
+				}
+				if callees == nil {
+					callees = []string{}
+				}
+				calleeType := callee.Type().ElementType()
+				if _, ok := calledTypes[calleeType]; !ok {
+					calledTypes[calleeType] = struct{}{}
+					if possibleCallees, ok := funcValues[calleeType]; ok {
+						callees = append(callees, possibleCallees...)
+					} else {
+						callees = append(callees, addressTaken[calleeType]...)
+					}
+				}
+				indirectCalls[fn.Name()] = callees
+			}
+		}
+	}
+	return indirectCalls, knownStackSizes
+}
+
+// isAddressTaken returns whether the function is used in any other way than
+// being called directly.
+func isAddressTaken(fn llvm.Value) bool {
+	for use := fn.FirstUse(); !use.IsNil(); use = use.NextUse() {
+		user := use.User()
+		if user.IsACallInst().IsNil() || user.CalledValue() != fn {
+			return true
+		}
+	}
+	return false
+}
+
 // modifyStackSizes modifies the .tinygo_stacksizes section with the updated
 // stack size information. Before this modification, all stack sizes in the
 // section assume the default stack size (which is relatively big).
@@ -1176,11 +1464,18 @@ func modifyStackSizes(executable string, stackSizeLoads []string, stackSizes map
 				// https://interrupt.memfault.com/blog/cortex-m-rtos-context-switching
 				stackSize += 32
 
-				// Adding 4 for the stack canary, and another 4 to keep the
-				// stack aligned. Even though the size may be automatically
-				// determined, stack overflow checking is still important as the
-				// stack size cannot be determined for all goroutines.
-				stackSize += 8
+				// Adding 16 for the stack canary: stackCanaryWords (4) words at
+				// the lowest addresses of the stack, see
+				// src/internal/task/task_stack.go. Without this room, the
+				// deepest call in the goroutine would overwrite the canary and
+				// be reported as a stack overflow. As 16 is a multiple of 8,
+				// the stack stays aligned without an extra padding word. Even
+				// though the size may be automatically determined, stack
+				// overflow checking is still important as the stack size
+				// cannot be determined for all goroutines.
+				// The MPU guard region (-tags=stackguard) is not part of the
+				// stack size, it is allocated separately by the runtime.
+				stackSize += 16
 			default:
 				return fmt.Errorf("unknown architecture: %s", fileHeader.Machine.String())
 			}
@@ -1216,7 +1511,7 @@ func printStacks(calculatedStacks []string, stackSizes map[string]functionStackS
 		case stacksize.Unknown:
 			fmt.Printf("%-32s unknown, %s does not have stack frame information\n", fn.humanName, fn.missingStackSize)
 		case stacksize.Recursive:
-			fmt.Printf("%-32s recursive, %s may call itself\n", fn.humanName, fn.missingStackSize)
+			fmt.Printf("%-32s recursive, %s may call itself (annotate it with //go:stacksize)\n", fn.humanName, fn.missingStackSize)
 		case stacksize.IndirectCall:
 			fmt.Printf("%-32s unknown, %s calls a function pointer\n", fn.humanName, fn.missingStackSize)
 		}
@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// Test which functions may be called by an indirect call, as used in the stack
+// size analysis.
+func TestFindIndirectCalls(t *testing.T) {
+	t.Parallel()
+	ctx := llvm.NewContext()
+	defer ctx.Dispose()
+	buf, err := llvm.NewMemoryBufferFromFile("testdata/indirect-calls.ll")
+	if err != nil {
+		t.Fatal("could not read file:", err)
+	}
+	mod, err := ctx.ParseIR(buf)
+	if err != nil {
+		t.Fatal("could not load module:", err)
+	}
+
+	indirectCalls, knownStackSizes := findIndirectCalls(mod)
+	expectedIndirectCalls := map[string][]string{
+		"callsFuncValue":    {"funcValueTarget1", "funcValueTarget2"},
+		"callsPointer":      {"onlyAddressTaken"},
+		"callsFixedAddress": nil,
+	}
+	if !reflect.DeepEqual(indirectCalls, expectedIndirectCalls) {
+		t.Errorf("unexpected indirect calls:\nexpected: %v\nactual:   %v", expectedIndirectCalls, indirectCalls)
+	}
+	expectedStackSizes := map[string]uint64{
+		"recursive": 100,
+	}
+	if !reflect.DeepEqual(knownStackSizes, expectedStackSizes) {
+		t.Errorf("unexpected known stack sizes:\nexpected: %v\nactual:   %v", expectedStackSizes, knownStackSizes)
+	}
+}
+
+func TestReplaceLinkerScript(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		ldflags  []string
+		expected []string
+	}{
+		{[]string{"-T", "targets/avr.ld", "-T", "src/device/avr/atmega328p.ld"}, []string{"-T", "targets/avr.ld", "-T", "/tmp/enlarged.ld"}},
+		{[]string{"-Tsrc/device/avr/atmega328p.ld"}, []string{"-T/tmp/enlarged.ld"}},
+		{[]string{"--script=./src/device/avr/atmega328p.ld"}, []string{"--script=/tmp/enlarged.ld"}},
+		{[]string{"--script", "src/device/avr/atmega328p.ld"}, []string{"--script", "/tmp/enlarged.ld"}},
+		{[]string{"-T", "targets/avr.ld"}, nil},
+	} {
+		flags, ok := replaceLinkerScript(tc.ldflags, "src/device/avr/atmega328p.ld", "/tmp/enlarged.ld")
+		if tc.expected == nil {
+			if ok {
+				t.Errorf("unexpected replacement in %v: %v", tc.ldflags, flags)
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(flags, tc.expected) {
+			t.Errorf("unexpected flags for %v: %v", tc.ldflags, flags)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package builder
+
+// This file implements the parts of -buildmode=c-archive and
+// -buildmode=c-shared that are specific to libraries: finding the functions
+// exported to C, hiding all other symbols, and generating a C header.
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/compileopts"
+	"github.com/tinygo-org/tinygo/loader"
+	"tinygo.org/x/go-llvm"
+)
+
+// libraryExport is a function in the main package that is exported to C with
+// //export (or //go:export).
+type libraryExport struct {
+	name string // C name of the function
+	decl *ast.FuncDecl
+	sig  *types.Signature
+}
+
+// findLibraryExports returns all functions in the main package that are
+// exported to C, in source order.
+func findLibraryExports(pkg *loader.Package) []libraryExport {
+	var exports []libraryExport
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || decl.Doc == nil {
+				continue
+			}
+			for _, comment := range decl.Doc.List {
+				parts := strings.Fields(comment.Text)
+				if len(parts) != 2 || (parts[0] != "//export" && parts[0] != "//go:export") {
+					continue
+				}
+				fn, ok := pkg.Pkg.Scope().Lookup(decl.Name.Name).(*types.Func)
+				if !ok {
+					continue
+				}
+				exports = append(exports, libraryExport{
+					name: parts[1],
+					decl: decl,
+					sig:  fn.Type().(*types.Signature),
+				})
+			}
+		}
+	}
+	return exports
+}
+
+// internalizeLibrarySymbols gives all function and global definitions internal
+// linkage, except for the functions exported from the main package and the
+// runtime functions prefixed with tinygo_ (such as tinygo_init and the ones
+// called from assembly). Without this, symbols like main, Reset_Handler,
+// malloc or interrupt handlers that are part of every TinyGo program would
+// conflict with the C program the library is linked into. Symbols that are not
+// used anymore are removed by the optimizer afterwards.
+func internalizeLibrarySymbols(mod llvm.Module, exports []libraryExport) {
+	keep := make(map[string]bool, len(exports))
+	for _, export := range exports {
+		keep[export.name] = true
+	}
+	internalize := func(value llvm.Value) {
+		if value.IsDeclaration() || value.Linkage() != llvm.ExternalLinkage {
+			return
+		}
+		name := value.Name()
+		if keep[name] || strings.HasPrefix(name, "tinygo_") {
+			return
+		}
+		value.SetLinkage(llvm.InternalLinkage)
+	}
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		internalize(fn)
+	}
+	for global := mod.FirstGlobal(); !global.IsNil(); global = llvm.NextGlobal(global) {
+		internalize(global)
+	}
+}
+
+// checkLibraryCGo returns an error if a library for Linux uses CGo. TinyGo
+// compiles C code against the musl headers that it ships with, but a library
+// is linked against the libc of the C program, which is usually glibc. The
+// runtime itself only relies on the Linux ABI that both implement, but C code
+// using the musl headers may not. Linking musl into the library instead isn't
+// an option either, as two C libraries can't share a process.
+func checkLibraryCGo(config *compileopts.Config, lprogram *loader.Program) error {
+	if config.Target.Libc != "musl" {
+		return nil
+	}
+	for _, pkg := range lprogram.Sorted() {
+		if len(pkg.CFlags) != 0 {
+			return fmt.Errorf("%s: CGo is not supported with -buildmode=%s on Linux: C code would be compiled against the musl headers of TinyGo, but linked against the libc of the C program", pkg.ImportPath, config.BuildMode())
+		}
+	}
+	return nil
+}
+
+// isLibraryExtraFile returns whether the given extra file (see
+// Config.ExtraFiles) should be included in a library. Only runtime support
+// code is included: startup code, vector tables and the like are provided by
+// the C program.
+func isLibraryExtraFile(path string) bool {
+	return strings.HasPrefix(path, "src/runtime/") || strings.HasPrefix(path, "src/internal/task/")
+}
+
+// writeLibraryHeader writes a C header to path that declares tinygo_init and
+// all exported functions of the main package.
+func writeLibraryHeader(path string, pkg *loader.Package, exports []libraryExport, fset *token.FileSet, sizes types.Sizes, buildMode string) error {
+	guard := "TINYGO_" + strings.ToUpper(cIdentifier(pkg.Pkg.Name())) + "_H"
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by TinyGo (-buildmode=%s) for package %s. DO NOT EDIT.\n\n", buildMode, pkg.ImportPath)
+	fmt.Fprintf(buf, "#ifndef %s\n#define %s\n\n", guard, guard)
+	buf.WriteString("#include <stdbool.h>\n#include <stdint.h>\n\n")
+	buf.WriteString("#ifdef __cplusplus\nextern \"C\" {\n#endif\n\n")
+	buf.WriteString("// Initialize the Go runtime and run the initializers of all packages. This\n")
+	buf.WriteString("// must be called once before calling any other function declared here.\n")
+	buf.WriteString("void tinygo_init(void);\n")
+
+	var errs []error
+	for _, export := range exports {
+		decl, err := cFunctionDeclaration(export, sizes)
+		if err != nil {
+			errs = append(errs, types.Error{
+				Fset: fset,
+				Pos:  export.decl.Pos(),
+				Msg:  fmt.Sprintf("cannot export %s to C: %s", export.decl.Name.Name, err),
+			})
+			continue
+		}
+		buf.WriteString("\n")
+		for _, comment := range export.decl.Doc.List {
+			if strings.HasPrefix(comment.Text, "//export ") || strings.HasPrefix(comment.Text, "//go:") {
+				continue
+			}
+			for _, line := range strings.Split(comment.Text, "\n") {
+				buf.WriteString(strings.TrimRight(line, " \t") + "\n")
+			}
+		}
+		buf.WriteString(decl + ";\n")
+	}
+	if len(errs) != 0 {
+		return newMultiError(errs)
+	}
+
+	buf.WriteString("\n#ifdef __cplusplus\n}\n#endif\n\n")
+	fmt.Fprintf(buf, "#endif // %s\n", guard)
+	return ioutil.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// cFunctionDeclaration returns the C declaration (without trailing semicolon)
+// of an exported function.
+func cFunctionDeclaration(export libraryExport, sizes types.Sizes) (string, error) {
+	result := "void"
+	switch export.sig.Results().Len() {
+	case 0:
+	case 1:
+		typ, err := cType(export.sig.Results().At(0).Type(), sizes)
+		if err != nil {
+			return "", fmt.Errorf("result: %w", err)
+		}
+		result = typ
+	default:
+		return "", fmt.Errorf("multiple results are not supported")
+	}
+	if export.sig.Variadic() {
+		return "", fmt.Errorf("variadic functions are not supported")
+	}
+
+	var params []string
+	for i := 0; i < export.sig.Params().Len(); i++ {
+		param := export.sig.Params().At(i)
+		typ, err := cType(param.Type(), sizes)
+		if err != nil {
+			return "", fmt.Errorf("parameter %d: %w", i+1, err)
+		}
+		name := cIdentifier(param.Name())
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("p%d", i)
+		}
+		params = append(params, typ+" "+name)
+	}
+	if len(params) == 0 {
+		params = []string{"void"}
+	}
+	return result + " " + export.name + "(" + strings.Join(params, ", ") + ")", nil
+}
+
+// cType returns the C type of a Go type used in an exported function. Only
+// types that are passed the same way in Go and C are supported: booleans,
+// integers, floats and pointers.
+func cType(typ types.Type, sizes types.Sizes) (string, error) {
+	switch underlying := typ.Underlying().(type) {
+	case *types.Basic:
+		switch underlying.Kind() {
+		case types.Bool:
+			return "bool", nil
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+			return fmt.Sprintf("int%d_t", sizes.Sizeof(underlying)*8), nil
+		case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return fmt.Sprintf("uint%d_t", sizes.Sizeof(underlying)*8), nil
+		case types.Uintptr:
+			return "uintptr_t", nil
+		case types.Float32:
+			return "float", nil
+		case types.Float64:
+			return "double", nil
+		case types.UnsafePointer:
+			return "void*", nil
+		}
+	case *types.Pointer:
+		return "void*", nil
+	}
+	return "", fmt.Errorf("type %s is not supported", typ)
+}
+
+// cIdentifier returns s with all characters that are not valid in a C
+// identifier replaced, and with a trailing underscore if it is a C keyword.
+func cIdentifier(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+	switch s {
+	case "auto", "char", "const", "double", "enum", "extern", "float", "inline", "int", "long", "register", "restrict", "short", "signed", "sizeof", "static", "typedef", "union", "unsigned", "void", "volatile", "while", "bool":
+		return s + "_"
+	}
+	return s
+}
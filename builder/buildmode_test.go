@@ -0,0 +1,169 @@
+package builder
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/loader"
+	"tinygo.org/x/go-llvm"
+)
+
+func TestLibraryHeader(t *testing.T) {
+	const source = `package main
+
+import "unsafe"
+
+// Add returns the sum of a and b.
+//export add
+func add(a, b int32) int32 {
+	return a + b
+}
+
+//export tinygo_fill
+func fill(buf *byte, len uintptr, value uint8, char bool) {
+}
+
+//export get_state
+func getState() unsafe.Pointer {
+	return nil
+}
+
+// Not exported.
+func unexported(s string) {
+}
+
+//export greet
+func greet(s string) {
+}
+
+//export divmod
+func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+func main() {
+}
+`
+	pkg, fset := parseTestPackage(t, source)
+	exports := findLibraryExports(pkg)
+	var names []string
+	for _, export := range exports {
+		names = append(names, export.name)
+	}
+	if strings.Join(names, " ") != "add tinygo_fill get_state greet divmod" {
+		t.Errorf("unexpected exports: %v", names)
+	}
+
+	// Strings and multiple results can't be used from C.
+	path := filepath.Join(t.TempDir(), "main.h")
+	sizes := types.SizesFor("gc", "arm")
+	err := writeLibraryHeader(path, pkg, exports, fset, sizes, "c-archive")
+	if err == nil {
+		t.Fatal("expected an error for unsupported types")
+	}
+	var errs []string
+	for _, err := range err.(*MultiError).Errs {
+		errs = append(errs, err.Error())
+	}
+	expectedErrs := []string{
+		"main.go:25:1: cannot export greet to C: parameter 1: type string is not supported",
+		"main.go:29:1: cannot export divmod to C: multiple results are not supported",
+	}
+	if strings.Join(errs, "\n") != strings.Join(expectedErrs, "\n") {
+		t.Errorf("unexpected errors:\n%s", strings.Join(errs, "\n"))
+	}
+
+	err = writeLibraryHeader(path, pkg, exports[:3], fset, sizes, "c-archive")
+	if err != nil {
+		t.Fatal("could not write header:", err)
+	}
+	header, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"// Add returns the sum of a and b.\nint32_t add(int32_t a, int32_t b);\n",
+		"void tinygo_fill(void* buf, uintptr_t len, uint8_t value, bool char_);\n",
+		"void* get_state(void);\n",
+		"void tinygo_init(void);\n",
+		"#ifndef TINYGO_MAIN_H\n",
+	} {
+		if !strings.Contains(string(header), line) {
+			t.Errorf("header does not contain %q:\n%s", line, header)
+		}
+	}
+}
+
+func TestInternalizeLibrarySymbols(t *testing.T) {
+	t.Parallel()
+	ctx := llvm.NewContext()
+	defer ctx.Dispose()
+	buf, err := llvm.NewMemoryBufferFromFile("testdata/library.ll")
+	if err != nil {
+		t.Fatal("could not read file:", err)
+	}
+	mod, err := ctx.ParseIR(buf)
+	if err != nil {
+		t.Fatal("could not load module:", err)
+	}
+
+	internalizeLibrarySymbols(mod, []libraryExport{{name: "add"}})
+
+	for name, expected := range map[string]llvm.Linkage{
+		// Exported functions, runtime symbols used from C or assembly, and
+		// declarations are kept.
+		"add":               llvm.ExternalLinkage,
+		"tinygo_init":       llvm.ExternalLinkage,
+		"tinygo_core1Stack": llvm.ExternalLinkage,
+		"write":             llvm.ExternalLinkage,
+		"externalGlobal":    llvm.ExternalLinkage,
+		// Everything else is hidden from the C program.
+		"main":              llvm.InternalLinkage,
+		"Reset_Handler":     llvm.InternalLinkage,
+		"malloc":            llvm.InternalLinkage,
+		"main.counter":      llvm.InternalLinkage,
+		"runtime.heapStart": llvm.InternalLinkage,
+		"internalGlobal":    llvm.InternalLinkage,
+		"weakFunction":      llvm.WeakAnyLinkage,
+	} {
+		value := mod.NamedFunction(name)
+		if value.IsNil() {
+			value = mod.NamedGlobal(name)
+		}
+		if value.IsNil() {
+			t.Errorf("%s: not found", name)
+			continue
+		}
+		if value.Linkage() != expected {
+			t.Errorf("%s: expected linkage %d, got %d", name, expected, value.Linkage())
+		}
+	}
+}
+
+// parseTestPackage parses and type checks a single file main package.
+func parseTestPackage(t *testing.T, source string) (*loader.Package, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := types.Config{Importer: importer.Default()}
+	typesPkg, err := config.Check("main", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &loader.Package{
+		Files: []*ast.File{file},
+		Pkg:   typesPkg,
+	}
+	pkg.ImportPath = "example.com/lib"
+	return pkg, fset
+}
@@ -3,6 +3,7 @@ package builder
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/tinygo-org/tinygo/compileopts"
 	"github.com/tinygo-org/tinygo/goenv"
@@ -33,17 +34,65 @@ func NewConfig(options *compileopts.Options) (*compileopts.Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not read version from GOROOT (%v): %v", goroot, err)
 	}
-	if major != 1 || minor < 15 || minor > 17 {
-		return nil, fmt.Errorf("requires go version 1.15 through 1.17, got go%d.%d", major, minor)
+	if major != 1 || minor != 18 {
+		return nil, fmt.Errorf("requires go version 1.18, got go%d.%d", major, minor)
 	}
 
 	clangHeaderPath := getClangHeaderPath(goenv.Get("TINYGOROOT"))
 
-	return &compileopts.Config{
+	config := &compileopts.Config{
 		Options:        options,
 		Target:         spec,
 		GoMinorVersion: minor,
 		ClangHeaders:   clangHeaderPath,
 		TestConfig:     options.TestConfig,
-	}, nil
+	}
+	if config.Scheduler() == "cores" && config.MultiCoreChip() == "" {
+		// The ESP32 also has two cores, but TinyGo doesn't support interrupts
+		// on it yet. Without a cross-core interrupt, the GC can't stop a
+		// goroutine that doesn't reach a safe point on the other core.
+		return nil, errors.New("scheduler 'cores' is only supported on the RP2040")
+	}
+	if config.GC() == "precise" && hasBuildTag(config, "avr") {
+		// Pointers on AVR are only aligned to a single byte, which can't be
+		// described by an object layout with one bit per word.
+		return nil, errors.New("-gc=precise is not supported on AVR")
+	}
+	if config.PreemptTimeSlice() != 0 {
+		if config.Scheduler() != "tasks" || !hasBuildTag(config, "cortexm") {
+			return nil, errors.New("-preempt is only supported with -scheduler=tasks on Cortex-M")
+		}
+		if hasBuildTag(config, "mimxrt1062") || hasBuildTag(config, "mk66f18") {
+			return nil, errors.New("-preempt is not supported on this chip: the SysTick timer is already in use")
+		}
+	}
+	if config.IsLibrary() {
+		if config.Scheduler() != "none" {
+			return nil, fmt.Errorf("-buildmode=%s requires -scheduler=none: goroutines cannot run when called from C", config.BuildMode())
+		}
+		switch {
+		case config.BuildMode() == "c-shared" && config.GOOS() != "linux":
+			return nil, errors.New("-buildmode=c-shared is only supported on Linux")
+		case config.BuildMode() == "c-shared" && hasBuildTag(config, "baremetal"):
+			return nil, errors.New("-buildmode=c-shared is not supported on baremetal targets, use -buildmode=c-archive instead")
+		case config.GOOS() != "linux" && !hasBuildTag(config, "baremetal"):
+			return nil, fmt.Errorf("-buildmode=%s is only supported on Linux and baremetal targets", config.BuildMode())
+		}
+	}
+	for _, tag := range strings.Fields(options.Tags) {
+		if tag == "goroutinedebug" && config.Scheduler() != "tasks" && config.Scheduler() != "cores" {
+			return nil, errors.New("-tags=goroutinedebug is only supported with -scheduler=tasks or -scheduler=cores")
+		}
+	}
+	return config, nil
+}
+
+// hasBuildTag returns whether the target has the given build tag.
+func hasBuildTag(config *compileopts.Config, tag string) bool {
+	for _, t := range config.Target.BuildTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
@@ -108,17 +108,27 @@ var Musl = Library{
 	librarySources: func(target string) []string {
 		arch := compileopts.MuslArchitecture(target)
 		globs := []string{
+			"ctype/*.c",
 			"env/*.c",
 			"errno/*.c",
 			"exit/*.c",
 			"internal/defsysinfo.c",
+			"internal/intscan.c",
 			"internal/libc.c",
+			"internal/shgetc.c",
 			"internal/syscall_ret.c",
 			"internal/vdso.c",
+			"linux/epoll.c",
 			"malloc/*.c",
 			"mman/*.c",
+			"network/*.c",
+			"process/execve.c",
+			"process/execvp.c",
+			"process/posix_spawn*.c",
+			"select/*.c",
 			"signal/*.c",
 			"stdio/*.c",
+			"stdlib/strtol.c",
 			"string/*.c",
 			"thread/" + arch + "/*.s",
 			"thread/" + arch + "/*.c",
@@ -0,0 +1,361 @@
+package builder
+
+// This file creates the PC table: a table in the binary that maps program
+// counters to function names and source locations. It is used by the runtime
+// to symbolize stack traces, see src/runtime/pctable.go for the format.
+//
+// The table is created from the symbol table and the DWARF line tables of the
+// linked executable. Because the size of the table is only known after
+// linking, the program is linked twice: once with an empty table and once with
+// a placeholder of the right size. The size of the table does not depend on
+// the address of each function (all addresses in the table are either fixed
+// size or relative to the function start), so the table calculated from the
+// second executable fits exactly in the placeholder.
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// Name of the section that contains runtime.pcTable.
+const pcTableSection = ".tinygo_pcln"
+
+// pcTableFunc is a single function in the PC table.
+type pcTableFunc struct {
+	Name    string
+	Address uint64
+	Size    uint64
+}
+
+// lineChunk is a chunk of code as described by the DWARF line table.
+type lineChunk struct {
+	Address uint64
+	Length  uint64
+	File    string
+	Line    int
+}
+
+// pcTableHeaderSize returns the size of the PC table header, which is also the
+// size of an empty PC table.
+func pcTableHeaderSize(machine llvm.TargetMachine) int {
+	targetData := machine.CreateTargetData()
+	defer targetData.Dispose()
+	return targetData.PointerSize() * 3
+}
+
+// writePCTableObject writes an object file to the given path that defines
+// runtime.pcTable with the given contents.
+func writePCTableObject(path string, machine llvm.TargetMachine, data []byte) error {
+	ctx := llvm.NewContext()
+	defer ctx.Dispose()
+	mod := ctx.NewModule("pctable")
+	defer mod.Dispose()
+	targetData := machine.CreateTargetData()
+	defer targetData.Dispose()
+	mod.SetTarget(machine.Triple())
+	mod.SetDataLayout(targetData.String())
+
+	initializer := ctx.ConstString(string(data), false)
+	global := llvm.AddGlobal(mod, initializer.Type(), "runtime.pcTable")
+	global.SetInitializer(initializer)
+	global.SetGlobalConstant(true)
+	global.SetSection(pcTableSection)
+	global.SetAlignment(targetData.PointerSize())
+
+	buf, err := machine.EmitToMemoryBuffer(mod, llvm.ObjectFile)
+	if err != nil {
+		return err
+	}
+	defer buf.Dispose()
+	return ioutil.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// defineEmptyPCTable turns the runtime.pcTable declaration into a definition of
+// an empty table, for when no PC table is added while linking.
+func defineEmptyPCTable(pcTable llvm.Value) {
+	pcTable.SetInitializer(llvm.ConstNull(pcTable.Type().ElementType()))
+	pcTable.SetLinkage(llvm.InternalLinkage)
+	pcTable.SetGlobalConstant(true)
+}
+
+// addPCTable adds the PC table to the given executable, which must have been
+// linked with a placeholder object for the table. The relink function is
+// called to link the executable again after the placeholder object has been
+// replaced.
+func addPCTable(executable, object string, machine llvm.TargetMachine, relink func() error) error {
+	table, err := makePCTable(executable)
+	if err != nil {
+		return err
+	}
+	if table == nil {
+		// The table isn't referenced, so it was removed by the linker.
+		return nil
+	}
+
+	// Link again, with a placeholder of the correct size.
+	err = writePCTableObject(object, machine, make([]byte, len(table)))
+	if err != nil {
+		return err
+	}
+	err = relink()
+	if err != nil {
+		return err
+	}
+
+	// Now calculate the real table and put it in the placeholder.
+	newTable, err := makePCTable(executable)
+	if err != nil {
+		return err
+	}
+	if len(newTable) != len(table) {
+		return fmt.Errorf("table size changed after relinking: %d -> %d bytes", len(table), len(newTable))
+	}
+	return replaceElfSection(executable, pcTableSection, newTable)
+}
+
+// makePCTable calculates the PC table for the given executable. It returns nil
+// if the executable doesn't contain a PC table section.
+func makePCTable(executable string) ([]byte, error) {
+	file, err := elf.Open(executable)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if file.Section(pcTableSection) == nil {
+		return nil, nil
+	}
+
+	// Read all functions from the symbol table.
+	funcs, err := readFunctionSymbols(file)
+	if err != nil {
+		return nil, err
+	}
+	var tableAddress uint64
+	symbols, err := file.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		if symbol.Name == "runtime.pcTable" {
+			tableAddress = symbol.Value
+		}
+	}
+	if tableAddress == 0 {
+		return nil, errors.New("could not find runtime.pcTable symbol")
+	}
+
+	// Read the line tables, if there is debug information.
+	var lines []lineChunk
+	if data, err := file.DWARF(); err == nil {
+		lines, err = readLineTable(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var byteOrder binary.ByteOrder = file.ByteOrder
+	wordSize := 8
+	if file.Class == elf.ELFCLASS32 {
+		wordSize = 4
+	}
+	return buildPCTable(funcs, lines, tableAddress, wordSize, byteOrder), nil
+}
+
+// readFunctionSymbols returns all functions in the symbol table of the ELF
+// file, sorted by address. Aliases and other overlapping functions are removed.
+func readFunctionSymbols(file *elf.File) ([]pcTableFunc, error) {
+	symbols, err := file.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	var funcs []pcTableFunc
+	for _, symbol := range symbols {
+		if elf.ST_TYPE(symbol.Info) != elf.STT_FUNC || symbol.Size == 0 {
+			continue
+		}
+		if symbol.Section == elf.SHN_UNDEF || symbol.Section >= elf.SHN_LORESERVE {
+			continue
+		}
+		address := symbol.Value
+		if file.Machine == elf.EM_ARM {
+			// Remove the Thumb bit.
+			address &^= 1
+		}
+		funcs = append(funcs, pcTableFunc{
+			Name:    symbol.Name,
+			Address: address,
+			Size:    symbol.Size,
+		})
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		if funcs[i].Address != funcs[j].Address {
+			return funcs[i].Address < funcs[j].Address
+		}
+		return funcs[i].Name < funcs[j].Name
+	})
+
+	// Remove aliases and other overlapping functions.
+	var filtered []pcTableFunc
+	for _, fn := range funcs {
+		if len(filtered) != 0 {
+			prev := filtered[len(filtered)-1]
+			if fn.Address < prev.Address+prev.Size {
+				continue
+			}
+		}
+		filtered = append(filtered, fn)
+	}
+	return filtered, nil
+}
+
+// readLineTable returns all code chunks of all compile units in the DWARF
+// line tables, sorted by address.
+func readLineTable(data *dwarf.Data) ([]lineChunk, error) {
+	var lines []lineChunk
+	r := data.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag == dwarf.TagCompileUnit {
+			lr, err := data.LineReader(e)
+			if err != nil {
+				return nil, err
+			}
+			if lr != nil {
+				err = readLineChunks(lr, func(entry *dwarf.LineEntry, length uint64) {
+					lines = append(lines, lineChunk{
+						Address: entry.Address,
+						Length:  length,
+						File:    entry.File.Name,
+						Line:    entry.Line,
+					})
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		r.SkipChildren()
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Address < lines[j].Address
+	})
+	return lines, nil
+}
+
+// buildPCTable encodes the PC table in the format expected by the runtime.
+func buildPCTable(funcs []pcTableFunc, lines []lineChunk, tableAddress uint64, wordSize int, byteOrder binary.ByteOrder) []byte {
+	var strtab []byte
+	stringOffsets := map[string]uint64{}
+	addString := func(s string) uint64 {
+		if offset, ok := stringOffsets[s]; ok {
+			return offset
+		}
+		offset := uint64(len(strtab))
+		strtab = appendUvarint(strtab, uint64(len(s)))
+		strtab = append(strtab, s...)
+		stringOffsets[s] = offset
+		return offset
+	}
+
+	// Encode each function record.
+	var records []byte
+	recordOffsets := make([]uint64, len(funcs))
+	for i, fn := range funcs {
+		recordOffsets[i] = uint64(len(records))
+		end := fn.Address + fn.Size
+
+		// Find the line table entries for this function.
+		var startFile string
+		var startLine int
+		var entries []byte
+		numEntries := 0
+		haveStart := false
+		file, line := "", 0
+		lastAddress := fn.Address
+		index := sort.Search(len(lines), func(i int) bool {
+			return lines[i].Address+lines[i].Length > fn.Address
+		})
+		for ; index < len(lines) && lines[index].Address < end; index++ {
+			chunk := lines[index]
+			if chunk.Line == 0 {
+				// Compiler generated code without a source location.
+				continue
+			}
+			if !haveStart {
+				startFile, startLine = chunk.File, chunk.Line
+				file, line = chunk.File, chunk.Line
+				haveStart = true
+				continue
+			}
+			if chunk.File == file && chunk.Line == line {
+				continue
+			}
+			lineDelta := int64(chunk.Line - line)
+			change := uint64(lineDelta<<1^lineDelta>>63) << 1 // zigzag encoding
+			if chunk.File != file {
+				change |= 1
+			}
+			entries = appendUvarint(entries, chunk.Address-lastAddress)
+			entries = appendUvarint(entries, change)
+			if chunk.File != file {
+				entries = appendUvarint(entries, addString(chunk.File))
+			}
+			numEntries++
+			lastAddress = chunk.Address
+			file, line = chunk.File, chunk.Line
+		}
+
+		records = appendUvarint(records, fn.Size)
+		records = appendUvarint(records, addString(fn.Name))
+		records = appendUvarint(records, addString(startFile))
+		records = appendUvarint(records, uint64(startLine))
+		records = appendUvarint(records, uint64(numEntries))
+		records = append(records, entries...)
+	}
+
+	// Put everything together.
+	headerSize := uint64(wordSize) * uint64(3+len(funcs)*2)
+	var table []byte
+	putWord := func(value uint64) {
+		buf := make([]byte, 8)
+		if wordSize == 4 {
+			byteOrder.PutUint32(buf, uint32(value))
+		} else {
+			byteOrder.PutUint64(buf, value)
+		}
+		table = append(table, buf[:wordSize]...)
+	}
+	putWord(tableAddress)
+	putWord(uint64(len(funcs)))
+	putWord(headerSize + uint64(len(records)))
+	for _, fn := range funcs {
+		putWord(fn.Address)
+	}
+	for _, offset := range recordOffsets {
+		putWord(headerSize + offset)
+	}
+	table = append(table, records...)
+	table = append(table, strtab...)
+	return table
+}
+
+// appendUvarint appends the varint-encoded value to buf.
+func appendUvarint(buf []byte, value uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], value)
+	return append(buf, tmp[:n]...)
+}
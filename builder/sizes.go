@@ -6,12 +6,16 @@ import (
 	"debug/elf"
 	"debug/pe"
 	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/aykevl/go-wasm"
@@ -22,12 +26,15 @@ import (
 const sizesDebug = false
 
 // programSize contains size statistics per package of a compiled program.
+// This is also the format of -size=json.
 type programSize struct {
-	Packages map[string]packageSize
-	Code     uint64
-	ROData   uint64
-	Data     uint64
-	BSS      uint64
+	Packages map[string]packageSize `json:"packages"`
+	Symbols  []symbolSize           `json:"symbols"`
+	Lines    []lineSize             `json:"lines,omitempty"`
+	Code     uint64                 `json:"code"`
+	ROData   uint64                 `json:"rodata"`
+	Data     uint64                 `json:"data"`
+	BSS      uint64                 `json:"bss"`
 }
 
 // sortedPackageNames returns the list of package names (ProgramSize.Packages)
@@ -54,10 +61,10 @@ func (ps *programSize) RAM() uint64 {
 // packageSize contains the size of a package, calculated from the linked object
 // file.
 type packageSize struct {
-	Code   uint64
-	ROData uint64
-	Data   uint64
-	BSS    uint64
+	Code   uint64 `json:"code"`
+	ROData uint64 `json:"rodata"`
+	Data   uint64 `json:"data"`
+	BSS    uint64 `json:"bss"`
 }
 
 // Flash usage in regular microcontrollers.
@@ -70,11 +77,47 @@ func (ps *packageSize) RAM() uint64 {
 	return ps.Data + ps.BSS
 }
 
+// symbolSize is the size of a single symbol (function or global) in the symbol
+// table of the program. Only one of the size fields is set, depending on the
+// section the symbol is in.
+type symbolSize struct {
+	Name    string `json:"name"`
+	Address uint64 `json:"address"`
+	Package string `json:"package"`
+	File    string `json:"file,omitempty"` // source file the symbol is declared in, if known
+	Line    int    `json:"line,omitempty"`
+	Code    uint64 `json:"code"`
+	ROData  uint64 `json:"rodata"`
+	Data    uint64 `json:"data"`
+	BSS     uint64 `json:"bss"`
+}
+
+// Flash usage in regular microcontrollers.
+func (ss *symbolSize) Flash() uint64 {
+	return ss.Code + ss.ROData + ss.Data
+}
+
+// Static RAM usage in regular microcontrollers.
+func (ss *symbolSize) RAM() uint64 {
+	return ss.Data + ss.BSS
+}
+
+// lineSize is the amount of code generated for a single line of source code, as
+// far as it is known from the DWARF line table. Code of inlined functions is
+// counted towards the line it was inlined from.
+type lineSize struct {
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Code    uint64 `json:"code"`
+}
+
 // A mapping of a single chunk of code or data to a file path.
 type addressLine struct {
 	Address    uint64
 	Length     uint64 // length of this chunk
 	File       string // file path as stored in DWARF
+	Line       int    // line number of code, if known
 	IsVariable bool   // true if this is a variable (or constant), false if it is code
 }
 
@@ -123,17 +166,413 @@ var (
 	reflectDataRegexp = regexp.MustCompile(`^reflect\.[a-zA-Z]+Sidetable$`)
 )
 
+// printProgramSize prints the program size in one of the formats of the -size
+// flag: short, full, symbols, json or csv.
+func printProgramSize(w io.Writer, sizes *programSize, format string) error {
+	switch format {
+	case "short":
+		fmt.Fprintf(w, "   code    data     bss |   flash     ram\n")
+		fmt.Fprintf(w, "%7d %7d %7d | %7d %7d\n", sizes.Code+sizes.ROData, sizes.Data, sizes.BSS, sizes.Flash(), sizes.RAM())
+	case "full":
+		printPackageSizes(w, sizes, sizes.sortedPackageNames())
+	case "symbols":
+		// Print the largest symbols first, with the package and source
+		// location they're declared in.
+		symbols := append([]symbolSize(nil), sizes.Symbols...)
+		sort.SliceStable(symbols, func(i, j int) bool {
+			if symbols[i].Flash() != symbols[j].Flash() {
+				return symbols[i].Flash() > symbols[j].Flash()
+			}
+			return symbols[i].RAM() > symbols[j].RAM()
+		})
+		fmt.Fprintf(w, "   code  rodata    data     bss |   flash     ram | symbol\n")
+		fmt.Fprintf(w, "------------------------------- | --------------- | ------\n")
+		for _, symbol := range symbols {
+			location := symbol.Package
+			if symbol.File != "" {
+				location = sourceLine(symbol.Package, symbol.File, symbol.Line)
+			}
+			name := symbol.Name
+			if location != "(unknown)" {
+				name += " (" + location + ")"
+			}
+			fmt.Fprintf(w, "%7d %7d %7d %7d | %7d %7d | %s\n", symbol.Code, symbol.ROData, symbol.Data, symbol.BSS, symbol.Flash(), symbol.RAM(), name)
+		}
+		fmt.Fprintf(w, "------------------------------- | --------------- | ------\n")
+		fmt.Fprintf(w, "%7d %7d %7d %7d | %7d %7d | total\n", sizes.Code, sizes.ROData, sizes.Data, sizes.BSS, sizes.Flash(), sizes.RAM())
+
+		// Print the code per line of source code, which is only known with
+		// debug information.
+		if len(sizes.Lines) != 0 {
+			fmt.Fprintf(w, "\n   code | line\n")
+			fmt.Fprintf(w, "------- | ----\n")
+			for _, line := range sizes.Lines {
+				fmt.Fprintf(w, "%7d | %s\n", line.Code, sourceLine(line.Package, line.File, line.Line))
+			}
+		}
+	case "json":
+		data, err := json.MarshalIndent(sizes, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case "csv":
+		return printSizesCSV(w, sizes)
+	default:
+		return fmt.Errorf("unknown size format: %s", format)
+	}
+	return nil
+}
+
+// printSizesCSV prints the size of each package, symbol and source line as CSV,
+// for use in spreadsheets and scripts. The kind column is "package", "symbol",
+// "line" or "total".
+func printSizesCSV(w io.Writer, sizes *programSize) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "name", "package", "file", "line", "code", "rodata", "data", "bss", "flash", "ram"})
+	formatSizes := func(code, rodata, data, bss, flash, ram uint64) []string {
+		var fields []string
+		for _, size := range []uint64{code, rodata, data, bss, flash, ram} {
+			fields = append(fields, strconv.FormatUint(size, 10))
+		}
+		return fields
+	}
+	for _, name := range sizes.sortedPackageNames() {
+		pkgSize := sizes.Packages[name]
+		cw.Write(append([]string{"package", name, name, "", ""}, formatSizes(pkgSize.Code, pkgSize.ROData, pkgSize.Data, pkgSize.BSS, pkgSize.Flash(), pkgSize.RAM())...))
+	}
+	for _, symbol := range sizes.Symbols {
+		line := ""
+		if symbol.Line != 0 {
+			line = strconv.Itoa(symbol.Line)
+		}
+		cw.Write(append([]string{"symbol", symbol.Name, symbol.Package, symbol.File, line}, formatSizes(symbol.Code, symbol.ROData, symbol.Data, symbol.BSS, symbol.Flash(), symbol.RAM())...))
+	}
+	for _, line := range sizes.Lines {
+		cw.Write(append([]string{"line", "", line.Package, line.File, strconv.Itoa(line.Line)}, formatSizes(line.Code, 0, 0, 0, line.Code, 0)...))
+	}
+	cw.Write(append([]string{"total", "", "", "", ""}, formatSizes(sizes.Code, sizes.ROData, sizes.Data, sizes.BSS, sizes.Flash(), sizes.RAM())...))
+	cw.Flush()
+	return cw.Error()
+}
+
+// sourceLine formats a source location as the package, the base name of the
+// file and the line number, for example "runtime/gc_blocks.go:270".
+func sourceLine(pkg, file string, line int) string {
+	location := pkg + "/" + filepath.Base(file)
+	if line != 0 {
+		location += ":" + strconv.Itoa(line)
+	}
+	return location
+}
+
+// printPackageSizes prints the size of the given packages as a table, followed
+// by the total size of the program.
+func printPackageSizes(w io.Writer, sizes *programSize, names []string) {
+	fmt.Fprintf(w, "   code  rodata    data     bss |   flash     ram | package\n")
+	fmt.Fprintf(w, "------------------------------- | --------------- | -------\n")
+	for _, name := range names {
+		pkgSize := sizes.Packages[name]
+		fmt.Fprintf(w, "%7d %7d %7d %7d | %7d %7d | %s\n", pkgSize.Code, pkgSize.ROData, pkgSize.Data, pkgSize.BSS, pkgSize.Flash(), pkgSize.RAM(), name)
+	}
+	fmt.Fprintf(w, "------------------------------- | --------------- | -------\n")
+	fmt.Fprintf(w, "%7d %7d %7d %7d | %7d %7d | total\n", sizes.Code, sizes.ROData, sizes.Data, sizes.BSS, sizes.Flash(), sizes.RAM())
+}
+
+// memoryBudgetError is returned when a program uses more flash or RAM than the
+// target allows (see compileopts.Config.MemoryBudget). It includes the size of
+// each package, the largest first, to show what could be made smaller.
+type memoryBudgetError struct {
+	memory string // "flash" or "RAM"
+	used   uint64
+	budget uint64
+	sizes  *programSize
+}
+
+func (e *memoryBudgetError) Error() string {
+	// Sort packages by their usage of the memory that overflowed.
+	names := e.sizes.sortedPackageNames()
+	usage := func(name string) uint64 {
+		pkgSize := e.sizes.Packages[name]
+		if e.memory == "flash" {
+			return pkgSize.Flash()
+		}
+		return pkgSize.RAM()
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return usage(names[i]) > usage(names[j])
+	})
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "program uses %d bytes of %s which exceeds the budget of %d bytes by %d bytes\n", e.used, e.memory, e.budget, e.used-e.budget)
+	printPackageSizes(buf, e.sizes, names)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// checkMemoryBudget returns a *memoryBudgetError if the program uses more
+// flash or static RAM than the given budget. A budget of 0 means there is no
+// limit.
+func checkMemoryBudget(sizes *programSize, flashBudget, ramBudget uint64) error {
+	if flashBudget != 0 && sizes.Flash() > flashBudget {
+		return &memoryBudgetError{"flash", sizes.Flash(), flashBudget, sizes}
+	}
+	if ramBudget != 0 && sizes.RAM() > ramBudget {
+		return &memoryBudgetError{"RAM", sizes.RAM(), ramBudget, sizes}
+	}
+	return nil
+}
+
+// memoryLengthRegexp matches the origin and the start of the length of a
+// memory region in the MEMORY command of a linker script. Both can be written
+// in three ways: ORIGIN/org/o and LENGTH/len/l.
+var memoryLengthRegexp = regexp.MustCompile(`\b((?:ORIGIN|org|o)\s*=[^,\n]*,\s*)(?:LENGTH|len|l)\s*=\s*`)
+
+// enlargeMemoryRegions returns a copy of the given linker script where every
+// memory region is 16MB larger, so that a program that overflows its memory
+// regions can still be linked to find out how large it is. Regions can't
+// simply be removed, because the linker script uses them to place sections.
+// The enlarged regions may overlap other regions, which is fine as long as the
+// sections placed in them don't overlap.
+func enlargeMemoryRegions(script []byte) []byte {
+	return memoryLengthRegexp.ReplaceAll(script, []byte("${1}LENGTH = 16M + "))
+}
+
+// PrintSizeDiff prints how the size of a program changed between two builds,
+// per package and per symbol. Both paths can either be an executable or a JSON
+// file created with -size=json. Only packages and symbols that changed in size
+// are printed.
+//
+// If maxGrowth is not negative, an error is returned when flash usage grew by
+// more than maxGrowth bytes. This can be used in CI to catch size regressions.
+func PrintSizeDiff(w io.Writer, oldPath, newPath string, maxGrowth int64) error {
+	oldSizes, err := loadSizeReport(oldPath)
+	if err != nil {
+		return err
+	}
+	newSizes, err := loadSizeReport(newPath)
+	if err != nil {
+		return err
+	}
+	printSizeDiff(w, oldSizes, newSizes)
+	return checkSizeGrowth(oldSizes, newSizes, maxGrowth)
+}
+
+// checkSizeGrowth returns an error if the flash usage of the new program is more
+// than maxGrowth bytes larger than that of the old program. A negative
+// maxGrowth means there is no limit.
+func checkSizeGrowth(oldSizes, newSizes *programSize, maxGrowth int64) error {
+	growth := int64(newSizes.Flash()) - int64(oldSizes.Flash())
+	if maxGrowth >= 0 && growth > maxGrowth {
+		return fmt.Errorf("flash usage grew by %d bytes, which is more than the maximum of %d bytes", growth, maxGrowth)
+	}
+	return nil
+}
+
+// loadSizeReport loads the program size from an executable or from a JSON file
+// created with -size=json.
+func loadSizeReport(path string) (*programSize, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) != 0 && bytes.TrimSpace(data)[0] == '{' {
+		sizes := &programSize{}
+		err := json.Unmarshal(data, sizes)
+		if err != nil {
+			return nil, fmt.Errorf("could not read size report %s: %w", path, err)
+		}
+		return sizes, nil
+	}
+
+	// The packages of an executable are only known by their directory, as
+	// there is no mapping to import paths like there is while building. Guess
+	// the import paths of packages in the standard library and in TinyGo.
+	sizes, err := loadProgramSize(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var roots []string
+	for _, root := range []string{goenv.Get("TINYGOROOT"), goenv.Get("GOROOT")} {
+		if root != "" {
+			roots = append(roots, filepath.Join(root, "src"))
+		}
+	}
+	guessPackage := func(name string) string {
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, name); err == nil && filepath.IsAbs(name) && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return name
+	}
+	packages := make(map[string]packageSize, len(sizes.Packages))
+	for name, pkgSize := range sizes.Packages {
+		name = guessPackage(name)
+		field := packages[name]
+		field.Code += pkgSize.Code
+		field.ROData += pkgSize.ROData
+		field.Data += pkgSize.Data
+		field.BSS += pkgSize.BSS
+		packages[name] = field
+	}
+	for i := range sizes.Symbols {
+		sizes.Symbols[i].Package = guessPackage(sizes.Symbols[i].Package)
+	}
+	sizes.Packages = packages
+	return sizes, nil
+}
+
+// printSizeDiff prints the difference between two program sizes, see
+// PrintSizeDiff.
+func printSizeDiff(w io.Writer, oldSizes, newSizes *programSize) {
+	// Print all packages that changed, in alphabetical order.
+	names := append(oldSizes.sortedPackageNames(), newSizes.sortedPackageNames()...)
+	sort.Strings(names)
+	fmt.Fprintf(w, "   code  rodata    data     bss |   flash     ram | package\n")
+	fmt.Fprintf(w, "------------------------------- | --------------- | -------\n")
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue // present in both programs
+		}
+		oldSize := oldSizes.Packages[name]
+		newSize := newSizes.Packages[name]
+		if oldSize == newSize {
+			continue
+		}
+		fmt.Fprintf(w, "%7s %7s %7s %7s | %7s %7s | %s\n",
+			sizeDelta(oldSize.Code, newSize.Code),
+			sizeDelta(oldSize.ROData, newSize.ROData),
+			sizeDelta(oldSize.Data, newSize.Data),
+			sizeDelta(oldSize.BSS, newSize.BSS),
+			sizeDelta(oldSize.Flash(), newSize.Flash()),
+			sizeDelta(oldSize.RAM(), newSize.RAM()),
+			name)
+	}
+	fmt.Fprintf(w, "------------------------------- | --------------- | -------\n")
+	fmt.Fprintf(w, "%7s %7s %7s %7s | %7s %7s | total\n",
+		sizeDelta(oldSizes.Code, newSizes.Code),
+		sizeDelta(oldSizes.ROData, newSizes.ROData),
+		sizeDelta(oldSizes.Data, newSizes.Data),
+		sizeDelta(oldSizes.BSS, newSizes.BSS),
+		sizeDelta(oldSizes.Flash(), newSizes.Flash()),
+		sizeDelta(oldSizes.RAM(), newSizes.RAM()))
+
+	// Print all symbols that changed, the ones that grew the most first.
+	// Symbols with the same name (static functions in C for example) are
+	// added together.
+	type symbolDiff struct {
+		name         string
+		old, new     symbolSize
+		inOld, inNew bool
+		flashDiff    int64
+		ramDiff      int64
+	}
+	diffs := make(map[string]*symbolDiff)
+	getDiff := func(name string) *symbolDiff {
+		if diffs[name] == nil {
+			diffs[name] = &symbolDiff{name: name}
+		}
+		return diffs[name]
+	}
+	for _, symbol := range oldSizes.Symbols {
+		diff := getDiff(symbol.Name)
+		diff.inOld = true
+		diff.old = addSymbolSizes(diff.old, symbol)
+	}
+	for _, symbol := range newSizes.Symbols {
+		diff := getDiff(symbol.Name)
+		diff.inNew = true
+		diff.new = addSymbolSizes(diff.new, symbol)
+	}
+	var changed []*symbolDiff
+	for _, diff := range diffs {
+		diff.flashDiff = int64(diff.new.Flash()) - int64(diff.old.Flash())
+		diff.ramDiff = int64(diff.new.RAM()) - int64(diff.old.RAM())
+		if diff.flashDiff != 0 || diff.ramDiff != 0 || diff.inOld != diff.inNew {
+			changed = append(changed, diff)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].flashDiff != changed[j].flashDiff {
+			return changed[i].flashDiff > changed[j].flashDiff
+		}
+		if changed[i].ramDiff != changed[j].ramDiff {
+			return changed[i].ramDiff > changed[j].ramDiff
+		}
+		return changed[i].name < changed[j].name
+	})
+	fmt.Fprintf(w, "\n  flash     ram | symbol\n")
+	fmt.Fprintf(w, "--------------- | ------\n")
+	for _, diff := range changed {
+		name := diff.name
+		if !diff.inOld {
+			name += " (added)"
+		} else if !diff.inNew {
+			name += " (removed)"
+		}
+		fmt.Fprintf(w, "%7s %7s | %s\n", sizeDelta(diff.old.Flash(), diff.new.Flash()), sizeDelta(diff.old.RAM(), diff.new.RAM()), name)
+	}
+}
+
+// addSymbolSizes returns the sum of the sizes of two symbols.
+func addSymbolSizes(a, b symbolSize) symbolSize {
+	a.Code += b.Code
+	a.ROData += b.ROData
+	a.Data += b.Data
+	a.BSS += b.BSS
+	return a
+}
+
+// sizeDelta formats the difference between two sizes, with a sign if it
+// changed.
+func sizeDelta(oldSize, newSize uint64) string {
+	if oldSize == newSize {
+		return "0"
+	}
+	return fmt.Sprintf("%+d", int64(newSize)-int64(oldSize))
+}
+
+// sourceLocation is the place in the source code where a function or global
+// variable is declared.
+type sourceLocation struct {
+	File string
+	Line int
+}
+
 // readProgramSizeFromDWARF reads the source location for each line of code and
 // each variable in the program, as far as this is stored in the DWARF debug
-// information.
-func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLine, error) {
+// information. It also returns where each function and variable is declared,
+// indexed by its address.
+func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLine, map[uint64]sourceLocation, error) {
 	r := data.Reader()
 	var lines []*dwarf.LineFile
 	var addresses []addressLine
+	locations := make(map[uint64]sourceLocation)
+
+	// declLocation returns the location of the given declaration, or false if
+	// it isn't known.
+	declLocation := func(e *dwarf.Entry) (sourceLocation, bool) {
+		file, _ := e.Val(dwarf.AttrDeclFile).(int64)
+		line, _ := e.Val(dwarf.AttrDeclLine).(int64)
+		if file < 0 || file >= int64(len(lines)) || lines[file] == nil {
+			return sourceLocation{}, false
+		}
+		return sourceLocation{File: lines[file].Name, Line: int(line)}, true
+	}
+
+	// Used to read the declaration of functions that were inlined somewhere
+	// and also emitted separately (in which case the DWARF entry of the
+	// function refers to an abstract entry).
+	originReader := data.Reader()
+
 	for {
 		e, err := r.Next()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if e == nil {
 			break
@@ -146,61 +585,53 @@ func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLin
 			// for inlined functions!
 			lr, err := data.LineReader(e)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+			if lr == nil {
+				// Compile unit without line table, which may happen in
+				// executables not created by TinyGo (see tinygo size-diff).
+				lines = nil
+				continue
 			}
 			lines = lr.Files()
-			var lineEntry = dwarf.LineEntry{
-				EndSequence: true,
+			err = readLineChunks(lr, func(entry *dwarf.LineEntry, length uint64) {
+				addresses = append(addresses, addressLine{
+					Address: entry.Address + codeOffset,
+					Length:  length,
+					File:    entry.File.Name,
+					Line:    entry.Line,
+				})
+			})
+			if err != nil {
+				return nil, nil, err
 			}
+		case dwarf.TagSubprogram:
+			// Function. Only the location of the declaration is needed, the
+			// code itself is described by the line table.
+			r.SkipChildren()
 
-			// Line tables are organized as sequences of line entries until an
-			// end sequence. A single line table can contain multiple such
-			// sequences. The last line entry is an EndSequence to indicate the
-			// end.
-			for {
-				// Read the next .debug_line entry.
-				prevLineEntry := lineEntry
-				err := lr.Next(&lineEntry)
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
-					return nil, err
-				}
-
-				if prevLineEntry.EndSequence && lineEntry.Address == 0 {
-					// Tombstone value. This symbol has been removed, for
-					// example by the --gc-sections linker flag. It is still
-					// here in the debug information because the linker can't
-					// just remove this reference.
-					// Read until the next EndSequence so that this sequence is
-					// skipped.
-					// For more details, see (among others):
-					// https://reviews.llvm.org/D84825
-					for {
-						err := lr.Next(&lineEntry)
-						if err != nil {
-							return nil, err
-						}
-						if lineEntry.EndSequence {
-							break
-						}
-					}
-				}
-
-				if !prevLineEntry.EndSequence {
-					// The chunk describes the code from prevLineEntry to
-					// lineEntry.
-					line := addressLine{
-						Address: prevLineEntry.Address + codeOffset,
-						Length:  lineEntry.Address - prevLineEntry.Address,
-						File:    prevLineEntry.File.Name,
+			lowpc, ok := e.Val(dwarf.AttrLowpc).(uint64)
+			if !ok {
+				// Declaration or abstract entry of an inlined function.
+				continue
+			}
+			decl := e
+			for _, attr := range []dwarf.Attr{dwarf.AttrAbstractOrigin, dwarf.AttrSpecification} {
+				if offset, ok := e.Val(attr).(dwarf.Offset); ok {
+					originReader.Seek(offset)
+					origin, err := originReader.Next()
+					if err != nil {
+						return nil, nil, err
 					}
-					if line.Length != 0 {
-						addresses = append(addresses, line)
+					if origin != nil {
+						decl = origin
 					}
+					break
 				}
 			}
+			if location, ok := declLocation(decl); ok {
+				locations[lowpc+codeOffset] = location
+			}
 		case dwarf.TagVariable:
 			// Global variable (or constant). Most of these are not actually
 			// stored in the binary, because they have been optimized out. Only
@@ -210,7 +641,7 @@ func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLin
 			file := e.AttrField(dwarf.AttrDeclFile)
 			location := e.AttrField(dwarf.AttrLocation)
 			globalType := e.AttrField(dwarf.AttrType)
-			if file == nil || location == nil || globalType == nil {
+			if file == nil || location == nil || globalType == nil || file.Val.(int64) >= int64(len(lines)) {
 				// Doesn't contain the requested information.
 				continue
 			}
@@ -239,7 +670,10 @@ func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLin
 			// only in the size.
 			typ, err := data.Type(globalType.Val.(dwarf.Offset))
 			if err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+			if location, ok := declLocation(e); ok {
+				locations[addr] = location
 			}
 
 			addresses = append(addresses, addressLine{
@@ -252,7 +686,60 @@ func readProgramSizeFromDWARF(data *dwarf.Data, codeOffset uint64) ([]addressLin
 			r.SkipChildren()
 		}
 	}
-	return addresses, nil
+	return addresses, locations, nil
+}
+
+// readLineChunks reads a DWARF line table and calls chunk for each chunk of
+// code in it: the line entry describing the code and the length of the code in
+// bytes. Sequences for code that was removed by the linker are skipped.
+func readLineChunks(lr *dwarf.LineReader, chunk func(entry *dwarf.LineEntry, length uint64)) error {
+	var lineEntry = dwarf.LineEntry{
+		EndSequence: true,
+	}
+
+	// Line tables are organized as sequences of line entries until an end
+	// sequence. A single line table can contain multiple such sequences. The
+	// last line entry is an EndSequence to indicate the end.
+	for {
+		// Read the next .debug_line entry.
+		prevLineEntry := lineEntry
+		err := lr.Next(&lineEntry)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		if prevLineEntry.EndSequence && lineEntry.Address == 0 {
+			// Tombstone value. This symbol has been removed, for example by
+			// the --gc-sections linker flag. It is still here in the debug
+			// information because the linker can't just remove this
+			// reference.
+			// Read until the next EndSequence so that this sequence is
+			// skipped.
+			// For more details, see (among others):
+			// https://reviews.llvm.org/D84825
+			for {
+				err := lr.Next(&lineEntry)
+				if err != nil {
+					return err
+				}
+				if lineEntry.EndSequence {
+					break
+				}
+			}
+		}
+
+		if !prevLineEntry.EndSequence {
+			// The chunk describes the code from prevLineEntry to lineEntry.
+			length := lineEntry.Address - prevLineEntry.Address
+			if length != 0 {
+				chunk(&prevLineEntry, length)
+			}
+		}
+	}
+	return nil
 }
 
 // loadProgramSize calculate a program/data size breakdown of each package for a
@@ -271,13 +758,17 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 	// This stores all chunks of addresses found in the binary.
 	var addresses []addressLine
 
+	// Sizes of individual symbols, as far as they're known.
+	var symbols []symbolSize
+
 	// Load the binary file, which could be in a number of file formats.
 	var sections []memorySection
 	if file, err := elf.NewFile(f); err == nil {
 		// Read DWARF information. The error is intentionally ignored.
 		data, _ := file.DWARF()
+		var locations map[uint64]sourceLocation
 		if data != nil {
-			addresses, err = readProgramSizeFromDWARF(data, 0)
+			addresses, locations, err = readProgramSizeFromDWARF(data, 0)
 			if err != nil {
 				// However, _do_ report an error here. Something must have gone
 				// wrong while trying to parse DWARF data.
@@ -319,6 +810,17 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 					IsVariable: true,
 				})
 			}
+			size := elfSymbolSize(symbol, section)
+			if file.Machine == elf.EM_ARM && symType == elf.STT_FUNC {
+				// The lowest bit of Thumb functions is set, but it isn't part
+				// of the address.
+				size.Address &^= 1
+			}
+			if location, ok := locations[size.Address]; ok {
+				size.File = location.File
+				size.Line = location.Line
+			}
+			symbols = append(symbols, size)
 		}
 
 		// Load allocated sections.
@@ -372,7 +874,7 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 		// Read DWARF information. The error is intentionally ignored.
 		data, _ := file.DWARF()
 		if data != nil {
-			addresses, err = readProgramSizeFromDWARF(data, 0)
+			addresses, _, err = readProgramSizeFromDWARF(data, 0)
 			if err != nil {
 				// However, _do_ report an error here. Something must have gone
 				// wrong while trying to parse DWARF data.
@@ -444,7 +946,7 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 		// Read DWARF information. The error is intentionally ignored.
 		data, err := file.DWARF()
 		if data != nil {
-			addresses, err = readProgramSizeFromDWARF(data, codeOffset)
+			addresses, _, err = readProgramSizeFromDWARF(data, codeOffset)
 			if err != nil {
 				// However, _do_ report an error here. Something must have gone
 				// wrong while trying to parse DWARF data.
@@ -571,9 +1073,33 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 		}
 	}
 
+	// Attribute each symbol to the package it is declared in or, if that isn't
+	// known, to the package of its first byte.
+	for i := range symbols {
+		symbol := &symbols[i]
+		if symbol.File != "" {
+			symbol.Package = findPackagePath(symbol.File, packagePathMap)
+		} else if line := findAddressLine(addresses, symbol.Address); line != nil {
+			symbol.Package = findPackagePath(line.File, packagePathMap)
+		} else {
+			symbol.Package = "(unknown)"
+		}
+	}
+
+	// Add up the code generated for each line of source code.
+	lines := readLineSizes(sections, addresses, packagePathMap)
+
 	// ...and summarize the results.
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Name == symbols[j].Name {
+			return symbols[i].Address < symbols[j].Address
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
 	program := &programSize{
 		Packages: sizes,
+		Symbols:  symbols,
+		Lines:    lines,
 	}
 	for _, pkg := range sizes {
 		program.Code += pkg.Code
@@ -584,6 +1110,95 @@ func loadProgramSize(path string, packagePathMap map[string]string) (*programSiz
 	return program, nil
 }
 
+// readLineSizes returns the number of bytes of code generated for each line of
+// source code, the largest first. Like readSection, bytes covered by more than
+// one line entry are only counted once.
+func readLineSizes(sections []memorySection, addresses []addressLine, packagePathMap map[string]string) []lineSize {
+	type fileLine struct {
+		file string
+		line int
+	}
+	sizes := make(map[fileLine]uint64)
+	for _, section := range sections {
+		if section.Type != memoryCode {
+			continue
+		}
+		addr := section.Address
+		sectionEnd := section.Address + section.Size
+		for _, line := range addresses {
+			if line.IsVariable || line.Address < section.Address || line.Address+line.Length > sectionEnd {
+				continue
+			}
+			if addr >= line.Address+line.Length {
+				// Already covered by a previous line entry.
+				continue
+			}
+			length := line.Length
+			if addr > line.Address {
+				length -= addr - line.Address
+			}
+			addr = line.Address + line.Length
+			if line.Line != 0 {
+				sizes[fileLine{line.File, line.Line}] += length
+			}
+		}
+	}
+
+	lines := make([]lineSize, 0, len(sizes))
+	for key, size := range sizes {
+		lines = append(lines, lineSize{
+			Package: findPackagePath(key.file, packagePathMap),
+			File:    key.file,
+			Line:    key.line,
+			Code:    size,
+		})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Code != lines[j].Code {
+			return lines[i].Code > lines[j].Code
+		}
+		if lines[i].File != lines[j].File {
+			return lines[i].File < lines[j].File
+		}
+		return lines[i].Line < lines[j].Line
+	})
+	return lines
+}
+
+// elfSymbolSize returns the size of an ELF symbol, counted as code, rodata,
+// data or bss depending on the section it is in (just like the sections in
+// loadProgramSize).
+func elfSymbolSize(symbol elf.Symbol, section *elf.Section) symbolSize {
+	size := symbolSize{
+		Name:    symbol.Name,
+		Address: symbol.Value,
+	}
+	switch {
+	case section.Type == elf.SHT_NOBITS:
+		size.BSS = symbol.Size
+	case section.Flags&elf.SHF_EXECINSTR != 0:
+		size.Code = symbol.Size
+	case section.Flags&elf.SHF_WRITE != 0:
+		size.Data = symbol.Size
+	default:
+		size.ROData = symbol.Size
+	}
+	return size
+}
+
+// findAddressLine returns the chunk of code or data that contains the given
+// address, or nil if there is none. The addresses must be sorted by address.
+func findAddressLine(addresses []addressLine, addr uint64) *addressLine {
+	// Find the last chunk that starts at or before addr.
+	i := sort.Search(len(addresses), func(i int) bool {
+		return addresses[i].Address > addr
+	}) - 1
+	if i < 0 || addr >= addresses[i].Address+addresses[i].Length {
+		return nil
+	}
+	return &addresses[i]
+}
+
 // readSection determines for each byte in this section to which package it
 // belongs. It reports this usage through the addSize callback.
 func readSection(section memorySection, addresses []addressLine, addSize func(string, uint64, bool), packagePathMap map[string]string) {
@@ -0,0 +1,286 @@
+package builder
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testProgramSize returns the size of a small (made up) program, as it would be
+// loaded from an executable with debug information. It returns a new copy each
+// time so that tests can modify it.
+func testProgramSize() *programSize {
+	return &programSize{
+		Packages: map[string]packageSize{
+			"(unknown)": {Code: 120},
+			"C stack":   {BSS: 2048},
+			"main":      {Code: 100, ROData: 20, Data: 8},
+			"runtime":   {Code: 400, BSS: 64},
+		},
+		Symbols: []symbolSize{
+			{Name: "__aeabi_memcpy", Address: 0x1000, Package: "(unknown)", Code: 120},
+			{Name: "main$string", Address: 0x2000, Package: "main", ROData: 20},
+			{Name: "main.counter", Address: 0x20000000, Package: "main", File: "/home/user/hello/main.go", Line: 5, Data: 8},
+			{Name: "main.main", Address: 0x1078, Package: "main", File: "/home/user/hello/main.go", Line: 7, Code: 100},
+			{Name: "runtime.alloc", Address: 0x10dc, Package: "runtime", File: "/tinygo/src/runtime/gc_blocks.go", Line: 270, Code: 400},
+			{Name: "runtime.heapptr", Address: 0x20000008, Package: "runtime", File: "/tinygo/src/runtime/gc_blocks.go", Line: 40, BSS: 64},
+		},
+		Lines: []lineSize{
+			{Package: "runtime", File: "/tinygo/src/runtime/gc_blocks.go", Line: 272, Code: 300},
+			{Package: "runtime", File: "/tinygo/src/runtime/gc_blocks.go", Line: 280, Code: 100},
+			{Package: "main", File: "/home/user/hello/main.go", Line: 8, Code: 60},
+			{Package: "main", File: "/home/user/hello/main.go", Line: 9, Code: 40},
+		},
+		Code: 620, ROData: 20, Data: 8, BSS: 2112,
+	}
+}
+
+func TestSizeDiff(t *testing.T) {
+	// The new program has a larger main.main, no longer uses runtime.heapptr
+	// and uses strconv.Itoa.
+	oldSizes := testProgramSize()
+	newSizes := testProgramSize()
+	newSizes.Packages["main"] = packageSize{Code: 150, ROData: 20, Data: 8}
+	newSizes.Packages["runtime"] = packageSize{Code: 400}
+	newSizes.Packages["strconv"] = packageSize{Code: 200, ROData: 10}
+	newSizes.Symbols[3].Code = 150
+	newSizes.Symbols = append(newSizes.Symbols[:5],
+		symbolSize{Name: "strconv.Itoa", Package: "strconv", Code: 200},
+		symbolSize{Name: "strconv$string", Package: "strconv", ROData: 10})
+	newSizes.Code, newSizes.ROData, newSizes.BSS = 870, 30, 2048
+
+	buf := &bytes.Buffer{}
+	printSizeDiff(buf, oldSizes, newSizes)
+	expected := `   code  rodata    data     bss |   flash     ram | package
+------------------------------- | --------------- | -------
+    +50       0       0       0 |     +50       0 | main
+      0       0       0     -64 |       0     -64 | runtime
+   +200     +10       0       0 |    +210       0 | strconv
+------------------------------- | --------------- | -------
+   +250     +10       0     -64 |    +260     -64 | total
+
+  flash     ram | symbol
+--------------- | ------
+   +200       0 | strconv.Itoa (added)
+    +50       0 | main.main
+    +10       0 | strconv$string (added)
+      0     -64 | runtime.heapptr (removed)
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected size diff:\n%s", buf.String())
+	}
+
+	// Flash usage went down, so there is no growth to check.
+	if err := checkSizeGrowth(newSizes, oldSizes, 0); err != nil {
+		t.Error("unexpected error when flash usage shrunk:", err)
+	}
+	if err := checkSizeGrowth(oldSizes, newSizes, -1); err != nil {
+		t.Error("unexpected error without a maximum growth:", err)
+	}
+	if err := checkSizeGrowth(oldSizes, newSizes, 260); err != nil {
+		t.Error("unexpected error within the maximum growth:", err)
+	}
+	err := checkSizeGrowth(oldSizes, newSizes, 100)
+	if err == nil || err.Error() != "flash usage grew by 260 bytes, which is more than the maximum of 100 bytes" {
+		t.Errorf("unexpected growth error: %v", err)
+	}
+}
+
+func TestSizeReportJSON(t *testing.T) {
+	sizes := testProgramSize()
+	buf := &bytes.Buffer{}
+	err := printProgramSize(buf, sizes, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "sizes.json")
+	err = ioutil.WriteFile(path, buf.Bytes(), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSizeReport(path)
+	if err != nil {
+		t.Fatal("could not load size report:", err)
+	}
+	if !reflect.DeepEqual(sizes, loaded) {
+		t.Errorf("size report changed after loading it:\n%#v", loaded)
+	}
+}
+
+func TestSizeSymbols(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := printProgramSize(buf, testProgramSize(), "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `   code  rodata    data     bss |   flash     ram | symbol
+------------------------------- | --------------- | ------
+    400       0       0       0 |     400       0 | runtime.alloc (runtime/gc_blocks.go:270)
+    120       0       0       0 |     120       0 | __aeabi_memcpy
+    100       0       0       0 |     100       0 | main.main (main/main.go:7)
+      0      20       0       0 |      20       0 | main$string (main)
+      0       0       8       0 |       8       8 | main.counter (main/main.go:5)
+      0       0       0      64 |       0      64 | runtime.heapptr (runtime/gc_blocks.go:40)
+------------------------------- | --------------- | ------
+    620      20       8    2112 |     648    2120 | total
+
+   code | line
+------- | ----
+    300 | runtime/gc_blocks.go:272
+    100 | runtime/gc_blocks.go:280
+     60 | main/main.go:8
+     40 | main/main.go:9
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected symbol sizes:\n%s", buf.String())
+	}
+}
+
+func TestSizeReportCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := printProgramSize(buf, testProgramSize(), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `kind,name,package,file,line,code,rodata,data,bss,flash,ram
+package,(unknown),(unknown),,,120,0,0,0,120,0
+package,C stack,C stack,,,0,0,0,2048,0,2048
+package,main,main,,,100,20,8,0,128,8
+package,runtime,runtime,,,400,0,0,64,400,64
+symbol,__aeabi_memcpy,(unknown),,,120,0,0,0,120,0
+symbol,main$string,main,,,0,20,0,0,20,0
+symbol,main.counter,main,/home/user/hello/main.go,5,0,0,8,0,8,8
+symbol,main.main,main,/home/user/hello/main.go,7,100,0,0,0,100,0
+symbol,runtime.alloc,runtime,/tinygo/src/runtime/gc_blocks.go,270,400,0,0,0,400,0
+symbol,runtime.heapptr,runtime,/tinygo/src/runtime/gc_blocks.go,40,0,0,0,64,0,64
+line,,runtime,/tinygo/src/runtime/gc_blocks.go,272,300,0,0,0,300,0
+line,,runtime,/tinygo/src/runtime/gc_blocks.go,280,100,0,0,0,100,0
+line,,main,/home/user/hello/main.go,8,60,0,0,0,60,0
+line,,main,/home/user/hello/main.go,9,40,0,0,0,40,0
+total,,,,,620,20,8,2112,648,2120
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected CSV size report:\n%s", buf.String())
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	sizes := testProgramSize()
+	if err := checkMemoryBudget(sizes, 0, 0); err != nil {
+		t.Error("unexpected error without budget:", err)
+	}
+	if err := checkMemoryBudget(sizes, 648, 2120); err != nil {
+		t.Error("unexpected error within budget:", err)
+	}
+
+	err := checkMemoryBudget(sizes, 512, 0)
+	expected := `program uses 648 bytes of flash which exceeds the budget of 512 bytes by 136 bytes
+   code  rodata    data     bss |   flash     ram | package
+------------------------------- | --------------- | -------
+    400       0       0      64 |     400      64 | runtime
+    100      20       8       0 |     128       8 | main
+    120       0       0       0 |     120       0 | (unknown)
+      0       0       0    2048 |       0    2048 | C stack
+------------------------------- | --------------- | -------
+    620      20       8    2112 |     648    2120 | total`
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected flash budget error:\n%v", err)
+	}
+
+	err = checkMemoryBudget(sizes, 0, 2048)
+	if err == nil || !strings.HasPrefix(err.Error(), "program uses 2120 bytes of RAM which exceeds the budget of 2048 bytes by 72 bytes\n") {
+		t.Errorf("unexpected RAM budget error:\n%v", err)
+	}
+}
+
+func TestLoadProgramSize(t *testing.T) {
+	sizes, err := loadProgramSize(buildTestProgram(t), nil)
+	if err != nil {
+		t.Fatal("could not load program size:", err)
+	}
+
+	// The exact addresses and code sizes depend on the compiler, so only check
+	// that every function has some code.
+	var functionCode uint64
+	for i := range sizes.Symbols {
+		symbol := &sizes.Symbols[i]
+		if symbol.Address == 0 {
+			t.Errorf("symbol %s has no address", symbol.Name)
+		}
+		symbol.Address = 0
+		symbol.Package = ""
+		if symbol.File != "" {
+			// The directory depends on where the program was compiled.
+			symbol.File = filepath.Base(symbol.File)
+		}
+		if symbol.Code != 0 {
+			functionCode += symbol.Code
+			symbol.Code = 1
+		}
+	}
+	expectedSymbols := []symbolSize{
+		{Name: "_start", File: "program.c", Line: 23, Code: 1},
+		{Name: "add", File: "program.c", Line: 10, Code: 1},
+		{Name: "compute", File: "program.c", Line: 15, Code: 1},
+		{Name: "counter", File: "program.c", Line: 6, BSS: 4},
+		{Name: "message", File: "program.c", Line: 7, ROData: 12},
+		{Name: "table", File: "program.c", Line: 8, BSS: 64},
+	}
+	if !reflect.DeepEqual(sizes.Symbols, expectedSymbols) {
+		t.Errorf("unexpected symbols:\n%+v", sizes.Symbols)
+	}
+	if sizes.Code < functionCode || sizes.ROData < 12 || sizes.Data != 0 || sizes.BSS < 4+64 {
+		t.Errorf("unexpected program size: code=%d rodata=%d data=%d bss=%d", sizes.Code, sizes.ROData, sizes.Data, sizes.BSS)
+	}
+
+	// The line table covers the code of all functions, except for some
+	// instructions that don't belong to a line.
+	var lineCode uint64
+	lines := make(map[int]uint64)
+	for _, line := range sizes.Lines {
+		if filepath.Base(line.File) != "program.c" {
+			t.Errorf("unexpected file for line %d: %s", line.Line, line.File)
+		}
+		lines[line.Line] = line.Code
+		lineCode += line.Code
+	}
+	if lineCode == 0 || lineCode > sizes.Code {
+		t.Errorf("line sizes add up to %d bytes, expected at most %d bytes", lineCode, sizes.Code)
+	}
+	for _, line := range []int{11, 18, 24} {
+		if lines[line] == 0 {
+			t.Errorf("no code for line %d: %v", line, lines)
+		}
+	}
+}
+
+func TestEnlargeMemoryRegions(t *testing.T) {
+	script := `MEMORY
+{
+    FLASH_TEXT (rw) : ORIGIN = 0x00000000+0x4000, LENGTH = 0x00080000-0x4000  /* bootloader */
+    RAM (xrw)       : ORIGIN = 0x20000000, LENGTH=256K
+    BOOT2 (r)       : org = 0x10000000, len = 256
+    EEPROM (rw)     : o = 0x810000, l = 4K
+}
+
+_heap_end = ORIGIN(RAM) + LENGTH(RAM);
+l = 4;
+`
+	expected := `MEMORY
+{
+    FLASH_TEXT (rw) : ORIGIN = 0x00000000+0x4000, LENGTH = 16M + 0x00080000-0x4000  /* bootloader */
+    RAM (xrw)       : ORIGIN = 0x20000000, LENGTH = 16M + 256K
+    BOOT2 (r)       : org = 0x10000000, LENGTH = 16M + 256
+    EEPROM (rw)     : o = 0x810000, LENGTH = 16M + 4K
+}
+
+_heap_end = ORIGIN(RAM) + LENGTH(RAM);
+l = 4;
+`
+	if result := string(enlargeMemoryRegions([]byte(script))); result != expected {
+		t.Errorf("unexpected linker script:\n%s", result)
+	}
+}
@@ -0,0 +1,67 @@
+; Test input for findIndirectCalls (see build_test.go).
+target datalayout = "e-m:e-p:32:32-Fi8-i64:64-v128:64:128-a:0:32-n32-S64"
+target triple = "thumbv7m-unknown-unknown-eabi"
+
+; Func values, marked by interface lowering.
+@funcValue1 = global { i8*, void ()* } { i8* null, void ()* bitcast (void (i32, i8*)* @funcValueTarget1 to void ()*) }
+@funcValue2 = global { i8*, void ()* } { i8* null, void ()* bitcast (void (i32, i8*)* @funcValueTarget2 to void ()*) }
+
+; Functions of which the address is taken, but that aren't func values.
+@pointer1 = global void (i32, i8*)* @addressTaken
+@pointer2 = global void (i64, i8*)* @onlyAddressTaken
+
+define void @funcValueTarget1(i32 %x, i8* %context) #0 {
+  ret void
+}
+
+define void @funcValueTarget2(i32 %x, i8* %context) #0 {
+  ret void
+}
+
+define void @addressTaken(i32 %x, i8* %context) {
+  ret void
+}
+
+define void @onlyAddressTaken(i64 %x, i8* %context) {
+  ret void
+}
+
+define void @otherSignature(i8* %context) {
+  ret void
+}
+
+; Calls a func value: only the marked functions of the same type may be called.
+define void @callsFuncValue(void (i32, i8*)* %fn) {
+  call void %fn(i32 1, i8* undef)
+  call void %fn(i32 2, i8* undef)
+  ret void
+}
+
+; Calls a function pointer of a type that has no func values.
+define void @callsPointer(void (i64, i8*)* %fn) {
+  call void %fn(i64 1, i8* undef)
+  ret void
+}
+
+; Calls a function at a fixed address, which could be anything.
+define void @callsFixedAddress(void (i32, i8*)* %fn) {
+  call void inttoptr (i32 256 to void ()*)()
+  call void %fn(i32 1, i8* undef)
+  ret void
+}
+
+; Only direct calls, one of them with a different function type.
+define void @callsDirect() {
+  call void @funcValueTarget1(i32 1, i8* undef)
+  call void bitcast (void (i8*)* @otherSignature to void ()*)()
+  ret void
+}
+
+; Annotated with //go:stacksize.
+define void @recursive(i8* %context) #1 {
+  call void @recursive(i8* undef)
+  ret void
+}
+
+attributes #0 = { "tinygo-funcvalue" }
+attributes #1 = { "tinygo-stacksize"="100" }
@@ -0,0 +1,37 @@
+; Test input for internalizeLibrarySymbols (see buildmode_test.go).
+target datalayout = "e-m:e-p:32:32-Fi8-i64:64-v128:64:128-a:0:32-n32-S64"
+target triple = "thumbv7m-unknown-unknown-eabi"
+
+@main.counter = global i32 0
+@runtime.heapStart = global i32 0
+@tinygo_core1Stack = global [64 x i8] zeroinitializer
+@internalGlobal = internal global i32 0
+@externalGlobal = external global i32
+
+declare void @write(i32, i8*, i32)
+
+; Exported from the main package with //export.
+define i32 @add(i32 %a, i32 %b) {
+  %result = add i32 %a, %b
+  ret i32 %result
+}
+
+define void @tinygo_init() {
+  ret void
+}
+
+define void @main() {
+  ret void
+}
+
+define void @Reset_Handler() {
+  ret void
+}
+
+define i8* @malloc(i32 %size) {
+  ret i8* null
+}
+
+define weak void @weakFunction() {
+  ret void
+}
@@ -0,0 +1,27 @@
+// Small test program for the ELF parsing code in the builder package (see
+// addr2line_test.go and sizes_test.go) and for the addr2line command. The
+// tests compile it with the clang and ld.lld that come with TinyGo, and check
+// the line numbers below: don't move any code around.
+
+volatile int counter;
+const char message[] = "hello world";
+int table[16];
+
+int add(int a, int b) {
+	counter++;
+	return a + b;
+}
+
+int compute(int n) {
+	int sum = 0;
+	for (int i = 0; i < n; i++) {
+		sum = add(sum, table[i % 16]);
+	}
+	return sum + message[n % sizeof(message)];
+}
+
+void _start(void) {
+	counter = compute(10);
+	for (;;) {
+	}
+}
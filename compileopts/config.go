@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/tinygo-org/tinygo/goenv"
 )
@@ -72,6 +73,12 @@ func (c *Config) BuildTags() []string {
 	for i := 1; i <= c.GoMinorVersion; i++ {
 		tags = append(tags, fmt.Sprintf("go1.%d", i))
 	}
+	if c.PreemptTimeSlice() != 0 {
+		tags = append(tags, "preempt")
+	}
+	if c.IsLibrary() {
+		tags = append(tags, "tinygo.library")
+	}
 	if extraTags := strings.Fields(c.Options.Tags); len(extraTags) != 0 {
 		tags = append(tags, extraTags...)
 	}
@@ -85,7 +92,7 @@ func (c *Config) CgoEnabled() bool {
 }
 
 // GC returns the garbage collection strategy in use on this platform. Valid
-// values are "none", "leaking", and "conservative".
+// values are "none", "leaking", "conservative", and "precise".
 func (c *Config) GC() string {
 	if c.Options.GC != "" {
 		return c.Options.GC
@@ -100,7 +107,7 @@ func (c *Config) GC() string {
 // that can be traced by the garbage collector.
 func (c *Config) NeedsStackObjects() bool {
 	switch c.GC() {
-	case "conservative":
+	case "conservative", "precise":
 		for _, tag := range c.BuildTags() {
 			if tag == "tinygo.wasm" {
 				return true
@@ -114,11 +121,16 @@ func (c *Config) NeedsStackObjects() bool {
 }
 
 // Scheduler returns the scheduler implementation. Valid values are "none",
-// "asyncify" and "tasks".
+// "asyncify", "tasks" and "cores".
 func (c *Config) Scheduler() string {
 	if c.Options.Scheduler != "" {
 		return c.Options.Scheduler
 	}
+	if c.IsLibrary() {
+		// Exported functions are called directly from C, there is no
+		// scheduler loop that could run other goroutines.
+		return "none"
+	}
 	if c.Target.Scheduler != "" {
 		return c.Target.Scheduler
 	}
@@ -126,6 +138,30 @@ func (c *Config) Scheduler() string {
 	return "none"
 }
 
+// BuildMode returns the kind of output file to produce: "default" for an
+// executable, "c-archive" for a static library or "c-shared" for a shared
+// library.
+func (c *Config) BuildMode() string {
+	if c.Options.BuildMode != "" {
+		return c.Options.BuildMode
+	}
+	return "default"
+}
+
+// IsLibrary returns whether the output is a library to be linked into a
+// program written in another language (-buildmode=c-archive or c-shared),
+// instead of an executable.
+func (c *Config) IsLibrary() bool {
+	return c.BuildMode() == "c-archive" || c.BuildMode() == "c-shared"
+}
+
+// PreemptTimeSlice returns the time a goroutine may run before it is preempted
+// by another goroutine, or 0 if goroutines only switch cooperatively.
+func (c *Config) PreemptTimeSlice() time.Duration {
+	slice, _ := time.ParseDuration(c.Options.Preempt)
+	return slice
+}
+
 // Serial returns the serial implementation for this build configuration: uart,
 // usb (meaning USB-CDC), or none.
 func (c *Config) Serial() string {
@@ -170,17 +206,100 @@ func (c *Config) PanicStrategy() string {
 // automatically at compile time, if possible. If it is false, no attempt is
 // made.
 func (c *Config) AutomaticStackSize() bool {
-	if c.Target.AutoStackSize != nil && c.Scheduler() == "tasks" {
+	if c.Target.AutoStackSize != nil && (c.Scheduler() == "tasks" || c.Scheduler() == "cores") {
 		return *c.Target.AutoStackSize
 	}
 	return false
 }
 
+// FramePointers returns whether all functions should keep a frame pointer.
+// The frame pointer chain is walked by runtime.Callers, for example to print a
+// backtrace on panic. It is only enabled on architectures where the runtime
+// knows how to walk this chain.
+//
+// Frame pointers cost some code size and a register. They are kept by default
+// on Cortex-M so that a panic prints a backtrace (see tinygo addr2line), and
+// can be removed with -tags=noframepointers. Other baremetal systems only keep
+// them when explicitly requested with -tags=framepointers.
+func (c *Config) FramePointers() bool {
+	if c.GOOS() == "windows" {
+		// The frame pointer on Windows doesn't point to the previous frame
+		// pointer, so it cannot be used as a simple linked list.
+		return false
+	}
+	baremetal := false
+	cortexm := false
+	requested := false
+	for _, tag := range c.BuildTags() {
+		switch tag {
+		case "noframepointers":
+			// Explicitly disabled, to save code size.
+			return false
+		case "framepointers":
+			// Explicitly requested, for example to get backtraces on a
+			// microcontroller.
+			requested = true
+		case "baremetal":
+			baremetal = true
+		case "cortexm":
+			cortexm = true
+		}
+	}
+	if baremetal && !cortexm && !requested {
+		return false
+	}
+	return c.framePointersSupported()
+}
+
+// framePointersSupported returns whether the runtime can walk the frame
+// pointer chain on this architecture.
+func (c *Config) framePointersSupported() bool {
+	arch := strings.Split(c.Triple(), "-")[0]
+	switch {
+	case arch == "x86_64" || arch == "i386" || arch == "i686" || arch == "aarch64":
+		return true
+	case strings.HasPrefix(arch, "arm") || strings.HasPrefix(arch, "thumb"):
+		return true
+	default:
+		return false
+	}
+}
+
+// PCTable returns whether a table should be included in the binary that maps
+// program counters back to function names and source locations. This table is
+// used by the runtime to symbolize stack traces. It is only supported on Linux
+// (not on baremetal systems, where it would waste a lot of flash) and requires
+// debug information to be created.
+func (c *Config) PCTable() bool {
+	if !c.FramePointers() || !c.Debug() {
+		return false
+	}
+	if c.GOOS() != "linux" || c.Target.Linker != "ld.lld" {
+		return false
+	}
+	if c.IsLibrary() {
+		// Libraries are either not linked by TinyGo (c-archive) or loaded at
+		// a different address than they were linked at (c-shared).
+		return false
+	}
+	for _, tag := range c.BuildTags() {
+		if tag == "baremetal" {
+			return false
+		}
+	}
+	return true
+}
+
 // UseThinLTO returns whether ThinLTO should be used for the given target. Some
 // targets (such as wasm) are not yet supported.
 // We should try and remove as many exceptions as possible in the future, so
 // that this optimization can be applied in more places.
 func (c *Config) UseThinLTO() bool {
+	if c.IsLibrary() {
+		// The C program may be linked with a linker that doesn't support
+		// LLVM bitcode.
+		return false
+	}
 	parts := strings.Split(c.Triple(), "-")
 	if parts[0] == "wasm32" {
 		// wasm-ld doesn't seem to support ThinLTO yet.
@@ -248,6 +367,12 @@ func (c *Config) LibcPath(name string) (path string, precompiled bool) {
 // DefaultBinaryExtension returns the default extension for binaries, such as
 // .exe, .wasm, or no extension (depending on the target).
 func (c *Config) DefaultBinaryExtension() string {
+	switch c.BuildMode() {
+	case "c-archive":
+		return ".a"
+	case "c-shared":
+		return ".so"
+	}
 	parts := strings.Split(c.Triple(), "-")
 	if parts[0] == "wasm32" {
 		// WebAssembly files always have the .wasm file extension.
@@ -325,6 +450,10 @@ func (c *Config) CFlags() []string {
 	cflags = append(cflags, "-O"+c.Options.Opt)
 	// Set the LLVM target triple.
 	cflags = append(cflags, "--target="+c.Triple())
+	if c.RelocationModel() == "pic" {
+		// Needed to link C and assembly files into a shared library.
+		cflags = append(cflags, "-fPIC")
+	}
 	// Set the -mcpu (or similar) flag.
 	if c.Target.CPU != "" {
 		if c.GOARCH() == "amd64" || c.GOARCH() == "386" {
@@ -361,7 +490,28 @@ func (c *Config) LDFlags() []string {
 // ExtraFiles returns the list of extra files to be built and linked with the
 // executable. This can include extra C and assembly files.
 func (c *Config) ExtraFiles() []string {
-	return c.Target.ExtraFiles
+	files := c.Target.ExtraFiles
+	if c.Scheduler() == "cores" && c.MultiCoreChip() != "" {
+		// Add the startup code for the secondary core.
+		files = append(append([]string{}, files...), "src/runtime/scheduler_cores_"+c.MultiCoreChip()+".S")
+	}
+	if c.PreemptTimeSlice() != 0 {
+		// Add the PendSV handler that switches away from a running goroutine.
+		files = append(append([]string{}, files...), "src/internal/task/task_stack_cortexm_preempt.S")
+	}
+	return files
+}
+
+// MultiCoreChip returns the chip for which goroutines can be run on all cores
+// (with -scheduler=cores), or the empty string if the target doesn't support
+// that.
+func (c *Config) MultiCoreChip() string {
+	for _, tag := range c.Target.BuildTags {
+		if tag == "rp2040" {
+			return tag
+		}
+	}
+	return ""
 }
 
 // DumpSSA returns whether to dump Go SSA while compiling (-dumpssa flag). Only
@@ -474,12 +624,32 @@ func (c *Config) CodeModel() string {
 	return "default"
 }
 
+// MemoryBudget returns the maximum flash and static RAM (data, bss and stacks)
+// usage of the program in bytes. These are the flash-budget and ram-budget
+// properties of the target, or flash-size and ram-size if no budget is set. A
+// value of 0 means there is no limit.
+func (c *Config) MemoryBudget() (flash, ram uint64) {
+	flash = c.Target.FlashSize
+	if c.Target.FlashBudget != 0 {
+		flash = c.Target.FlashBudget
+	}
+	ram = c.Target.RAMSize
+	if c.Target.RAMBudget != 0 {
+		ram = c.Target.RAMBudget
+	}
+	return flash, ram
+}
+
 // RelocationModel returns the relocation model in use on this platform. Valid
 // values are "static", "pic", "dynamicnopic".
 func (c *Config) RelocationModel() string {
 	if c.Target.RelocationModel != "" {
 		return c.Target.RelocationModel
 	}
+	if c.BuildMode() == "c-shared" {
+		// Shared libraries are loaded at an arbitrary address.
+		return "pic"
+	}
 
 	return "static"
 }
@@ -0,0 +1,44 @@
+package compileopts_test
+
+import (
+	"testing"
+
+	"github.com/tinygo-org/tinygo/compileopts"
+)
+
+func TestFramePointers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		goos     string
+		triple   string
+		tags     []string
+		options  string
+		expected bool
+	}{
+		{name: "linux/amd64", goos: "linux", triple: "x86_64-unknown-linux", expected: true},
+		{name: "linux/arm", goos: "linux", triple: "armv7-unknown-linux-gnueabihf", expected: true},
+		{name: "windows/amd64", goos: "windows", triple: "x86_64-unknown-windows-gnu", expected: false},
+		{name: "riscv", triple: "riscv32-unknown-none", tags: []string{"baremetal"}, expected: false},
+		{name: "cortex-m", triple: "thumbv7em-unknown-unknown-eabi", tags: []string{"cortexm", "baremetal"}, expected: true},
+		{name: "cortex-m-notag", triple: "thumbv7em-unknown-unknown-eabi", tags: []string{"cortexm", "baremetal"}, options: "noframepointers", expected: false},
+		{name: "gameboy-advance", triple: "armv4t-unknown-unknown-eabi", tags: []string{"baremetal"}, expected: false},
+		{name: "gameboy-advance-tag", triple: "armv4t-unknown-unknown-eabi", tags: []string{"baremetal"}, options: "framepointers", expected: true},
+		{name: "cortex-m-tag", triple: "thumbv7em-unknown-unknown-eabi", tags: []string{"cortexm", "baremetal"}, options: "framepointers", expected: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config := &compileopts.Config{
+				Options: &compileopts.Options{Tags: tc.options},
+				Target: &compileopts.TargetSpec{
+					GOOS:      tc.goos,
+					Triple:    tc.triple,
+					BuildTags: tc.tags,
+				},
+			}
+			if got := config.FramePointers(); got != tc.expected {
+				t.Errorf("FramePointers() = %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
@@ -4,15 +4,17 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var (
-	validGCOptions            = []string{"none", "leaking", "conservative"}
-	validSchedulerOptions     = []string{"none", "tasks", "asyncify"}
+	validGCOptions            = []string{"none", "leaking", "conservative", "precise"}
+	validSchedulerOptions     = []string{"none", "tasks", "asyncify", "cores"}
 	validSerialOptions        = []string{"none", "uart", "usb"}
-	validPrintSizeOptions     = []string{"none", "short", "full"}
+	validPrintSizeOptions     = []string{"none", "short", "full", "symbols", "json", "csv"}
 	validPanicStrategyOptions = []string{"print", "trap"}
 	validOptOptions           = []string{"none", "0", "1", "2", "s", "z"}
+	validBuildModeOptions     = []string{"default", "c-archive", "c-shared"}
 )
 
 // Options contains extra options to give to the compiler. These options are
@@ -24,9 +26,11 @@ type Options struct {
 	GOARM           string // environment variable (only used with GOARCH=arm)
 	Target          string
 	Opt             string
+	BuildMode       string // -buildmode flag: default, c-archive or c-shared
 	GC              string
 	PanicStrategy   string
 	Scheduler       string
+	Preempt         string // time slice for preemptive scheduling, such as "10ms"
 	Serial          string
 	Work            bool // -work flag to print temporary build directory
 	PrintIR         bool
@@ -38,12 +42,15 @@ type Options struct {
 	PrintSizes      string
 	PrintAllocs     *regexp.Regexp // regexp string
 	PrintStacks     bool
+	ReflectMethods  bool
 	Tags            string
 	WasmAbi         string
 	GlobalValues    map[string]map[string]string // map[pkgpath]map[varname]value
 	TestConfig      TestConfig
 	Programmer      string
 	OpenOCDCommands []string
+	Monitor         bool // -monitor flag to start the serial monitor after flashing
+	BaudRate        int  // baud rate of the serial monitor
 	LLVMFeatures    string
 	Directory       string
 	PrintJSON       bool
@@ -69,6 +76,13 @@ func (o *Options) Verify() error {
 		}
 	}
 
+	if o.Preempt != "" {
+		slice, err := time.ParseDuration(o.Preempt)
+		if err != nil || slice < time.Microsecond {
+			return fmt.Errorf("invalid -preempt=%s: expected a time slice such as 10ms", o.Preempt)
+		}
+	}
+
 	if o.Serial != "" {
 		valid := isInArray(validSerialOptions, o.Serial)
 		if !valid {
@@ -102,6 +116,12 @@ func (o *Options) Verify() error {
 		}
 	}
 
+	if o.BuildMode != "" {
+		if !isInArray(validBuildModeOptions, o.BuildMode) {
+			return fmt.Errorf("invalid -buildmode=%s: valid values are %s", o.BuildMode, strings.Join(validBuildModeOptions, ", "))
+		}
+	}
+
 	return nil
 }
 
@@ -9,10 +9,12 @@ import (
 
 func TestVerifyOptions(t *testing.T) {
 
-	expectedGCError := errors.New(`invalid gc option 'incorrect': valid values are none, leaking, conservative`)
-	expectedSchedulerError := errors.New(`invalid scheduler option 'incorrect': valid values are none, tasks, asyncify`)
-	expectedPrintSizeError := errors.New(`invalid size option 'incorrect': valid values are none, short, full`)
+	expectedGCError := errors.New(`invalid gc option 'incorrect': valid values are none, leaking, conservative, precise`)
+	expectedSchedulerError := errors.New(`invalid scheduler option 'incorrect': valid values are none, tasks, asyncify, cores`)
+	expectedPrintSizeError := errors.New(`invalid size option 'incorrect': valid values are none, short, full, symbols, json, csv`)
 	expectedPanicStrategyError := errors.New(`invalid panic option 'incorrect': valid values are print, trap`)
+	expectedPreemptError := errors.New(`invalid -preempt=incorrect: expected a time slice such as 10ms`)
+	expectedBuildModeError := errors.New(`invalid -buildmode=incorrect: valid values are default, c-archive, c-shared`)
 
 	testCases := []struct {
 		name          string
@@ -67,6 +69,32 @@ func TestVerifyOptions(t *testing.T) {
 				Scheduler: "tasks",
 			},
 		},
+		{
+			name: "InvalidPreemptOption",
+			opts: compileopts.Options{
+				Preempt: "incorrect",
+			},
+			expectedError: expectedPreemptError,
+		},
+		{
+			name: "PreemptOption",
+			opts: compileopts.Options{
+				Preempt: "10ms",
+			},
+		},
+		{
+			name: "InvalidBuildModeOption",
+			opts: compileopts.Options{
+				BuildMode: "incorrect",
+			},
+			expectedError: expectedBuildModeError,
+		},
+		{
+			name: "BuildModeOptionCArchive",
+			opts: compileopts.Options{
+				BuildMode: "c-archive",
+			},
+		},
 		{
 			name: "InvalidPrintSizeOption",
 			opts: compileopts.Options{
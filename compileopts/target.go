@@ -40,6 +40,10 @@ type TargetSpec struct {
 	Libc             string   `json:"libc"`
 	AutoStackSize    *bool    `json:"automatic-stack-size"` // Determine stack size automatically at compile time.
 	DefaultStackSize uint64   `json:"default-stack-size"`   // Default stack size if the size couldn't be determined at compile time.
+	FlashSize        uint64   `json:"flash-size"`           // Flash available to the program in bytes, 0 if unknown.
+	RAMSize          uint64   `json:"ram-size"`             // Static RAM available to the program in bytes, 0 if unknown.
+	FlashBudget      uint64   `json:"flash-budget"`         // Maximum flash usage in bytes, if lower than flash-size.
+	RAMBudget        uint64   `json:"ram-budget"`           // Maximum static RAM usage in bytes, if lower than ram-size.
 	CFlags           []string `json:"cflags"`
 	LDFlags          []string `json:"ldflags"`
 	LinkerScript     string   `json:"linkerscript"`
@@ -321,6 +325,9 @@ func defaultTarget(goos, goarch, triple string) (*TargetSpec, error) {
 		}
 		spec.ExtraFiles = append(spec.ExtraFiles, "src/runtime/gc_"+goarch+suffix+".S")
 		spec.ExtraFiles = append(spec.ExtraFiles, "src/internal/task/task_stack_"+goarch+suffix+".S")
+		if goos == "linux" {
+			spec.ExtraFiles = append(spec.ExtraFiles, "src/runtime/cpuprof/signal_linux.c")
+		}
 	}
 	if goarch != runtime.GOARCH {
 		// Some educated guesses as to how to invoke helper programs.
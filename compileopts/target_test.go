@@ -2,6 +2,7 @@ package compileopts
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -22,6 +23,49 @@ func TestLoadTarget(t *testing.T) {
 	}
 }
 
+func TestLoadTargetMemorySize(t *testing.T) {
+	// Memory sizes are inherited from the chip, unless the board uses a
+	// different linker script (for a bootloader for example).
+	for _, tc := range []struct {
+		target     string
+		flash, ram uint64
+	}{
+		{"nrf52840", 1024 * 1024, 256 * 1024},
+		{"pca10056", 1024 * 1024, 256 * 1024},
+		{"feather-nrf52840", 0xED000 - 0x26000, 0x40000 - 0x4180},
+		{"pca10056-s140v7", 1024*1024 - 0x27000, 256*1024 - 0x39c0},
+		{"pico", 2048*1024 - 256, 256 * 1024},
+		{"feather-rp2040", 8192*1024 - 256, 256 * 1024},
+		{"feather-m4", 0x80000 - 0x4000, 0x30000},
+		{"pyportal", 0x100000 - 0x4000, 0x40000},
+		{"arduino", 0, 0},
+	} {
+		spec, err := LoadTarget(&Options{Target: tc.target})
+		if err != nil {
+			t.Errorf("could not load target %s: %v", tc.target, err)
+			continue
+		}
+		if spec.FlashSize != tc.flash || spec.RAMSize != tc.ram {
+			t.Errorf("unexpected memory size for %s: flash-size=%d ram-size=%d", tc.target, spec.FlashSize, spec.RAMSize)
+		}
+	}
+
+	// The budget in a custom target overrides the size of the chip.
+	path := filepath.Join(t.TempDir(), "budget.json")
+	err := os.WriteFile(path, []byte(`{"inherits": ["feather-m4"], "flash-budget": 262144}`), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadTarget(&Options{Target: path})
+	if err != nil {
+		t.Fatal("could not load target:", err)
+	}
+	config := &Config{Options: &Options{}, Target: spec}
+	if flash, ram := config.MemoryBudget(); flash != 262144 || ram != 0x30000 {
+		t.Errorf("unexpected memory budget: flash=%d ram=%d", flash, ram)
+	}
+}
+
 func TestOverrideProperties(t *testing.T) {
 	baseAutoStackSize := true
 	base := &TargetSpec{
@@ -50,6 +50,8 @@ type Config struct {
 	AutomaticStackSize bool
 	DefaultStackSize   uint64
 	NeedsStackObjects  bool
+	ReflectMethods     bool // Whether to keep method tables for reflect.
+	FramePointers      bool // Whether to keep the frame pointer in all functions.
 	Debug              bool // Whether to emit debug information in the LLVM module.
 }
 
@@ -379,6 +381,11 @@ func (c *compilerContext) makeLLVMType(goType types.Type) llvm.Type {
 			// LLVM. This is because it is otherwise impossible to create
 			// self-referencing types such as linked lists.
 			llvmName := typ.Obj().Pkg().Path() + "." + typ.Obj().Name()
+			if typ.TypeArgs().Len() != 0 {
+				// Instantiated generic type, such as List[int]. Include the
+				// type arguments to keep different instantiations apart.
+				llvmName += typeArgsString(typ.TypeArgs())
+			}
 			llvmType := c.ctx.StructCreateNamed(llvmName)
 			c.llvmTypes[goType] = llvmType // avoid infinite recursion
 			underlying := c.getLLVMType(st)
@@ -411,11 +418,25 @@ func (c *compilerContext) makeLLVMType(goType types.Type) llvm.Type {
 			members[i] = c.getLLVMType(typ.At(i).Type())
 		}
 		return c.ctx.StructType(members, false)
+	case *types.TypeParam:
+		// Generic functions are instantiated (monomorphized) before they're
+		// compiled, so type parameters should never reach this point.
+		panic("type parameter in non-instantiated code: " + typ.String())
 	default:
 		panic("unknown type: " + goType.String())
 	}
 }
 
+// typeArgsString returns the type arguments of an instantiated type in a form
+// like "[int,string]", for use in symbol names.
+func typeArgsString(args *types.TypeList) string {
+	names := make([]string, args.Len())
+	for i := 0; i < args.Len(); i++ {
+		names[i] = args.At(i).String()
+	}
+	return "[" + strings.Join(names, ",") + "]"
+}
+
 // Is this a pointer type of some sort? Can be unsafe.Pointer or any *T pointer.
 func isPointer(typ types.Type) bool {
 	if _, ok := typ.(*types.Pointer); ok {
@@ -655,8 +676,10 @@ func (b *builder) setDebugLocation(pos token.Pos) {
 
 // getLocalVariable returns a debug info entry for a local variable, which may
 // either be a parameter or a regular variable. It will create a new metadata
-// entry if there isn't one for the variable yet.
-func (b *builder) getLocalVariable(variable *types.Var) llvm.Metadata {
+// entry if there isn't one for the variable yet. The type is passed in
+// separately because in instantiated generic functions, the variable still
+// refers to the type parameter instead of the concrete type.
+func (b *builder) getLocalVariable(variable *types.Var, typ types.Type) llvm.Metadata {
 	if dilocal, ok := b.dilocals[variable]; ok {
 		// DILocalVariable was already created, return it directly.
 		return dilocal
@@ -672,7 +695,7 @@ func (b *builder) getLocalVariable(variable *types.Var) llvm.Metadata {
 				Name:           param.Name(),
 				File:           b.getDIFile(pos.Filename),
 				Line:           pos.Line,
-				Type:           b.getDIType(variable.Type()),
+				Type:           b.getDIType(typ),
 				AlwaysPreserve: true,
 				ArgNo:          i + 1,
 			})
@@ -686,7 +709,7 @@ func (b *builder) getLocalVariable(variable *types.Var) llvm.Metadata {
 		Name:           variable.Name(),
 		File:           b.getDIFile(pos.Filename),
 		Line:           pos.Line,
-		Type:           b.getDIType(variable.Type()),
+		Type:           b.getDIType(typ),
 		AlwaysPreserve: true,
 	})
 	b.dilocals[variable] = dilocal
@@ -770,6 +793,11 @@ func (c *compilerContext) createPackage(irbuilder llvm.Builder, pkg *ssa.Package
 		member := pkg.Members[name]
 		switch member := member.(type) {
 		case *ssa.Function:
+			if member.Signature.TypeParams().Len() != 0 {
+				// Generic functions are not compiled directly. Instead, each
+				// instantiation is created on demand by getFunction.
+				continue
+			}
 			// Create the function definition.
 			b := newBuilder(c, irbuilder, member)
 			if member.Blocks == nil {
@@ -781,6 +809,11 @@ func (c *compilerContext) createPackage(irbuilder llvm.Builder, pkg *ssa.Package
 				// Interfaces don't have concrete methods.
 				continue
 			}
+			if named, ok := member.Type().(*types.Named); ok && named.TypeParams().Len() != 0 {
+				// Methods on generic types are only created for instantiated
+				// types (such as List[int]), which happens in getFunction.
+				continue
+			}
 
 			// Named type. We should make sure all methods are created.
 			// This includes both functions with pointer receivers and those
@@ -949,7 +982,7 @@ func (b *builder) createFunction() {
 
 		// Add debug information to this parameter (if available)
 		if b.Debug && b.fn.Syntax() != nil {
-			dbgParam := b.getLocalVariable(param.Object().(*types.Var))
+			dbgParam := b.getLocalVariable(param.Object().(*types.Var), param.Type())
 			loc := b.GetCurrentDebugLocation()
 			if len(fields) == 1 {
 				expr := b.dibuilder.CreateExpression(nil)
@@ -1020,7 +1053,7 @@ func (b *builder) createFunction() {
 					// for example.
 					continue
 				}
-				dbgVar := b.getLocalVariable(variable)
+				dbgVar := b.getLocalVariable(variable, instr.X.Type())
 				pos := b.program.Fset.Position(instr.Pos())
 				b.dibuilder.InsertValueAtEnd(b.getValue(instr.X), dbgVar, b.dibuilder.CreateExpression(nil), llvm.DebugLoc{
 					Line:  uint(pos.Line),
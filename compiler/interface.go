@@ -57,6 +57,7 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		var methodSet llvm.Value
 		var ptrTo llvm.Value
 		var typeAssert llvm.Value
+		var reflectMethods llvm.Value
 		switch typ := typ.(type) {
 		case *types.Named:
 			references = c.getTypeCode(typ.Underlying())
@@ -69,6 +70,21 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		case *types.Array:
 			references = c.getTypeCode(typ.Elem())
 			length = typ.Len()
+		case *types.Map:
+			// Maps have both a key and an element type, so store a pointer to
+			// a {key, elem} pair of typecodes.
+			mapGlobal := c.makeMapTypeFields(typ)
+			references = llvm.ConstBitCast(mapGlobal, global.Type())
+		case *types.Signature:
+			// Functions have a variable number of parameters and results, so
+			// store them all in a separate global. The length field stores
+			// the number of parameters and whether the function is variadic.
+			funcGlobal := c.makeFuncTypeFields(typ)
+			references = llvm.ConstBitCast(funcGlobal, global.Type())
+			length = int64(typ.Params().Len()) << 1
+			if typ.Variadic() {
+				length |= 1
+			}
 		case *types.Struct:
 			// Take a pointer to the typecodeID of the first field (if it exists).
 			structGlobal := c.makeStructTypeFields(typ)
@@ -86,6 +102,9 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		if _, ok := typ.Underlying().(*types.Pointer); !ok {
 			ptrTo = c.getTypeCode(types.NewPointer(typ))
 		}
+		if c.ReflectMethods {
+			reflectMethods = c.getReflectMethods(typ)
+		}
 		globalValue := llvm.ConstNull(global.Type().ElementType())
 		if !references.IsNil() {
 			globalValue = llvm.ConstInsertValue(globalValue, references, []uint32{0})
@@ -103,6 +122,9 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		if !typeAssert.IsNil() {
 			globalValue = llvm.ConstInsertValue(globalValue, typeAssert, []uint32{4})
 		}
+		if !reflectMethods.IsNil() {
+			globalValue = llvm.ConstInsertValue(globalValue, reflectMethods, []uint32{5})
+		}
 		global.SetInitializer(globalValue)
 		global.SetLinkage(llvm.LinkOnceODRLinkage)
 		global.SetGlobalConstant(true)
@@ -110,6 +132,38 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 	return global
 }
 
+// makeMapTypeFields creates a new global that stores the key and element type
+// of a map type, in that order.
+func (c *compilerContext) makeMapTypeFields(typ *types.Map) llvm.Value {
+	mapGlobalValue := llvm.ConstArray(llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0), []llvm.Value{
+		c.getTypeCode(typ.Key()),
+		c.getTypeCode(typ.Elem()),
+	})
+	mapGlobal := llvm.AddGlobal(c.mod, mapGlobalValue.Type(), "reflect/types.mapFields")
+	mapGlobal.SetInitializer(mapGlobalValue)
+	mapGlobal.SetUnnamedAddr(true)
+	mapGlobal.SetLinkage(llvm.PrivateLinkage)
+	return mapGlobal
+}
+
+// makeFuncTypeFields creates a new global that stores the parameter types
+// followed by the result types of a function type.
+func (c *compilerContext) makeFuncTypeFields(typ *types.Signature) llvm.Value {
+	var fields []llvm.Value
+	for i := 0; i < typ.Params().Len(); i++ {
+		fields = append(fields, c.getTypeCode(typ.Params().At(i).Type()))
+	}
+	for i := 0; i < typ.Results().Len(); i++ {
+		fields = append(fields, c.getTypeCode(typ.Results().At(i).Type()))
+	}
+	funcGlobalValue := llvm.ConstArray(llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0), fields)
+	funcGlobal := llvm.AddGlobal(c.mod, funcGlobalValue.Type(), "reflect/types.funcFields")
+	funcGlobal.SetInitializer(funcGlobalValue)
+	funcGlobal.SetUnnamedAddr(true)
+	funcGlobal.SetLinkage(llvm.PrivateLinkage)
+	return funcGlobal
+}
+
 // makeStructTypeFields creates a new global that stores all type information
 // related to this struct type, and returns the resulting global. This global is
 // actually an array of all the fields in the structs.
@@ -227,6 +281,9 @@ func getTypeCodeName(t types.Type) string {
 		for i := 0; i < t.Params().Len(); i++ {
 			params[i] = getTypeCodeName(t.Params().At(i).Type())
 		}
+		if t.Variadic() {
+			params[len(params)-1] = "..." + params[len(params)-1]
+		}
 		results := make([]string, t.Results().Len())
 		for i := 0; i < t.Results().Len(); i++ {
 			results[i] = getTypeCodeName(t.Results().At(i).Type())
@@ -247,6 +304,10 @@ func getTypeCodeName(t types.Type) string {
 			}
 		}
 		return "struct:" + "{" + strings.Join(elems, ",") + "}"
+	case *types.TypeParam:
+		// Only instantiated generic code is compiled, so a type parameter
+		// can't be stored in an interface.
+		panic("type parameter in non-instantiated code: " + t.String())
 	default:
 		panic("unknown type: " + t.String())
 	}
@@ -295,6 +356,78 @@ func (c *compilerContext) getTypeMethodSet(typ types.Type) llvm.Value {
 	return llvm.ConstGEP(global, []llvm.Value{zero, zero})
 }
 
+// getReflectMethods returns a pointer (of type i8*) to a global with all
+// exported methods of the given type, for use by the reflect package. The
+// global is an array of {name, signature, wrapper} structs in the order of the
+// method set, where the signature is the method type without receiver and the
+// wrapper is created by getReflectCallWrapper (zero for interface types). The
+// reflect lowering pass converts these globals into a sidetable.
+// A nil value is returned when the type has no exported methods.
+func (c *compilerContext) getReflectMethods(typ types.Type) llvm.Value {
+	globalName := "reflect/types.methods:" + getTypeCodeName(typ)
+	global := c.mod.NamedGlobal(globalName)
+	if !global.IsNil() {
+		return llvm.ConstBitCast(global, c.i8ptrType)
+	}
+
+	methodType := c.ctx.StructType([]llvm.Type{
+		c.i8ptrType,
+		llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0),
+		c.uintptrType,
+	}, false)
+	var methods []llvm.Value
+	addMethod := func(method *types.Func, wrapper llvm.Value) {
+		sig := method.Type().(*types.Signature)
+		name := c.makeGlobalArray([]byte(method.Name()), "reflect/types.methodName", c.ctx.Int8Type())
+		name.SetLinkage(llvm.PrivateLinkage)
+		name.SetUnnamedAddr(true)
+		name = llvm.ConstGEP(name, []llvm.Value{
+			llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+			llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+		})
+		methods = append(methods, llvm.ConstStruct([]llvm.Value{
+			name,
+			c.getTypeCode(types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())),
+			wrapper,
+		}, false))
+	}
+	if itf, ok := typ.Underlying().(*types.Interface); ok {
+		// Interface methods can't be called directly, only through the
+		// dynamic type of a value.
+		for i := 0; i < itf.NumMethods(); i++ {
+			if method := itf.Method(i); method.Exported() {
+				addMethod(method, llvm.ConstNull(c.uintptrType))
+			}
+		}
+	} else {
+		ms := c.program.MethodSets.MethodSet(typ)
+		for i := 0; i < ms.Len(); i++ {
+			method := ms.At(i).Obj().(*types.Func)
+			if !method.Exported() {
+				continue
+			}
+			fn := c.program.MethodValue(ms.At(i))
+			llvmFn := c.getFunction(fn)
+			if llvmFn.IsNil() {
+				// compiler error, so panic
+				panic("cannot find function: " + c.getFunctionInfo(fn).linkName)
+			}
+			wrapper := c.getReflectCallWrapper(fn, llvmFn)
+			addMethod(method, llvm.ConstPtrToInt(wrapper, c.uintptrType))
+		}
+	}
+	if len(methods) == 0 {
+		return llvm.Value{}
+	}
+
+	value := llvm.ConstArray(methodType, methods)
+	global = llvm.AddGlobal(c.mod, value.Type(), globalName)
+	global.SetInitializer(value)
+	global.SetGlobalConstant(true)
+	global.SetLinkage(llvm.LinkOnceODRLinkage)
+	return llvm.ConstBitCast(global, c.i8ptrType)
+}
+
 // getInterfaceMethodSet returns a global variable with the method set of the
 // given named interface type. This method set is used by the interface lowering
 // pass.
@@ -565,6 +698,91 @@ func (c *compilerContext) getInterfaceInvokeWrapper(fn *ssa.Function, llvmFn llv
 	return wrapper
 }
 
+// getReflectCallWrapper returns a wrapper for the given method so it can be
+// called from the reflect package. The wrapper has the following signature:
+//
+//     func(receiver, params, results unsafe.Pointer)
+//
+// The receiver is passed as it is stored in an interface. The parameters are
+// loaded from params and the results are stored in results, both laid out as
+// they would be in a struct.
+func (c *compilerContext) getReflectCallWrapper(fn *ssa.Function, llvmFn llvm.Value) llvm.Value {
+	wrapperName := llvmFn.Name() + "$reflectcall"
+	wrapper := c.mod.NamedFunction(wrapperName)
+	if !wrapper.IsNil() {
+		// Wrapper already created. Return it directly.
+		return wrapper
+	}
+
+	// Call the method through the interface invoke wrapper, which already
+	// takes care of unpacking the receiver.
+	invokeFn := c.getInterfaceInvokeWrapper(fn, llvmFn)
+
+	// create wrapper function
+	wrapFnType := llvm.FunctionType(c.ctx.VoidType(), []llvm.Type{c.i8ptrType, c.i8ptrType, c.i8ptrType, c.i8ptrType}, false)
+	wrapper = llvm.AddFunction(c.mod, wrapperName, wrapFnType)
+	c.addStandardAttributes(wrapper)
+
+	wrapper.SetLinkage(llvm.LinkOnceODRLinkage)
+	wrapper.SetUnnamedAddr(true)
+
+	// Create a new builder just to create this wrapper.
+	b := builder{
+		compilerContext: c,
+		Builder:         c.ctx.NewBuilder(),
+	}
+	defer b.Builder.Dispose()
+
+	// add debug info if needed
+	if c.Debug {
+		pos := c.program.Fset.Position(fn.Pos())
+		difunc := c.attachDebugInfoRaw(fn, wrapper, "$reflectcall", pos.Filename, pos.Line)
+		b.SetCurrentDebugLocation(uint(pos.Line), uint(pos.Column), difunc, llvm.Metadata{})
+	}
+
+	// set up IR builder
+	block := b.ctx.AddBasicBlock(wrapper, "entry")
+	b.SetInsertPointAtEnd(block)
+
+	// The receiver may need a bitcast when the method has a pointer receiver
+	// and no invoke wrapper was necessary.
+	invokeFnType := invokeFn.Type().ElementType()
+	receiver := wrapper.Param(0)
+	if receiver.Type() != invokeFnType.ParamTypes()[0] {
+		receiver = b.CreateBitCast(receiver, invokeFnType.ParamTypes()[0], "receiver")
+	}
+	params := []llvm.Value{receiver}
+
+	// Load all parameters from the params buffer.
+	var paramTypes []llvm.Type
+	for i := 0; i < fn.Signature.Params().Len(); i++ {
+		paramTypes = append(paramTypes, c.getLLVMType(fn.Signature.Params().At(i).Type()))
+	}
+	paramsType := c.ctx.StructType(paramTypes, false)
+	paramsPtr := b.CreateBitCast(wrapper.Param(1), llvm.PointerType(paramsType, 0), "params")
+	for i := range paramTypes {
+		gep := b.CreateInBoundsGEP(paramsPtr, []llvm.Value{
+			llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+			llvm.ConstInt(c.ctx.Int32Type(), uint64(i), false),
+		}, "")
+		param := b.CreateLoad(gep, "")
+		params = append(params, b.expandFormalParam(param)...)
+	}
+	params = append(params, llvm.Undef(c.i8ptrType)) // context parameter
+
+	// Call the method and store the results, if there are any.
+	if invokeFnType.ReturnType().TypeKind() == llvm.VoidTypeKind {
+		b.CreateCall(invokeFn, params, "")
+	} else {
+		ret := b.CreateCall(invokeFn, params, "ret")
+		resultsPtr := b.CreateBitCast(wrapper.Param(2), llvm.PointerType(ret.Type(), 0), "results")
+		b.CreateStore(ret, resultsPtr)
+	}
+	b.CreateRetVoid()
+
+	return wrapper
+}
+
 // methodSignature creates a readable version of a method signature (including
 // the function name, excluding the receiver name). This string is used
 // internally to match interfaces and to call the correct method on an
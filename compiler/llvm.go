@@ -148,6 +148,7 @@ func (c *compilerContext) createObjectLayout(t llvm.Type, pos token.Pos) llvm.Va
 	// Create the global initializer.
 	bitmapBytes := make([]byte, int(objectSizeWords+7)/8)
 	bitmap.FillBytes(bitmapBytes)
+	reverseBytes(bitmapBytes) // big-endian to little-endian
 	var bitmapByteValues []llvm.Value
 	for _, b := range bitmapBytes {
 		bitmapByteValues = append(bitmapByteValues, llvm.ConstInt(c.ctx.Int8Type(), uint64(b), false))
@@ -253,3 +254,12 @@ func (c *compilerContext) getPointerBitmap(typ llvm.Type, pos token.Pos) *big.In
 		panic("unknown LLVM type")
 	}
 }
+
+// reverseBytes reverses the byte slice in place, for example to convert
+// between big-endian and little-endian byte order.
+func reverseBytes(buf []byte) {
+	for i := 0; i < len(buf)/2; i++ {
+		j := len(buf) - i - 1
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+}
@@ -30,6 +30,7 @@ type functionInfo struct {
 	nobounds   bool       // go:nobounds
 	variadic   bool       // go:variadic (CGo only)
 	inline     inlineType // go:inline
+	stackSize  uint64     // go:stacksize - maximum stack usage including callees, or 0
 }
 
 type inlineType int
@@ -110,6 +111,13 @@ func (c *compilerContext) getFunction(fn *ssa.Function) llvm.Value {
 	}
 	c.addStandardDeclaredAttributes(llvmFn)
 
+	// The maximum stack size of this function (including all the functions it
+	// calls) has been annotated. It is used in the stack size analysis, which
+	// can't determine a bound for recursive functions.
+	if info.stackSize != 0 {
+		llvmFn.AddFunctionAttr(c.ctx.CreateStringAttribute("tinygo-stacksize", strconv.FormatUint(info.stackSize, 10)))
+	}
+
 	dereferenceableOrNullKind := llvm.AttributeKindID("dereferenceable_or_null")
 	for i, info := range paramInfos {
 		if info.flags&paramIsDeferenceableOrNull == 0 {
@@ -223,6 +231,9 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 		return
 	}
 	if decl, ok := f.Syntax().(*ast.FuncDecl); ok && decl.Doc != nil {
+		// Use the package of the declared object instead of f.Pkg, because
+		// instantiated generic functions don't belong to any *ssa.Package.
+		pkg := f.Object().Pkg()
 
 		// Our importName for a wasm module (if we are compiling to wasm), or llvm link name
 		var importName string
@@ -247,7 +258,7 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 				importName = parts[1]
 				info.exported = true
 			case "//go:interrupt":
-				if hasUnsafeImport(f.Pkg.Pkg) {
+				if hasUnsafeImport(pkg) {
 					info.interrupt = true
 				}
 			case "//go:wasm-module":
@@ -268,19 +279,31 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 				// This is a slightly looser requirement than what gc uses: gc
 				// requires the file to import "unsafe", not the package as a
 				// whole.
-				if hasUnsafeImport(f.Pkg.Pkg) {
+				if hasUnsafeImport(pkg) {
 					info.linkName = parts[2]
 				}
 			case "//go:section":
-				if len(parts) == 2 && hasUnsafeImport(f.Pkg.Pkg) {
+				if len(parts) == 2 && hasUnsafeImport(pkg) {
 					info.section = parts[1]
 				}
+			case "//go:stacksize":
+				// Maximum stack usage of this function, including all the
+				// functions it calls. Used to bound the stack size of
+				// recursive functions.
+				if len(parts) != 2 {
+					continue
+				}
+				size, err := strconv.ParseUint(parts[1], 10, 32)
+				if err != nil || size == 0 {
+					continue
+				}
+				info.stackSize = size
 			case "//go:nobounds":
 				// Skip bounds checking in this function. Useful for some
 				// runtime functions.
 				// This is somewhat dangerous and thus only imported in packages
 				// that import unsafe.
-				if hasUnsafeImport(f.Pkg.Pkg) {
+				if hasUnsafeImport(pkg) {
 					info.nobounds = true
 				}
 			case "//go:variadic":
@@ -362,6 +385,11 @@ func (c *compilerContext) addStandardDefinedAttributes(llvmFn llvm.Value) {
 		// Required by the ABI.
 		llvmFn.AddFunctionAttr(c.ctx.CreateEnumAttribute(llvm.AttributeKindID("uwtable"), 0))
 	}
+	if c.FramePointers {
+		// Keep the frame pointer so that the runtime can walk the stack, for
+		// runtime.Callers and for backtraces on panic.
+		llvmFn.AddFunctionAttr(c.ctx.CreateStringAttribute("frame-pointer", "all"))
+	}
 }
 
 // addStandardAttribute adds all attributes added to defined functions.
@@ -0,0 +1,32 @@
+package main
+
+// Test generic functions and types, introduced in Go 1.18.
+
+type Number interface {
+	int | float32
+}
+
+type Point[T Number] struct {
+	X, Y T
+}
+
+func Add[T Number](x, y T) T {
+	return x + y
+}
+
+func addInt(x, y int) int {
+	return Add(x, y)
+}
+
+func addIntConst(x int) int {
+	// This should reuse the Add[int] instantiation from above.
+	return Add[int](x, 3)
+}
+
+func addFloat(x, y float32) float32 {
+	return Add(x, y)
+}
+
+// Each instantiation of Point must get its own LLVM struct type.
+func usePoints(a *Point[int], b *Point[float32]) {
+}
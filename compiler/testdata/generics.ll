@@ -0,0 +1,60 @@
+; ModuleID = 'generics.go'
+source_filename = "generics.go"
+target datalayout = "e-m:e-p:32:32-i64:64-n32:64-S128-ni:1:10:20"
+target triple = "wasm32-unknown-wasi"
+
+%"main.Point[int]" = type { i32, i32 }
+%"main.Point[float32]" = type { float, float }
+
+declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*)
+
+declare void @runtime.trackPointer(i8* nocapture readonly, i8*)
+
+; Function Attrs: nounwind
+define hidden void @main.init(i8* %context) unnamed_addr #0 {
+entry:
+  ret void
+}
+
+; Function Attrs: nounwind
+define hidden i32 @main.addInt(i32 %x, i32 %y, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = call i32 @"main.Add[int]"(i32 %x, i32 %y, i8* undef)
+  ret i32 %0
+}
+
+; Function Attrs: nounwind
+define linkonce_odr hidden i32 @"main.Add[int]"(i32 %x, i32 %y, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = add i32 %x, %y
+  ret i32 %0
+}
+
+; Function Attrs: nounwind
+define hidden i32 @main.addIntConst(i32 %x, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = call i32 @"main.Add[int]"(i32 %x, i32 3, i8* undef)
+  ret i32 %0
+}
+
+; Function Attrs: nounwind
+define hidden float @main.addFloat(float %x, float %y, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = call float @"main.Add[float32]"(float %x, float %y, i8* undef)
+  ret float %0
+}
+
+; Function Attrs: nounwind
+define linkonce_odr hidden float @"main.Add[float32]"(float %x, float %y, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = fadd float %x, %y
+  ret float %0
+}
+
+; Function Attrs: nounwind
+define hidden void @main.usePoints(%"main.Point[int]"* dereferenceable_or_null(8) %a, %"main.Point[float32]"* dereferenceable_or_null(8) %b, i8* %context) unnamed_addr #0 {
+entry:
+  ret void
+}
+
+attributes #0 = { nounwind }
@@ -40,6 +40,12 @@ func inlineFunc() {
 func noinlineFunc() {
 }
 
+// The maximum stack size of this function is annotated, for use in the stack
+// size analysis.
+//go:stacksize 256
+func stackSizeFunc() {
+}
+
 // This function should have the specified section.
 //go:section .special_function_section
 func functionInSection() {
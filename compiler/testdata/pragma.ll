@@ -46,6 +46,12 @@ entry:
   ret void
 }
 
+; Function Attrs: nounwind
+define hidden void @main.stackSizeFunc(i8* %context) unnamed_addr #4 {
+entry:
+  ret void
+}
+
 ; Function Attrs: nounwind
 define hidden void @main.functionInSection(i8* %context) unnamed_addr #0 section ".special_function_section" {
 entry:
@@ -53,7 +59,7 @@ entry:
 }
 
 ; Function Attrs: nounwind
-define void @exportedFunctionInSection() #4 section ".special_function_section" {
+define void @exportedFunctionInSection() #5 section ".special_function_section" {
 entry:
   ret void
 }
@@ -64,4 +70,5 @@ attributes #0 = { nounwind }
 attributes #1 = { nounwind "wasm-export-name"="extern_func" }
 attributes #2 = { inlinehint nounwind }
 attributes #3 = { noinline nounwind }
-attributes #4 = { nounwind "wasm-export-name"="exportedFunctionInSection" }
+attributes #4 = { nounwind "tinygo-stacksize"="256" }
+attributes #5 = { nounwind "wasm-export-name"="exportedFunctionInSection" }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/goenv"
+)
+
+// Test that `info goroutines` from src/runtime/runtime-gdb.py lists all
+// goroutines, including the ones that are blocked on a channel or mutex.
+func TestGDBGoroutines(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("goroutine stack switching is only tested on linux/amd64")
+	}
+	gdb, err := exec.LookPath("gdb")
+	if err != nil {
+		t.Skip("gdb not installed")
+	}
+
+	opts := optionsFromTarget("", sema)
+	opts.Scheduler = "tasks"
+	opts.Tags = "goroutinedebug"
+	binary := filepath.Join(t.TempDir(), "test")
+	err = Build("./"+TESTDATA+"/gdb-goroutines.go", binary, &opts)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fatal("failed to build")
+	}
+
+	script := filepath.Join(goenv.Get("TINYGOROOT"), "src", "runtime", "runtime-gdb.py")
+	cmd := exec.Command(gdb, "-batch", "-nx",
+		"-ex", "source "+script,
+		"-ex", "break main.breakpoint",
+		"-ex", "run",
+		"-ex", "info goroutines",
+		binary)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	if err != nil {
+		t.Log(output.String())
+		t.Fatal("failed to run gdb:", err)
+	}
+
+	for _, want := range []string{
+		"running  main.breakpoint",
+		"sleeping ",
+		"blocked  [chan receive 0x",
+		"blocked  [sync.Mutex.Lock 0x",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output of info goroutines does not contain %q", want)
+		}
+	}
+	if t.Failed() {
+		t.Log(output.String())
+	}
+}
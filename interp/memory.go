@@ -1246,7 +1246,9 @@ func (r *runner) readObjectLayout(layoutValue value) (uint64, *big.Int) {
 		if uint64(byte(v)) != v {
 			panic("found pointer in data array?") // sanity check
 		}
-		rawBytes[i] = byte(v)
+		// The bitmap is stored in little-endian form, but big.Int expects a
+		// big-endian byte slice.
+		rawBytes[len(rawBytes)-i-1] = byte(v)
 	}
 	bitmap := new(big.Int).SetBytes(rawBytes)
 	return objectSizeWords, bitmap
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/blakesmith/ar"
+)
+
+// Test building testdata/library with -buildmode=c-archive. Only the exported
+// functions and the tinygo_* runtime symbols may be visible to the C program.
+// On Linux, the library is also linked into a C program and run.
+func TestLibrary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Host", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS != "linux" {
+			t.Skip("libraries are only supported on Linux and baremetal targets")
+		}
+		tmpdir := t.TempDir()
+		archive := filepath.Join(tmpdir, "libtest.a")
+		checkLibrary(t, "", archive)
+		if t.Failed() {
+			return
+		}
+
+		cc, err := exec.LookPath("cc")
+		if err != nil {
+			t.Skip("no C compiler installed")
+		}
+		binary := filepath.Join(tmpdir, "test")
+		cmd := exec.Command(cc, "-o", binary, "-I"+tmpdir, filepath.Join(TESTDATA, "library", "main.c"), archive)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("could not link C program: %v\n%s", err, output)
+		}
+		output, err := exec.Command(binary).CombinedOutput()
+		if err != nil {
+			t.Fatalf("could not run C program: %v\n%s", err, output)
+		}
+		expected := "add: 5\nnext: 11\nnext: 12\nallocate: 4950\n"
+		if string(output) != expected {
+			t.Errorf("unexpected output:\nexpected:\n%s\nactual:\n%s", expected, output)
+		}
+	})
+
+	t.Run("EmulatedCortexM3", func(t *testing.T) {
+		t.Parallel()
+		checkLibrary(t, "cortex-m-qemu", filepath.Join(t.TempDir(), "libtest.a"))
+	})
+}
+
+// checkLibrary builds testdata/library as a static library for the given
+// target and checks the symbols defined in it, and the generated header.
+func checkLibrary(t *testing.T, target, archive string) {
+	opts := optionsFromTarget(target, sema)
+	opts.BuildMode = "c-archive"
+	err := Build("./"+TESTDATA+"/library", archive, &opts)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fatal("failed to build library")
+	}
+
+	header, err := os.ReadFile(strings.TrimSuffix(archive, ".a") + ".h")
+	if err != nil {
+		t.Fatal("could not read header:", err)
+	}
+	for _, decl := range []string{
+		"void tinygo_init(void);",
+		"int32_t add(int32_t a, int32_t b);",
+		"int32_t next(void);",
+		"int32_t allocate(int32_t n);",
+	} {
+		if !bytes.Contains(header, []byte(decl)) {
+			t.Errorf("header does not contain %q", decl)
+		}
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var exported []string
+	reader := ar.NewReader(f)
+	for {
+		hdr, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("could not read archive:", err)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal("could not read archive:", err)
+		}
+		obj, err := elf.NewFile(bytes.NewReader(data))
+		if err != nil {
+			// The symbol table.
+			continue
+		}
+		symbols, err := obj.Symbols()
+		if err != nil {
+			t.Fatalf("could not read symbols of %s: %v", hdr.Name, err)
+		}
+		for _, symbol := range symbols {
+			if elf.ST_BIND(symbol.Info) == elf.STB_LOCAL || symbol.Section == elf.SHN_UNDEF {
+				continue
+			}
+			if !strings.HasPrefix(symbol.Name, "tinygo_") {
+				exported = append(exported, symbol.Name)
+			}
+		}
+	}
+	sort.Strings(exported)
+	if strings.Join(exported, " ") != "add allocate next" {
+		t.Errorf("unexpected symbols defined in library: %v", exported)
+	}
+}
@@ -141,6 +141,7 @@ func Load(config *compileopts.Config, inputPkgs []string, clangHeaders string, t
 			FileHashes: make(map[string][]byte),
 			info: types.Info{
 				Types:      make(map[ast.Expr]types.TypeAndValue),
+				Instances:  make(map[*ast.Ident]types.Instance),
 				Defs:       make(map[*ast.Ident]types.Object),
 				Uses:       make(map[*ast.Ident]types.Object),
 				Implicits:  make(map[ast.Node]types.Object),
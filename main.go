@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -163,6 +164,15 @@ func Build(pkgName, outpath string, options *compileopts.Options) error {
 			}
 		}
 
+		if result.Header != "" {
+			// Put the C header of a library next to it: libfoo.a gets a
+			// libfoo.h header.
+			err := copyFile(result.Header, strings.TrimSuffix(outpath, filepath.Ext(outpath))+".h")
+			if err != nil {
+				return err
+			}
+		}
+
 		if err := os.Rename(result.Binary, outpath); err != nil {
 			// Moving failed. Do a file copy.
 			inf, err := os.Open(result.Binary)
@@ -376,7 +386,11 @@ func Flash(pkgName, port string, options *compileopts.Options) error {
 		return errors.New("unknown flash method: " + flashMethod)
 	}
 
-	return builder.Build(pkgName, fileExt, config, func(result builder.BuildResult) error {
+	// The port may be modified while flashing, keep the original value for the
+	// serial monitor.
+	monitorPort := port
+
+	flash := func(result builder.BuildResult) error {
 		// do we need port reset to put MCU into bootloader mode?
 		if config.Target.PortReset == "true" && flashMethod != "openocd" {
 			port, err := getDefaultPort(port, config.Target.SerialPort)
@@ -484,6 +498,14 @@ func Flash(pkgName, port string, options *compileopts.Options) error {
 		default:
 			return fmt.Errorf("unknown flash method: %s", flashMethod)
 		}
+	}
+
+	return builder.Build(pkgName, fileExt, config, func(result builder.BuildResult) error {
+		err := flash(result)
+		if err != nil || !options.Monitor {
+			return err
+		}
+		return Monitor(result.Executable, monitorPort, options)
 	})
 }
 
@@ -663,6 +685,8 @@ func Debug(debugger, pkgName string, ocdOutput bool, options *compileopts.Option
 		params := []string{result.Binary}
 		switch debugger {
 		case "gdb":
+			// Load the goroutine inspection commands.
+			params = append(params, "-ex", "source "+filepath.Join(goenv.Get("TINYGOROOT"), "src", "runtime", "runtime-gdb.py"))
 			if port != "" {
 				params = append(params, "-ex", "target extended-remote "+port)
 			}
@@ -1024,6 +1048,90 @@ func getBMPPorts() (gdbPort, uartPort string, err error) {
 	}
 }
 
+// Addr2Line prints the function and source location of each of the given
+// return addresses, as printed by the runtime in a backtrace on panic. If no
+// addresses are given, it reads the output of a program from r and decodes all
+// backtraces in it, leaving all other lines as-is.
+//
+// Baremetal programs print these backtraces by default on Cortex-M (unless
+// built with -tags=noframepointers) and on other baremetal targets only with
+// -tags=framepointers, see compileopts.Config.FramePointers.
+func Addr2Line(executable string, args []string, r io.Reader, w io.Writer) error {
+	if len(args) != 0 {
+		addresses, err := parseAddresses(args)
+		if err != nil {
+			return err
+		}
+		return printAddressLocations(w, executable, addresses)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		addresses, ok := parseBacktrace(line)
+		if !ok {
+			fmt.Fprintln(w, line)
+			continue
+		}
+		err := printAddressLocations(w, executable, addresses)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseBacktrace returns the addresses of a compact backtrace as printed by the
+// runtime on panic (backtrace: 0x2b9 0x1f3 0x151), if the line contains one.
+func parseBacktrace(line string) ([]uint64, bool) {
+	index := strings.Index(line, "backtrace:")
+	if index < 0 {
+		return nil, false
+	}
+	addresses, err := parseAddresses(strings.Fields(line[index+len("backtrace:"):]))
+	if err != nil || len(addresses) == 0 {
+		// Probably not a backtrace printed by the runtime.
+		return nil, false
+	}
+	return addresses, true
+}
+
+// parseAddresses parses a list of hexadecimal addresses, with or without 0x
+// prefix.
+func parseAddresses(args []string) ([]uint64, error) {
+	var addresses []uint64
+	for _, arg := range args {
+		address, err := strconv.ParseUint(strings.TrimPrefix(arg, "0x"), 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %#v: %w", arg, err)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// printAddressLocations prints a backtrace in the same format as the runtime
+// does when it is able to symbolize a backtrace by itself.
+func printAddressLocations(w io.Writer, executable string, addresses []uint64) error {
+	locations, err := builder.LookupReturnAddresses(executable, addresses)
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		if location.Function == "" {
+			fmt.Fprintf(w, "?()\n\t%#x\n", location.Address)
+			continue
+		}
+		fmt.Fprintf(w, "%s()\n", location.Function)
+		if location.File != "" {
+			fmt.Fprintf(w, "\t%s:%d\n", location.File, location.Line)
+		} else {
+			fmt.Fprintf(w, "\t%#x\n", location.Address)
+		}
+	}
+	return nil
+}
+
 func usage(command string) {
 	version := goenv.Version
 	if strings.HasSuffix(version, "-dev") && goenv.GitSha1 != "" {
@@ -1042,6 +1150,9 @@ func usage(command string) {
 		fmt.Fprintln(os.Stderr, "  flash:   compile and flash to the device")
 		fmt.Fprintln(os.Stderr, "  gdb:     run/flash and immediately enter GDB")
 		fmt.Fprintln(os.Stderr, "  lldb:    run/flash and immediately enter LLDB")
+		fmt.Fprintln(os.Stderr, "  monitor: open the serial port of the device")
+		fmt.Fprintln(os.Stderr, "  addr2line: decode a backtrace printed on panic")
+		fmt.Fprintln(os.Stderr, "  size-diff: compare the size of two builds")
 		fmt.Fprintln(os.Stderr, "  env:     list environment variables used during build")
 		fmt.Fprintln(os.Stderr, "  list:    run go list using the TinyGo root")
 		fmt.Fprintln(os.Stderr, "  clean:   empty cache directory ("+goenv.Get("GOCACHE")+")")
@@ -1197,9 +1308,11 @@ func main() {
 	command := os.Args[1]
 
 	opt := flag.String("opt", "z", "optimization level: 0, 1, 2, s, z")
-	gc := flag.String("gc", "", "garbage collector to use (none, leaking, conservative)")
+	buildMode := flag.String("buildmode", "", "build mode to use (default, c-archive, c-shared)")
+	gc := flag.String("gc", "", "garbage collector to use (none, leaking, conservative, precise)")
 	panicStrategy := flag.String("panic", "print", "panic strategy (print, trap)")
-	scheduler := flag.String("scheduler", "", "which scheduler to use (none, tasks, asyncify)")
+	scheduler := flag.String("scheduler", "", "which scheduler to use (none, tasks, asyncify, cores)")
+	preempt := flag.String("preempt", "", "preempt goroutines that run longer than this time slice, for example 10ms (Cortex-M only)")
 	serial := flag.String("serial", "", "which serial output to use (none, uart, usb)")
 	work := flag.Bool("work", false, "print the name of the temporary build directory and do not delete this directory on exit")
 	printIR := flag.Bool("printir", false, "print LLVM IR")
@@ -1207,15 +1320,18 @@ func main() {
 	verifyIR := flag.Bool("verifyir", false, "run extra verification steps on LLVM IR")
 	tags := flag.String("tags", "", "a space-separated list of extra build tags")
 	target := flag.String("target", "", "chip/board name or JSON target specification file")
-	printSize := flag.String("size", "", "print sizes (none, short, full)")
+	printSize := flag.String("size", "", "print sizes (none, short, full, symbols, json, csv)")
 	printStacks := flag.Bool("print-stacks", false, "print stack sizes of goroutines")
 	printAllocsString := flag.String("print-allocs", "", "regular expression of functions for which heap allocations should be printed")
+	reflectMethods := flag.Bool("reflect-methods", false, "keep exported methods of types for use with reflect (increases code size)")
 	printCommands := flag.Bool("x", false, "Print commands")
 	parallelism := flag.Int("p", runtime.GOMAXPROCS(0), "the number of build jobs that can run in parallel")
 	nodebug := flag.Bool("no-debug", false, "strip debug information")
 	ocdCommandsString := flag.String("ocd-commands", "", "OpenOCD commands, overriding target spec (can specify multiple separated by commas)")
 	ocdOutput := flag.Bool("ocd-output", false, "print OCD daemon output during debug")
 	port := flag.String("port", "", "flash port (can specify multiple candidates separated by commas)")
+	startMonitor := flag.Bool("monitor", false, "start the serial monitor after flashing")
+	baudRate := flag.Int("baudrate", 115200, "baud rate of the serial monitor")
 	programmer := flag.String("programmer", "", "which hardware programmer to use")
 	ldflags := flag.String("ldflags", "", "Go link tool compatible ldflags")
 	wasmAbi := flag.String("wasm-abi", "", "WebAssembly ABI conventions: js (no i64 params) or generic")
@@ -1230,6 +1346,10 @@ func main() {
 		flag.BoolVar(&flagDeps, "deps", false, "supply -deps flag to go list")
 		flag.BoolVar(&flagTest, "test", false, "supply -test flag to go list")
 	}
+	var maxGrowth int64
+	if command == "help" || command == "size-diff" {
+		flag.Int64Var(&maxGrowth, "max-growth", -1, "size-diff: exit with an error if flash usage grew by more than this number of bytes")
+	}
 	var outpath string
 	if command == "help" || command == "build" || command == "build-library" || command == "test" {
 		flag.StringVar(&outpath, "o", "", "output filename")
@@ -1286,9 +1406,11 @@ func main() {
 		GOARM:           goenv.Get("GOARM"),
 		Target:          *target,
 		Opt:             *opt,
+		BuildMode:       *buildMode,
 		GC:              *gc,
 		PanicStrategy:   *panicStrategy,
 		Scheduler:       *scheduler,
+		Preempt:         *preempt,
 		Serial:          *serial,
 		Work:            *work,
 		PrintIR:         *printIR,
@@ -1299,11 +1421,14 @@ func main() {
 		PrintSizes:      *printSize,
 		PrintStacks:     *printStacks,
 		PrintAllocs:     printAllocs,
+		ReflectMethods:  *reflectMethods,
 		Tags:            *tags,
 		GlobalValues:    globalVarValues,
 		WasmAbi:         *wasmAbi,
 		Programmer:      *programmer,
 		OpenOCDCommands: ocdCommands,
+		Monitor:         *startMonitor,
+		BaudRate:        *baudRate,
 		LLVMFeatures:    *llvmFeatures,
 		PrintJSON:       flagJSON,
 	}
@@ -1317,6 +1442,11 @@ func main() {
 		usage(command)
 		os.Exit(1)
 	}
+	if options.BuildMode != "" && options.BuildMode != "default" && command != "build" {
+		fmt.Fprintf(os.Stderr, "-buildmode=%s is only supported by the build command\n", options.BuildMode)
+		usage(command)
+		os.Exit(1)
+	}
 
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -1406,6 +1536,36 @@ func main() {
 			err := Debug(command, pkgName, *ocdOutput, options)
 			handleCompilerError(err)
 		}
+	case "monitor":
+		if flag.NArg() > 1 {
+			fmt.Fprintln(os.Stderr, "Only an executable can be specified, to decode backtraces.")
+			usage(command)
+			os.Exit(1)
+		}
+		err := Monitor(flag.Arg(0), *port, options)
+		handleCompilerError(err)
+	case "addr2line":
+		if flag.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "No executable specified.")
+			usage(command)
+			os.Exit(1)
+		}
+		err := Addr2Line(flag.Arg(0), flag.Args()[1:], os.Stdin, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+	case "size-diff":
+		if flag.NArg() != 2 {
+			fmt.Fprintln(os.Stderr, "Expected two executables or -size=json reports to compare.")
+			usage(command)
+			os.Exit(1)
+		}
+		err := builder.PrintSizeDiff(os.Stdout, flag.Arg(0), flag.Arg(1), maxGrowth)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
 	case "run":
 		if flag.NArg() != 1 {
 			fmt.Fprintln(os.Stderr, "No package specified.")
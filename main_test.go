@@ -59,6 +59,7 @@ func TestBuild(t *testing.T) {
 		"json.go",
 		"map.go",
 		"math.go",
+		"memstats.go",
 		"print.go",
 		"reflect.go",
 		"slice.go",
@@ -76,6 +77,9 @@ func TestBuild(t *testing.T) {
 	if minor >= 17 {
 		tests = append(tests, "go1.17.go")
 	}
+	if minor >= 18 {
+		tests = append(tests, "generics.go")
+	}
 
 	if *testTarget != "" {
 		// This makes it possible to run one specific test (instead of all),
@@ -121,6 +125,114 @@ func TestBuild(t *testing.T) {
 			}
 			runTestWithConfig("ldflags.go", t, opts, nil, nil)
 		})
+
+		// Test the precise GC, which uses the object layout emitted by the
+		// compiler to scan heap objects.
+		t.Run("gc=precise", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.GC = "precise"
+			runTestWithConfig("gc.go", t, opts, nil, nil)
+		})
+
+		// Goroutine stacks contain pointers but have no object layout, so
+		// they must be scanned conservatively by the precise GC.
+		t.Run("gc=precise-stack", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.GC = "precise"
+			opts.Scheduler = "tasks"
+			runTestWithConfig("gcstack.go", t, opts, nil, nil)
+		})
+
+		// Test runtime.Callers and friends, which need the PC table that is
+		// only available on the host.
+		t.Run("callers", func(t *testing.T) {
+			t.Parallel()
+			if runtime.GOOS != "linux" {
+				t.Skip("PC table is only supported on Linux")
+			}
+			opts := optionsFromTarget("", sema)
+			runTestWithConfig("callers.go", t, opts, nil, nil)
+		})
+
+		// Test the memory statistics of the leaking GC, which never frees
+		// memory.
+		t.Run("gc=leaking", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.GC = "leaking"
+			runTestWithConfig("memstats.go", t, opts, nil, nil)
+		})
+
+		// Test the net package, using sockets on Linux and the loopback
+		// network elsewhere.
+		t.Run("net", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			runTestWithConfig("net.go", t, opts, nil, nil)
+		})
+		t.Run("net-loopback", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.Tags = "netloopback"
+			runTestWithConfig("net.go", t, opts, nil, nil)
+		})
+
+		// Test CPU and heap profiling, which is only supported on Linux.
+		t.Run("pprof", func(t *testing.T) {
+			t.Parallel()
+			if runtime.GOOS != "linux" {
+				t.Skip("profiling is only supported on Linux")
+			}
+			opts := optionsFromTarget("", sema)
+			runTestWithConfig("pprof.go", t, opts, nil, nil)
+		})
+
+		// Test that goroutines blocked on a pipe don't block the scheduler.
+		// The netpoller is only implemented on Linux and WASI.
+		t.Run("netpoll", func(t *testing.T) {
+			t.Parallel()
+			if runtime.GOOS != "linux" {
+				t.Skip("netpoller is only supported on Linux")
+			}
+			opts := optionsFromTarget("", sema)
+			runTestWithConfig("netpoll.go", t, opts, nil, nil)
+		})
+
+		// Test starting processes with os/exec, which is only implemented on
+		// Linux.
+		t.Run("exec", func(t *testing.T) {
+			t.Parallel()
+			if runtime.GOOS != "linux" {
+				t.Skip("os/exec is only supported on Linux")
+			}
+			opts := optionsFromTarget("", sema)
+			runTestWithConfig("exec.go", t, opts, nil, nil)
+		})
+
+		// Test the goroutine lists printed with -tags=goroutinedebug. The test
+		// runs itself as a child process to test the deadlock report, so it
+		// needs os/exec which is only implemented on Linux.
+		t.Run("goroutinedebug", func(t *testing.T) {
+			t.Parallel()
+			if runtime.GOOS != "linux" {
+				t.Skip("os/exec is only supported on Linux")
+			}
+			opts := optionsFromTarget("", sema)
+			opts.Scheduler = "tasks"
+			opts.Tags = "goroutinedebug"
+			runTestWithConfig("goroutinedebug.go", t, opts, nil, nil)
+		})
+
+		// Test calling methods through reflection, which needs method
+		// information that is only kept with -reflect-methods.
+		t.Run("reflect-methods", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.ReflectMethods = true
+			runTestWithConfig("reflect-methods.go", t, opts, nil, nil)
+		})
 	})
 
 	if testing.Short() {
@@ -227,6 +339,28 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 			runTest("env.go", options, t, []string{"first", "second"}, []string{"ENV1=VALUE1", "ENV2=VALUE2"})
 		})
 	}
+	if options.Target == "cortex-m-qemu" {
+		t.Run("preempt.go", func(t *testing.T) {
+			t.Parallel()
+			options := compileopts.Options(options)
+			options.Preempt = "1ms"
+			runTest("preempt.go", options, t, nil, nil)
+		})
+		t.Run("gcstack.go-gc-precise", func(t *testing.T) {
+			t.Parallel()
+			options := compileopts.Options(options)
+			options.GC = "precise"
+			runTest("gcstack.go", options, t, nil, nil)
+		})
+		t.Run("gcstack.go-stackguard", func(t *testing.T) {
+			// Run the GC from a goroutine while its MPU stack guard is
+			// enabled. The GC must not read the guard region.
+			t.Parallel()
+			options := compileopts.Options(options)
+			options.Tags = "stackguard"
+			runTest("gcstack.go", options, t, nil, nil)
+		})
+	}
 	if options.Target == "wasi" || options.Target == "wasm" {
 		t.Run("alias.go-scheduler-none", func(t *testing.T) {
 			t.Parallel()
@@ -0,0 +1,211 @@
+package main
+
+// This file implements `tinygo monitor` and `tinygo flash -monitor`, a simple
+// serial console for the device.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/tinygo-org/tinygo/builder"
+	"github.com/tinygo-org/tinygo/compileopts"
+	"go.bug.st/serial"
+)
+
+// Monitor connects to the serial port of the device and prints everything it
+// sends, while sending everything typed on stdin to the device. The port is
+// detected the same way as for flashing (see getDefaultPort). When the port
+// disappears, for example because a USB-CDC device was reset, Monitor waits
+// until it is available again and reconnects.
+//
+// If executable is not empty, backtraces printed by the program on panic are
+// decoded against this executable (just like tinygo addr2line does).
+//
+// Monitor only returns on error or when interrupted with Ctrl-C.
+func Monitor(executable, port string, options *compileopts.Options) error {
+	config, err := builder.NewConfig(options)
+	if err != nil {
+		return err
+	}
+	baudRate := options.BaudRate
+	if baudRate <= 0 {
+		baudRate = 115200
+	}
+
+	m := &monitor{
+		open: func() (io.ReadWriteCloser, string, error) {
+			name, err := getDefaultPort(port, config.Target.SerialPort)
+			if err != nil {
+				return nil, "", err
+			}
+			p, err := serial.Open(name, &serial.Mode{BaudRate: baudRate})
+			if err != nil {
+				return nil, "", err
+			}
+			return p, name, nil
+		},
+		executable:    executable,
+		stdout:        os.Stdout,
+		status:        os.Stderr,
+		retryInterval: 200 * time.Millisecond,
+	}
+
+	// Stop on Ctrl-C, so that temporary files (such as the executable when
+	// used with flash -monitor) are removed.
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		<-sig
+		close(stop)
+	}()
+
+	go m.copyInput(os.Stdin)
+	return m.run(stop)
+}
+
+// monitor is the state of a running serial monitor. It is separate from
+// Monitor so that it can be tested without real serial ports.
+type monitor struct {
+	// open opens the serial port, and returns it together with its name.
+	open func() (io.ReadWriteCloser, string, error)
+
+	executable    string    // executable to decode backtraces, may be empty
+	stdout        io.Writer // output of the device
+	status        io.Writer // messages of the monitor itself
+	retryInterval time.Duration
+
+	lock sync.Mutex
+	port io.ReadWriteCloser // current port, or nil if not connected
+}
+
+// run connects to the serial port and copies its output to stdout, until stop
+// is closed.
+func (m *monitor) run(stop <-chan struct{}) error {
+	// Close the current port when stopping, which makes the blocking read
+	// return.
+	stopped := make(chan struct{})
+	go func() {
+		<-stop
+		m.lock.Lock()
+		close(stopped)
+		if m.port != nil {
+			m.port.Close()
+		}
+		m.lock.Unlock()
+	}()
+
+	lastError := ""
+	for {
+		port, name, err := m.open()
+		if err != nil {
+			// Only print the error when it changes, not on every retry.
+			if err.Error() != lastError {
+				lastError = err.Error()
+				fmt.Fprintf(m.status, "Waiting for serial port: %s\n", lastError)
+			}
+			select {
+			case <-stopped:
+				return nil
+			case <-time.After(m.retryInterval):
+			}
+			continue
+		}
+		lastError = ""
+
+		m.lock.Lock()
+		select {
+		case <-stopped:
+			m.lock.Unlock()
+			port.Close()
+			return nil
+		default:
+		}
+		m.port = port
+		m.lock.Unlock()
+
+		fmt.Fprintf(m.status, "Connected to %s. Press Ctrl-C to exit.\n", name)
+		err = m.copyOutput(port)
+
+		m.lock.Lock()
+		m.port = nil
+		m.lock.Unlock()
+		port.Close()
+
+		select {
+		case <-stopped:
+			return nil
+		default:
+		}
+		fmt.Fprintf(m.status, "Disconnected from %s: %s\n", name, err)
+	}
+}
+
+// copyOutput copies everything read from the port to stdout, until reading
+// fails. Backtraces are decoded after the line they are printed on.
+func (m *monitor) copyOutput(port io.Reader) error {
+	const maxLineLength = 4096
+	buf := make([]byte, 256)
+	var line []byte
+	for {
+		n, err := port.Read(buf)
+		if n > 0 {
+			m.stdout.Write(buf[:n])
+			for _, c := range buf[:n] {
+				if c == '\n' {
+					m.decodeBacktrace(string(line))
+					line = line[:0]
+				} else if len(line) < maxLineLength {
+					line = append(line, c)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// A read without data means the device went away.
+			return io.EOF
+		}
+	}
+}
+
+// decodeBacktrace prints the function and source location of each address if
+// the line contains a backtrace.
+func (m *monitor) decodeBacktrace(line string) {
+	if m.executable == "" {
+		return
+	}
+	addresses, ok := parseBacktrace(line)
+	if !ok {
+		return
+	}
+	err := printAddressLocations(m.stdout, m.executable, addresses)
+	if err != nil {
+		fmt.Fprintf(m.status, "could not decode backtrace: %s\n", err)
+	}
+}
+
+// copyInput sends everything read from r to the device. Input is dropped while
+// the device is not connected.
+func (m *monitor) copyInput(r io.Reader) {
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			m.lock.Lock()
+			if m.port != nil {
+				m.port.Write(buf[:n])
+			}
+			m.lock.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY creates a new pseudo terminal, which acts as a serial port. It
+// returns the controlling side and the path of the serial port.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("pseudo terminals not available:", err)
+	}
+	fd := int(ptmx.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		ptmx.Close()
+		t.Fatal("could not unlock pty:", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		ptmx.Close()
+		t.Fatal("could not get pty number:", err)
+	}
+	return ptmx, "/dev/pts/" + strconv.Itoa(n)
+}
+
+// lockedBuffer is a bytes.Buffer that can be used from multiple goroutines.
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until the buffer contains the given string.
+func waitFor(t *testing.T, b *lockedBuffer, s string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(b.String(), s) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %q, got:\n%s", s, b.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	ptmx1, port1 := openPTY(t)
+	defer ptmx1.Close()
+	ptmx2, port2 := openPTY(t)
+	defer ptmx2.Close()
+
+	var portLock sync.Mutex
+	port := port1
+	stdout := &lockedBuffer{}
+	status := &lockedBuffer{}
+	m := &monitor{
+		open: func() (io.ReadWriteCloser, string, error) {
+			portLock.Lock()
+			name := port
+			portLock.Unlock()
+			p, err := serial.Open(name, &serial.Mode{BaudRate: 115200})
+			return p, name, err
+		},
+		stdout:        stdout,
+		status:        status,
+		retryInterval: 10 * time.Millisecond,
+	}
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- m.run(stop)
+	}()
+
+	// Output of the device is printed.
+	waitFor(t, status, "Connected to "+port1)
+	ptmx1.Write([]byte("hello\n"))
+	waitFor(t, stdout, "hello")
+
+	// Input is sent to the device.
+	go m.copyInput(strings.NewReader("ping\n"))
+	buf := make([]byte, 64)
+	n, err := ptmx1.Read(buf)
+	if err != nil {
+		t.Fatal("could not read input:", err)
+	}
+	if !strings.Contains(string(buf[:n]), "ping") {
+		t.Errorf("expected input to be sent to the device, got %q", buf[:n])
+	}
+
+	// The device resets and comes back, possibly as a different port.
+	portLock.Lock()
+	port = port2
+	portLock.Unlock()
+	ptmx1.Close()
+	waitFor(t, status, "Disconnected from "+port1)
+	waitFor(t, status, "Connected to "+port2)
+	ptmx2.Write([]byte("reconnected\n"))
+	waitFor(t, stdout, "reconnected")
+
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("monitor failed:", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("monitor did not stop")
+	}
+}
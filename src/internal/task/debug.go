@@ -0,0 +1,52 @@
+package task
+
+// WaitReason describes what a paused goroutine is waiting for. It is only
+// recorded when building with -tags=goroutinedebug, see SetWaitReason.
+type WaitReason uint8
+
+const (
+	WaitNone WaitReason = iota
+	WaitChanSend
+	WaitChanRecv
+	WaitSelect
+	WaitSleep
+	WaitMutex
+	WaitRWMutexLock
+	WaitRWMutexRLock
+	WaitCond
+	WaitWaitGroup
+	WaitInterrupt
+	WaitIO
+	WaitForever
+)
+
+func (r WaitReason) String() string {
+	switch r {
+	case WaitChanSend:
+		return "chan send"
+	case WaitChanRecv:
+		return "chan receive"
+	case WaitSelect:
+		return "select"
+	case WaitSleep:
+		return "sleep"
+	case WaitMutex:
+		return "sync.Mutex.Lock"
+	case WaitRWMutexLock:
+		return "sync.RWMutex.Lock"
+	case WaitRWMutexRLock:
+		return "sync.RWMutex.RLock"
+	case WaitCond:
+		return "sync.Cond.Wait"
+	case WaitWaitGroup:
+		return "sync.WaitGroup.Wait"
+	case WaitInterrupt:
+		return "interrupt"
+	case WaitIO:
+		return "IO wait"
+	case WaitForever:
+		return "blocked forever"
+	default:
+		return "waiting"
+	}
+}
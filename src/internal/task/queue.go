@@ -1,7 +1,5 @@
 package task
 
-import "runtime/interrupt"
-
 const asserts = false
 
 // Queue is a FIFO container of tasks.
@@ -12,9 +10,9 @@ type Queue struct {
 
 // Push a task onto the queue.
 func (q *Queue) Push(t *Task) {
-	i := interrupt.Disable()
+	i := lockQueue()
 	if asserts && t.Next != nil {
-		interrupt.Restore(i)
+		unlockQueue(i)
 		panic("runtime: pushing a task to a queue with a non-nil Next pointer")
 	}
 	if q.tail != nil {
@@ -25,15 +23,16 @@ func (q *Queue) Push(t *Task) {
 	if q.head == nil {
 		q.head = t
 	}
-	interrupt.Restore(i)
+	unlockQueue(i)
+	notifyCores()
 }
 
 // Pop a task off of the queue.
 func (q *Queue) Pop() *Task {
-	i := interrupt.Disable()
+	i := lockQueue()
 	t := q.head
 	if t == nil {
-		interrupt.Restore(i)
+		unlockQueue(i)
 		return nil
 	}
 	q.head = t.Next
@@ -41,13 +40,13 @@ func (q *Queue) Pop() *Task {
 		q.tail = nil
 	}
 	t.Next = nil
-	interrupt.Restore(i)
+	unlockQueue(i)
 	return t
 }
 
 // Append pops the contents of another queue and pushes them onto the end of this queue.
 func (q *Queue) Append(other *Queue) {
-	i := interrupt.Disable()
+	i := lockQueue()
 	if q.head == nil {
 		q.head = other.head
 	} else {
@@ -55,14 +54,15 @@ func (q *Queue) Append(other *Queue) {
 	}
 	q.tail = other.tail
 	other.head, other.tail = nil, nil
-	interrupt.Restore(i)
+	unlockQueue(i)
+	notifyCores()
 }
 
 // Empty checks if the queue is empty.
 func (q *Queue) Empty() bool {
-	i := interrupt.Disable()
+	i := lockQueue()
 	empty := q.head == nil
-	interrupt.Restore(i)
+	unlockQueue(i)
 	return empty
 }
 
@@ -75,24 +75,24 @@ type Stack struct {
 
 // Push a task onto the stack.
 func (s *Stack) Push(t *Task) {
-	i := interrupt.Disable()
+	i := lockQueue()
 	if asserts && t.Next != nil {
-		interrupt.Restore(i)
+		unlockQueue(i)
 		panic("runtime: pushing a task to a stack with a non-nil Next pointer")
 	}
 	s.top, t.Next = t, s.top
-	interrupt.Restore(i)
+	unlockQueue(i)
 }
 
 // Pop a task off of the stack.
 func (s *Stack) Pop() *Task {
-	i := interrupt.Disable()
+	i := lockQueue()
 	t := s.top
 	if t != nil {
 		s.top = t.Next
 		t.Next = nil
 	}
-	interrupt.Restore(i)
+	unlockQueue(i)
 	return t
 }
 
@@ -112,13 +112,13 @@ func (t *Task) tail() *Task {
 // Queue moves the contents of the stack into a queue.
 // Elements can be popped from the queue in the same order that they would be popped from the stack.
 func (s *Stack) Queue() Queue {
-	i := interrupt.Disable()
+	i := lockQueue()
 	head := s.top
 	s.top = nil
 	q := Queue{
 		head: head,
 		tail: head.tail(),
 	}
-	interrupt.Restore(i)
+	unlockQueue(i)
 	return q
 }
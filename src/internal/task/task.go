@@ -15,6 +15,10 @@ type Task struct {
 	// Data is a field which can be used for storing state information.
 	Data uint64
 
+	// debug records what the task is waiting for, when built with
+	// -tags=goroutinedebug.
+	debug DebugInfo
+
 	// gcData holds data for the GC.
 	gcData gcData
 
@@ -0,0 +1,53 @@
+//go:build scheduler.cores
+// +build scheduler.cores
+
+package task
+
+import (
+	"runtime/interrupt"
+	"runtime/volatile"
+)
+
+// runningFlag is set while a task is running on one of the cores. It is needed
+// because a task can be pushed to the runqueue (for example, by a channel
+// operation on the other core) right before it pauses itself.
+type runningFlag uint32
+
+// acquire waits until the task has been fully paused by the core it was
+// previously running on, and marks it as running.
+func (f *runningFlag) acquire() {
+	for volatile.LoadUint32((*uint32)(f)) != 0 {
+	}
+	volatile.StoreUint32((*uint32)(f), 1)
+	memoryBarrier()
+}
+
+// release marks the task as paused. The task may be resumed on any core after
+// this call.
+func (f *runningFlag) release() {
+	memoryBarrier()
+	volatile.StoreUint32((*uint32)(f), 0)
+}
+
+// queueLock protects all task queues and stacks.
+var queueLock Spinlock
+
+// lockQueue protects task queues and stacks against concurrent modification
+// from interrupts and from the other cores.
+func lockQueue() interrupt.State {
+	i := interrupt.Disable()
+	queueLock.Lock()
+	return i
+}
+
+// unlockQueue releases the lock obtained with lockQueue.
+func unlockQueue(i interrupt.State) {
+	queueLock.Unlock()
+	interrupt.Restore(i)
+}
+
+// notifyCores wakes up the cores that are waiting for a task to be pushed to a
+// queue, see WaitForEvent.
+func notifyCores() {
+	SendEvent()
+}
@@ -0,0 +1,94 @@
+//go:build scheduler.cores && rp2040
+// +build scheduler.cores,rp2040
+
+package task
+
+import (
+	"device/arm"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// NumCPU is the number of cores that goroutines are run on.
+const NumCPU = 2
+
+// Registers of the SIO block that are used here. The device/rp package is not
+// imported to keep this package small.
+var (
+	sioCPUID = (*volatile.Register32)(unsafe.Pointer(uintptr(0xd0000000)))
+
+	// Hardware spinlock 31. The Pico SDK leaves the upper spinlocks free for
+	// use by applications, so this one is least likely to conflict with
+	// existing code.
+	sioSpinlock = (*volatile.Register32)(unsafe.Pointer(uintptr(0xd0000100 + 31*4)))
+)
+
+// CPU returns the index of the core the caller is running on.
+func CPU() int {
+	return int(sioCPUID.Get())
+}
+
+// Spinlock is a lock that is safe to use from multiple cores. It must not be
+// held for a long time, as other cores busy-wait while it is locked.
+//
+// There are only 32 hardware spinlocks on the RP2040, so instead they are
+// implemented in software using a single hardware spinlock to make the test
+// and set operation atomic.
+type Spinlock struct {
+	locked uint32
+}
+
+// Lock waits until the lock is available and then locks it.
+func (l *Spinlock) Lock() {
+	for !l.TryLock() {
+	}
+}
+
+// TryLock tries to lock the lock and reports whether it succeeded.
+func (l *Spinlock) TryLock() bool {
+	// Interrupts must be disabled while holding the hardware spinlock, or an
+	// interrupt that uses a spinlock on the same core would deadlock.
+	mask := interrupt.Disable()
+
+	// Reading the register claims the hardware spinlock. It returns zero if
+	// it was already claimed.
+	for sioSpinlock.Get() == 0 {
+	}
+	arm.Asm("dmb")
+	ok := volatile.LoadUint32(&l.locked) == 0
+	if ok {
+		volatile.StoreUint32(&l.locked, 1)
+	}
+	arm.Asm("dmb")
+
+	// Release the hardware spinlock. Any value will do.
+	sioSpinlock.Set(0)
+	interrupt.Restore(mask)
+	return ok
+}
+
+// Unlock releases the lock.
+func (l *Spinlock) Unlock() {
+	arm.Asm("dmb")
+	volatile.StoreUint32(&l.locked, 0)
+}
+
+// WaitForEvent puts the current core in a low power state until another core
+// calls SendEvent or an interrupt happens. It may return early, so it must be
+// called in a loop that checks the condition being waited for.
+func WaitForEvent() {
+	arm.Asm("wfe")
+}
+
+// SendEvent wakes up the other cores if they are in WaitForEvent. If a core is
+// not waiting, the next call to WaitForEvent on that core returns immediately.
+func SendEvent() {
+	arm.Asm("sev")
+}
+
+// memoryBarrier makes sure all memory accesses before it are visible to the
+// other core before any memory accesses after it.
+func memoryBarrier() {
+	arm.Asm("dmb")
+}
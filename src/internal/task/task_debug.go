@@ -0,0 +1,100 @@
+//go:build goroutinedebug
+// +build goroutinedebug
+
+package task
+
+// Goroutine debugging, enabled with -tags=goroutinedebug. All goroutines that
+// haven't exited are kept in a list, and every goroutine records what it is
+// waiting for and where right before it pauses. The runtime uses this to print
+// the state of all goroutines when they are all blocked. Only the stack-based
+// schedulers (-scheduler=tasks and -scheduler=cores) are supported.
+
+import "unsafe"
+
+// Debugging is true when goroutine debugging is enabled with
+// -tags=goroutinedebug.
+const Debugging = true
+
+// DebugInfo is stored in every task.
+type DebugInfo struct {
+	// prev and next link all tasks in allTasks.
+	prev, next *Task
+
+	// Reason is what the task is waiting for, or WaitNone if the task is
+	// running or runnable.
+	Reason WaitReason
+
+	// Object is the object the task is waiting on (for example a channel or
+	// mutex), if any.
+	Object unsafe.Pointer
+
+	// PCs holds the return addresses of the call stack at the time
+	// SetWaitReason was called, starting at the caller of SetWaitReason.
+	PCs [8]uintptr
+}
+
+// allTasks is a list of all tasks that have been started and haven't exited
+// yet.
+var allTasks *Task
+
+//go:linkname callers runtime.callers
+func callers(skip int, pc []uintptr) int
+
+// SetWaitReason records what the current goroutine is about to wait for. It
+// must be called right before the goroutine pauses.
+func SetWaitReason(reason WaitReason, object unsafe.Pointer) {
+	t := Current()
+	if t == nil {
+		return
+	}
+	t.debug.Reason = reason
+	t.debug.Object = object
+	n := callers(1, t.debug.PCs[:])
+	for i := n; i < len(t.debug.PCs); i++ {
+		t.debug.PCs[i] = 0
+	}
+}
+
+// Debug returns the debug information of the task.
+func (t *Task) Debug() *DebugInfo {
+	return &t.debug
+}
+
+// ForEach calls fn for every goroutine that hasn't exited yet. The list is not
+// locked, as fn may need interrupts (for example to print something), so
+// goroutines that start or exit at the same time may be missed.
+func ForEach(fn func(t *Task)) {
+	for t := allTasks; t != nil; t = t.debug.next {
+		fn(t)
+	}
+}
+
+// clear resets the wait reason once the task is resumed.
+func (d *DebugInfo) clear() {
+	d.Reason = WaitNone
+	d.Object = nil
+}
+
+func addTask(t *Task) {
+	i := lockQueue()
+	t.debug.next = allTasks
+	if allTasks != nil {
+		allTasks.debug.prev = t
+	}
+	allTasks = t
+	unlockQueue(i)
+}
+
+func removeTask(t *Task) {
+	i := lockQueue()
+	if t.debug.prev != nil {
+		t.debug.prev.debug.next = t.debug.next
+	} else {
+		allTasks = t.debug.next
+	}
+	if t.debug.next != nil {
+		t.debug.next.debug.prev = t.debug.prev
+	}
+	t.debug.prev, t.debug.next = nil, nil
+	unlockQueue(i)
+}
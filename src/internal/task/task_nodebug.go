@@ -0,0 +1,24 @@
+//go:build !goroutinedebug
+// +build !goroutinedebug
+
+package task
+
+import "unsafe"
+
+// Debugging is true when goroutine debugging is enabled with
+// -tags=goroutinedebug.
+const Debugging = false
+
+// DebugInfo is empty without -tags=goroutinedebug, so it doesn't take up space
+// in every task.
+type DebugInfo struct{}
+
+// SetWaitReason records what the current goroutine is about to wait for. It
+// does nothing without -tags=goroutinedebug.
+func SetWaitReason(reason WaitReason, object unsafe.Pointer) {}
+
+func (d *DebugInfo) clear() {}
+
+func addTask(t *Task) {}
+
+func removeTask(t *Task) {}
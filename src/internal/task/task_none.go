@@ -33,3 +33,20 @@ func OnSystemStack() bool {
 	// This scheduler does not do any stack switching.
 	return true
 }
+
+// StackTop always returns 0: there are no goroutine stacks without a
+// scheduler.
+func StackTop(sp uintptr) uintptr {
+	return 0
+}
+
+// CheckStackGuard does nothing: there are no goroutine stacks without a
+// scheduler.
+func CheckStackGuard(addr uintptr) {
+}
+
+// SkipStackGuard returns start: there are no goroutine stacks without a
+// scheduler.
+func SkipStackGuard(start, end uintptr) uintptr {
+	return start
+}
@@ -0,0 +1,31 @@
+//go:build !preempt
+// +build !preempt
+
+package task
+
+// Goroutines are only switched cooperatively, so these functions have nothing
+// to do. See task_stack_cortexm_preempt.go for the preemptive version.
+
+// DisablePreemption makes sure the current goroutine is not preempted until it
+// has been paused.
+func DisablePreemption() {}
+
+// EnablePreemption undoes DisablePreemption when the current goroutine didn't
+// pause after all.
+func EnablePreemption() {}
+
+// LockPreemption prevents the current goroutine from being preempted until
+// UnlockPreemption is called.
+func LockPreemption() {}
+
+// UnlockPreemption releases the lock obtained with LockPreemption.
+func UnlockPreemption() {}
+
+// startTimeSlice is called by the scheduler right before it resumes a task.
+func startTimeSlice(s *state) {}
+
+// endTimeSlice is called by the scheduler after a task returned to it, and
+// reports whether the task was preempted.
+func endTimeSlice() bool {
+	return false
+}
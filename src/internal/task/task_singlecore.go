@@ -0,0 +1,36 @@
+//go:build !scheduler.cores
+// +build !scheduler.cores
+
+package task
+
+import "runtime/interrupt"
+
+// NumCPU is the number of cores that goroutines are run on.
+const NumCPU = 1
+
+// CPU returns the index of the core the caller is running on.
+func CPU() int {
+	return 0
+}
+
+// runningFlag is not needed when there is only one core: a task can only be
+// resumed after it has paused.
+type runningFlag struct{}
+
+func (f *runningFlag) acquire() {}
+
+func (f *runningFlag) release() {}
+
+// lockQueue protects task queues and stacks against concurrent modification
+// from interrupts.
+func lockQueue() interrupt.State {
+	return interrupt.Disable()
+}
+
+// unlockQueue releases the lock obtained with lockQueue.
+func unlockQueue(i interrupt.State) {
+	interrupt.Restore(i)
+}
+
+// notifyCores does nothing: there are no other cores waiting for work.
+func notifyCores() {}
@@ -1,88 +1,167 @@
-//go:build scheduler.tasks
-// +build scheduler.tasks
+//go:build scheduler.tasks || scheduler.cores
+// +build scheduler.tasks scheduler.cores
 
 package task
 
 import "unsafe"
 
-//go:linkname runtimePanic runtime.runtimePanic
-func runtimePanic(str string)
+// stackOverflow reports a goroutine stack overflow and aborts the program.
+//go:linkname stackOverflow runtime.stackOverflow
+func stackOverflow(t *Task, fn uintptr, stackSize uintptr)
 
 // Stack canary, to detect a stack overflow. The number is a random number
 // generated by random.org. The bit fiddling dance is necessary because
 // otherwise Go wouldn't allow the cast to a smaller integer size.
 const stackCanary = uintptr(uint64(0x670c1333b83bf575) & uint64(^uintptr(0)))
 
+// stackCanaryWords is the number of canary words at the end of every goroutine
+// stack. Using more than one word makes it more likely that an overflow is
+// detected when a function skips over the canary, for example because it
+// allocates a large stack frame without writing to all of it.
+const stackCanaryWords = 4
+
 // state is a structure which holds a reference to the state of the task.
 // When the task is suspended, the registers are stored onto the stack and the stack pointer is stored into sp.
 type state struct {
+	// running is set while the task is running on one of the cores. It is
+	// only used with -scheduler=cores. This field is placed first as it has a
+	// size of zero otherwise.
+	running runningFlag
+
 	// sp is the stack pointer of the saved state.
 	// When the task is inactive, the saved registers are stored at the top of the stack.
 	sp uintptr
 
-	// canaryPtr points to the top word of the stack (the lowest address).
+	// canaryPtr points to the top words of the stack (the lowest addresses,
+	// right above the guard region if there is one).
 	// This is used to detect stack overflows.
 	// When initializing the goroutine, the stackCanary constant is stored there.
-	// If the stack overflowed, the word will likely no longer equal stackCanary.
-	canaryPtr *uintptr
+	// If the stack overflowed, the words will likely no longer equal
+	// stackCanary.
+	canaryPtr *[stackCanaryWords]uintptr
+
+	// top is the highest address of the stack (just past the end of the stack
+	// allocation).
+	top uintptr
+
+	// guard is the address of the guard region below the stack that is
+	// protected by the MPU while the goroutine runs, or 0 if the chip has no
+	// (supported) MPU. See task_stack_cortexm_guard.go.
+	guard uintptr
+
+	// fn and stackSize are the function the goroutine was started with and
+	// the stack size that was requested for it. They are only used to report
+	// stack overflows.
+	fn        uintptr
+	stackSize uintptr
 }
 
-// currentTask is the current running task, or nil if currently in the scheduler.
-var currentTask *Task
+// currentTask is the current running task on each core, or nil if that core is
+// currently in the scheduler.
+var currentTask [NumCPU]*Task
 
 // Current returns the current active task.
 func Current() *Task {
-	return currentTask
+	return currentTask[CPU()]
 }
 
 // Pause suspends the current task and returns to the scheduler.
 // This function may only be called when running on a goroutine stack, not when running on the system stack or in an interrupt.
 func Pause() {
-	// Check whether the canary (the lowest address of the stack) is still
+	// The task is switching to the scheduler, it must not be preempted while
+	// doing that.
+	DisablePreemption()
+
+	// Check whether the canary (the lowest addresses of the stack) is still
 	// valid. If it is not, a stack overflow has occured.
-	if *currentTask.state.canaryPtr != stackCanary {
-		runtimePanic("goroutine stack overflow")
+	t := Current()
+	t.checkStack()
+	t.state.pause()
+}
+
+// checkStack aborts the program if the stack canary of the task was
+// overwritten.
+func (t *Task) checkStack() {
+	for _, word := range t.state.canaryPtr {
+		if word != stackCanary {
+			stackOverflow(t, t.state.fn, t.state.stackSize)
+		}
 	}
-	currentTask.state.pause()
 }
 
+// pause is called when a goroutine exits.
 //export tinygo_pause
 func pause() {
+	removeTask(Current())
 	Pause()
 }
 
 // Resume the task until it pauses or completes.
 // This may only be called from the scheduler.
 func (t *Task) Resume() {
-	currentTask = t
+	// With multiple cores, the task may have been made runnable again just
+	// before it paused on the other core. Wait until it is fully paused.
+	t.state.running.acquire()
+	t.debug.clear()
+	cpu := CPU()
+	currentTask[cpu] = t
 	t.gcData.swap()
+	startTimeSlice(&t.state)
+	enableStackGuard(&t.state)
 	t.state.resume()
+	disableStackGuard(&t.state)
+	// Check the stack again, now that the stack guard is disabled: the task
+	// may have been preempted (without calling Pause) or may have overflowed
+	// its stack in Pause.
+	t.checkStack()
+	preempted := endTimeSlice()
 	t.gcData.swap()
-	currentTask = nil
+	currentTask[cpu] = nil
+	t.state.running.release()
+	if preempted {
+		// The task didn't pause but was preempted, so it can continue running
+		// later.
+		runqueuePushBack(t)
+	}
 }
 
 // initialize the state and prepare to call the specified function with the specified argument bundle.
 func (s *state) initialize(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
-	// Create a stack.
-	stack := make([]uintptr, stackSize/unsafe.Sizeof(uintptr(0)))
+	s.fn = fn
+	s.stackSize = stackSize
+
+	// Create a stack, with room for a guard region if the MPU supports it.
+	// The stack is allocated with an unknown layout (instead of as a
+	// []uintptr) so that the precise GC scans all of it for pointers.
+	size := (stackSize + stackGuardSize()) &^ (unsafe.Sizeof(uintptr(0)) - 1)
+	stack := uintptr(alloc(size, nil))
 
 	// Set up the stack canary, a random number that should be checked when
 	// switching from the task back to the scheduler. The stack canary pointer
-	// points to the first word of the stack. If it has changed between now and
-	// the next stack switch, there was a stack overflow.
-	s.canaryPtr = &stack[0]
-	*s.canaryPtr = stackCanary
+	// points to the first words of the stack (above the guard region). If they
+	// have changed between now and the next stack switch, there was a stack
+	// overflow.
+	var bottom uintptr
+	s.guard, bottom = initStackGuard(stack)
+	s.canaryPtr = (*[stackCanaryWords]uintptr)(unsafe.Pointer(bottom))
+	s.top = stack + size
+	for i := range s.canaryPtr {
+		s.canaryPtr[i] = stackCanary
+	}
 
 	// Get a pointer to the top of the stack, where the initial register values
 	// are stored. They will be popped off the stack on the first stack switch
 	// to the goroutine, and will start running tinygo_startTask (this setup
 	// happens in archInit).
-	r := (*calleeSavedRegs)(unsafe.Pointer(&stack[uintptr(len(stack))-(unsafe.Sizeof(calleeSavedRegs{})/unsafe.Sizeof(uintptr(0)))]))
+	r := (*calleeSavedRegs)(unsafe.Pointer(stack + size - unsafe.Sizeof(calleeSavedRegs{})))
 
 	// Invoke architecture-specific initialization.
 	s.archInit(r, fn, args)
 }
 
+//go:linkname alloc runtime.alloc
+func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer
+
 //export tinygo_swapTask
 func swapTask(oldStack uintptr, newStack *uintptr)
 
@@ -100,6 +179,7 @@ func runqueuePushBack(*Task)
 func start(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
 	t := &Task{}
 	t.state.initialize(fn, args, stackSize)
+	addTask(t)
 	runqueuePushBack(t)
 }
 
@@ -108,3 +188,18 @@ func OnSystemStack() bool {
 	// If there is not an active goroutine, then this must be running on the system stack.
 	return Current() == nil
 }
+
+// StackTop returns the highest address of the stack of the current goroutine
+// if sp points into that stack, or 0 otherwise (for example while running on
+// the system stack). It is used to check frame pointers when walking the stack
+// of interrupted code.
+func StackTop(sp uintptr) uintptr {
+	t := Current()
+	if t == nil {
+		return 0
+	}
+	if sp < uintptr(unsafe.Pointer(t.state.canaryPtr)) || sp >= t.state.top {
+		return 0
+	}
+	return t.state.top
+}
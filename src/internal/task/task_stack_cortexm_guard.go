@@ -0,0 +1,182 @@
+//go:build cortexm && stackguard && (scheduler.tasks || scheduler.cores)
+// +build cortexm,stackguard
+// +build scheduler.tasks scheduler.cores
+
+package task
+
+// Stack guard regions on Cortex-M.
+//
+// The stack canary only detects a stack overflow at the next context switch,
+// when memory below the stack may already have been corrupted. On chips with
+// an MPU, the 32 bytes below every goroutine stack are made inaccessible while
+// the goroutine runs, so that a stack overflow results in a fault right away.
+// The HardFault handler then uses CheckStackGuard to report it as a stack
+// overflow.
+//
+// The guard is opt-in with -tags=stackguard, as it takes up an MPU region and
+// 64 extra bytes per goroutine, and makes every context switch slower.
+//
+// Only the Cortex-M3, M4, M7 (ARMv7-M) and the Cortex-M33 (ARMv8-M) are
+// supported. The guard uses the highest numbered MPU region, which takes
+// priority over other regions on ARMv7-M. On ARMv8-M regions must not overlap,
+// so the guard is only used when the MPU isn't in use already.
+
+import (
+	"device/arm"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// mpuType is the layout of the MPU registers, which is the same on ARMv7-M and
+// ARMv8-M (except that RASR is called RLAR on ARMv8-M).
+type mpuType struct {
+	TYPE volatile.Register32 // MPU Type Register
+	CTRL volatile.Register32 // MPU Control Register
+	RNR  volatile.Register32 // MPU Region Number Register
+	RBAR volatile.Register32 // MPU Region Base Address Register
+	RASR volatile.Register32 // MPU Region Attribute and Size Register (RLAR on ARMv8-M)
+}
+
+var mpu = (*mpuType)(unsafe.Pointer(uintptr(0xe000ed90)))
+
+const (
+	mpuCTRL_ENABLE     = 1 << 0
+	mpuCTRL_PRIVDEFENA = 1 << 2 // use the default memory map outside of MPU regions
+
+	// ARMv7-M: 32 bytes, no access, execute never, enabled.
+	mpuRASR_guard = 1<<28 | 4<<1 | 1
+
+	// ARMv8-M: read-only (privileged code only), execute never. There is no
+	// "no access" setting, but a stack overflow always writes to the stack.
+	mpuRBAR_guard = 2<<1 | 1
+
+	// ARMv8-M: region enabled.
+	mpuRLAR_EN = 1
+)
+
+// The size (and alignment) of the guard region, the smallest MPU region.
+const stackGuardRegionSize = 32
+
+const (
+	stackGuardUnknown = iota
+	stackGuardNone
+	stackGuardARMv7M
+	stackGuardARMv8M
+)
+
+// stackGuardMode is the kind of MPU of this chip, detected on first use.
+var stackGuardMode uint8
+
+// stackGuardRegion is the MPU region number used for the guard.
+var stackGuardRegion uint32
+
+// detectStackGuard detects whether this chip has a supported MPU, and enables
+// it if needed.
+func detectStackGuard() uint8 {
+	regions := (mpu.TYPE.Get() >> 8) & 0xff
+	if regions == 0 {
+		return stackGuardNone
+	}
+	mode := uint8(stackGuardNone)
+	switch (arm.SCB.CPUID.Get() & arm.SCB_CPUID_PARTNO_Msk) >> arm.SCB_CPUID_PARTNO_Pos {
+	case 0xc23, 0xc24, 0xc27: // Cortex-M3, M4, M7
+		mode = stackGuardARMv7M
+	case 0xd21: // Cortex-M33
+		if mpu.CTRL.Get()&mpuCTRL_ENABLE == 0 {
+			mode = stackGuardARMv8M
+		}
+	}
+	if mode == stackGuardNone {
+		return mode
+	}
+	stackGuardRegion = regions - 1
+	if mpu.CTRL.Get()&mpuCTRL_ENABLE == 0 {
+		// The MPU isn't used otherwise, so everything outside the guard
+		// region should behave as if the MPU is disabled.
+		mpu.CTRL.Set(mpuCTRL_PRIVDEFENA | mpuCTRL_ENABLE)
+		arm.Asm("dsb")
+		arm.Asm("isb")
+	}
+	return mode
+}
+
+// stackGuardSize returns the number of bytes to allocate in addition to the
+// stack itself, to fit an aligned guard region.
+func stackGuardSize() uintptr {
+	if stackGuardMode == stackGuardUnknown {
+		stackGuardMode = detectStackGuard()
+	}
+	if stackGuardMode == stackGuardNone {
+		return 0
+	}
+	return stackGuardRegionSize * 2
+}
+
+// initStackGuard returns the guard region within a stack allocated with
+// stackGuardSize extra bytes, and the lowest address of the stack above it.
+func initStackGuard(bottom uintptr) (guard, stackBottom uintptr) {
+	if stackGuardMode == stackGuardNone {
+		return 0, bottom
+	}
+	guard = (bottom + stackGuardRegionSize - 1) &^ (stackGuardRegionSize - 1)
+	return guard, guard + stackGuardRegionSize
+}
+
+// enableStackGuard protects the guard region of the given task, right before
+// it is resumed.
+func enableStackGuard(s *state) {
+	if s.guard == 0 {
+		return
+	}
+	mpu.RNR.Set(stackGuardRegion)
+	if stackGuardMode == stackGuardARMv7M {
+		mpu.RBAR.Set(uint32(s.guard))
+		mpu.RASR.Set(mpuRASR_guard)
+	} else {
+		mpu.RBAR.Set(uint32(s.guard) | mpuRBAR_guard)
+		mpu.RASR.Set(uint32(s.guard) | mpuRLAR_EN) // the limit is guard+31
+	}
+	arm.Asm("dsb")
+	arm.Asm("isb")
+}
+
+// disableStackGuard removes the protection again once the task has returned
+// to the scheduler.
+func disableStackGuard(s *state) {
+	if s.guard == 0 {
+		return
+	}
+	mpu.RNR.Set(stackGuardRegion)
+	mpu.RASR.Set(0)
+	arm.Asm("dsb")
+	arm.Asm("isb")
+}
+
+// CheckStackGuard is called on a HardFault with the faulting address (or the
+// stack pointer, if there is no fault address). If the address is within (or
+// right below) the guard region of the current goroutine, the fault is
+// reported as a stack overflow of this goroutine and the program is aborted.
+func CheckStackGuard(addr uintptr) {
+	t := Current()
+	if t == nil || t.state.guard == 0 {
+		return
+	}
+	if addr >= t.state.guard-stackGuardRegionSize && addr < t.state.guard+stackGuardRegionSize {
+		stackOverflow(t, t.state.fn, t.state.stackSize)
+	}
+}
+
+// SkipStackGuard returns the address to start scanning a heap object at, for a
+// heap object in [start, end). The stack of the current goroutine has its
+// guard region enabled, which would fault when read, so the GC skips to the
+// stack bottom right above the guard.
+func SkipStackGuard(start, end uintptr) uintptr {
+	t := Current()
+	if t == nil || t.state.guard == 0 {
+		return start
+	}
+	if t.state.guard >= start && t.state.guard < end {
+		return t.state.guard + stackGuardRegionSize
+	}
+	return start
+}
@@ -0,0 +1,129 @@
+//go:build preempt
+// +build preempt
+
+package task
+
+// Preemptive scheduling on Cortex-M, used with -preempt (which is only
+// supported with -scheduler=tasks).
+//
+// The SysTick timer fires at a fixed interval. Once a goroutine has used up its
+// time slice, the SysTick handler triggers PendSV, the exception with the
+// lowest priority. PendSV (see task_stack_cortexm_preempt.S) stores the state
+// of the goroutine on its own stack in a way that looks like a regular call to
+// Pause, and returns to the scheduler, which adds the goroutine to the end of
+// the runqueue. When the goroutine is resumed, it triggers PendSV again to
+// return to the place where it was interrupted.
+//
+// A goroutine must not be preempted after it added itself to a wait list (of a
+// channel, a mutex, the sleep queue, etc) and before it paused: the runqueue and
+// these wait lists share the Task.Next field. Therefore, all such places call
+// DisablePreemption before adding the goroutine to a wait list. Preemption is
+// enabled again when the scheduler resumes the goroutine.
+
+import (
+	"device/arm"
+	"runtime/volatile"
+)
+
+// preemptDisabled is set by DisablePreemption, and cleared when a task is
+// resumed.
+var preemptDisabled uint8
+
+// preemptLocks is the number of LockPreemption calls that have not been
+// matched by UnlockPreemption yet.
+var preemptLocks uint8
+
+// The number of SysTick interrupts per time slice, and the number of SysTick
+// interrupts that have happened in the current time slice. The SysTick counter
+// is only 24 bits wide so long time slices need multiple interrupts.
+var preemptTicksPerSlice, preemptTicks uint32
+
+// preemptSP is a pointer to the saved stack pointer of the running task. It is
+// used in PendSV.
+//go:extern tinygo_preemptSP
+var preemptSP *uintptr
+
+// preempted is set by PendSV when the running task was preempted.
+//go:extern tinygo_preempted
+var preempted uint32
+
+// StartPreemption starts the SysTick timer, to preempt goroutines that run
+// longer than the given number of CPU cycles.
+func StartPreemption(cycles uint64) {
+	ticks := uint64(1)
+	for cycles/ticks > arm.SYST_RVR_RELOAD_Msk+1 {
+		ticks++
+	}
+	reload := uint32(cycles / ticks)
+	if reload < 256 {
+		// Don't spend all time in the SysTick handler.
+		reload = 256
+	}
+	preemptTicksPerSlice = uint32(ticks)
+
+	// Give PendSV and SysTick the lowest possible priority, so that they never
+	// delay other interrupts.
+	arm.SCB.SHPR3.SetBits(0xffff0000)
+
+	arm.SetupSystemTimer(reload - 1)
+}
+
+// handleSysTick is called on every SysTick interrupt and preempts the running
+// goroutine at the end of its time slice.
+//export SysTick_Handler
+func handleSysTick() {
+	preemptTicks++
+	if preemptTicks < preemptTicksPerSlice {
+		return
+	}
+	preemptTicks = 0
+	if Current() == nil || volatile.LoadUint8(&preemptDisabled) != 0 || volatile.LoadUint8(&preemptLocks) != 0 {
+		// Not running a goroutine (or the scheduler is about to switch to a
+		// goroutine), or this goroutine must not be preempted right now.
+		return
+	}
+	arm.SCB.ICSR.Set(arm.SCB_ICSR_PENDSVSET)
+}
+
+// DisablePreemption makes sure the current goroutine is not preempted until it
+// has been paused.
+func DisablePreemption() {
+	volatile.StoreUint8(&preemptDisabled, 1)
+}
+
+// EnablePreemption undoes DisablePreemption when the current goroutine didn't
+// pause after all.
+func EnablePreemption() {
+	volatile.StoreUint8(&preemptDisabled, 0)
+}
+
+// LockPreemption prevents the current goroutine from being preempted until
+// UnlockPreemption is called.
+func LockPreemption() {
+	volatile.StoreUint8(&preemptLocks, preemptLocks+1)
+}
+
+// UnlockPreemption releases the lock obtained with LockPreemption.
+func UnlockPreemption() {
+	volatile.StoreUint8(&preemptLocks, preemptLocks-1)
+}
+
+// startTimeSlice is called by the scheduler right before it resumes a task.
+func startTimeSlice(s *state) {
+	preemptSP = &s.sp
+	volatile.StoreUint8(&preemptDisabled, 0)
+
+	// Restart the SysTick timer, so that the task gets a full time slice.
+	preemptTicks = 0
+	arm.SYST.SYST_CVR.Set(0)
+}
+
+// endTimeSlice is called by the scheduler after a task returned to it, and
+// reports whether the task was preempted.
+func endTimeSlice() bool {
+	if volatile.LoadUint32(&preempted) == 0 {
+		return false
+	}
+	volatile.StoreUint32(&preempted, 0)
+	return true
+}
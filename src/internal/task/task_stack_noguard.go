@@ -0,0 +1,31 @@
+//go:build (scheduler.tasks || scheduler.cores) && (!cortexm || !stackguard)
+// +build scheduler.tasks scheduler.cores
+// +build !cortexm !stackguard
+
+package task
+
+// Stack guard regions are only supported on Cortex-M with -tags=stackguard,
+// see task_stack_cortexm_guard.go. Otherwise, only the stack canary is checked.
+
+func stackGuardSize() uintptr {
+	return 0
+}
+
+func initStackGuard(bottom uintptr) (guard, stackBottom uintptr) {
+	return 0, bottom
+}
+
+func enableStackGuard(s *state) {
+}
+
+func disableStackGuard(s *state) {
+}
+
+// CheckStackGuard does nothing: there are no guard regions.
+func CheckStackGuard(addr uintptr) {
+}
+
+// SkipStackGuard returns start: there are no guard regions to skip.
+func SkipStackGuard(start, end uintptr) uintptr {
+	return start
+}
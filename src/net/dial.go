@@ -5,21 +5,326 @@ import (
 	"time"
 )
 
+// A Dialer contains options for connecting to an address.
+//
+// Connections are made through the registered network driver (see
+// UseNetdev). Not all drivers support timeouts while connecting, in which case
+// Timeout, Deadline and the context deadline are only checked before
+// connecting.
 type Dialer struct {
-	Timeout   time.Duration
-	Deadline  time.Time
+	// Timeout is the maximum amount of time a dial will wait for a connect
+	// to complete. If Deadline is also set, it may fail earlier.
+	Timeout time.Duration
+
+	// Deadline is the absolute point in time after which dials will fail.
+	// If Timeout is set, it may fail earlier.
+	Deadline time.Time
+
+	LocalAddr Addr
 	DualStack bool
 	KeepAlive time.Duration
 }
 
+// Dial connects to the address on the named network.
+//
+// Known networks are "tcp", "tcp4", "tcp6", "udp", "udp4" and "udp6".
+//
+// For TCP and UDP networks, the address has the form "host:port". The host
+// must be a literal IP address, or a host name that can be resolved to IP
+// addresses. The port must be a literal port number or a service name.
 func Dial(network, address string) (Conn, error) {
-	return nil, ErrNotImplemented
+	var d Dialer
+	return d.Dial(network, address)
 }
 
-func Listen(network, address string) (Listener, error) {
-	return nil, ErrNotImplemented
+// DialTimeout acts like Dial but takes a timeout.
+func DialTimeout(network, address string, timeout time.Duration) (Conn, error) {
+	d := Dialer{Timeout: timeout}
+	return d.Dial(network, address)
+}
+
+// Dial connects to the address on the named network.
+//
+// See func Dial for a description of the network and address parameters.
+func (d *Dialer) Dial(network, address string) (Conn, error) {
+	return d.DialContext(context.Background(), network, address)
 }
 
+// DialContext connects to the address on the named network using the provided
+// context.
+//
+// See func Dial for a description of the network and address parameters.
 func (d *Dialer) DialContext(ctx context.Context, network, address string) (Conn, error) {
-	return nil, ErrNotImplemented
+	if err := ctx.Err(); err != nil {
+		return nil, &OpError{Op: "dial", Net: network, Err: err}
+	}
+	deadline := d.deadline(ctx, time.Now())
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return nil, &OpError{Op: "dial", Net: network, Source: d.LocalAddr, Err: errTimeout}
+	}
+	var laddr *sockaddr
+	if d.LocalAddr != nil {
+		a, err := toSockaddr(d.LocalAddr)
+		if err != nil {
+			return nil, &OpError{Op: "dial", Net: network, Source: d.LocalAddr, Err: err}
+		}
+		laddr = &a
+	}
+	raddr, err := resolveSockaddr(network, address)
+	if err != nil {
+		return nil, &OpError{Op: "dial", Net: network, Source: d.LocalAddr, Err: err}
+	}
+	fd, err := dialSocket(network, laddr, &raddr, deadline)
+	if err != nil {
+		return nil, &OpError{Op: "dial", Net: network, Source: d.LocalAddr, Addr: raddr.addr(network), Err: err}
+	}
+	return newConn(fd), nil
+}
+
+// deadline returns the earliest of the Dialer timeout, the Dialer deadline and
+// the context deadline, or the zero time if there is none.
+func (d *Dialer) deadline(ctx context.Context, now time.Time) (earliest time.Time) {
+	if d.Timeout != 0 {
+		earliest = now.Add(d.Timeout)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		earliest = minNonzeroTime(earliest, deadline)
+	}
+	return minNonzeroTime(earliest, d.Deadline)
+}
+
+// minNonzeroTime returns the earlier of a and b, ignoring zero times.
+func minNonzeroTime(a, b time.Time) time.Time {
+	if a.IsZero() {
+		return b
+	}
+	if b.IsZero() || a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Listen announces on the local network address.
+//
+// The network must be "tcp", "tcp4" or "tcp6".
+//
+// For TCP networks, if the host in the address parameter is empty or a literal
+// unspecified IP address, Listen listens on all available addresses. If the
+// port in the address parameter is empty or "0", as in "127.0.0.1:" or
+// "[::1]:0", a port number is automatically chosen by the driver.
+func Listen(network, address string) (Listener, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, &OpError{Op: "listen", Net: network, Err: UnknownNetworkError(network)}
+	}
+	laddr, err := resolveSockaddr(network, address)
+	if err != nil {
+		return nil, &OpError{Op: "listen", Net: network, Err: err}
+	}
+	fd, err := listenSocket(network, laddr)
+	if err != nil {
+		return nil, &OpError{Op: "listen", Net: network, Addr: laddr.addr(network), Err: err}
+	}
+	return &TCPListener{fd: fd}, nil
+}
+
+// ListenPacket announces on the local network address.
+//
+// The network must be "udp", "udp4" or "udp6".
+//
+// For UDP networks, if the host in the address parameter is empty or a literal
+// unspecified IP address, ListenPacket listens on all available addresses. If
+// the port in the address parameter is empty or "0", as in "127.0.0.1:" or
+// "[::1]:0", a port number is automatically chosen by the driver.
+//
+// The network driver must support unconnected datagram sockets (see Netdev).
+func ListenPacket(network, address string) (PacketConn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+	default:
+		return nil, &OpError{Op: "listen", Net: network, Err: UnknownNetworkError(network)}
+	}
+	laddr, err := resolveSockaddr(network, address)
+	if err != nil {
+		return nil, &OpError{Op: "listen", Net: network, Err: err}
+	}
+	fd, err := listenSocket(network, laddr)
+	if err != nil {
+		return nil, &OpError{Op: "listen", Net: network, Addr: laddr.addr(network), Err: err}
+	}
+	return &UDPConn{conn{fd}}, nil
+}
+
+// sockaddr is a resolved network address.
+type sockaddr struct {
+	host string // original host name, if any
+	ip   IP
+	port int
+}
+
+// addr returns the address as a TCPAddr or UDPAddr, depending on the network.
+func (a sockaddr) addr(network string) Addr {
+	switch network {
+	case "udp", "udp4", "udp6":
+		return &UDPAddr{IP: a.ip, Port: a.port}
+	default:
+		return &TCPAddr{IP: a.ip, Port: a.port}
+	}
+}
+
+// toSockaddr converts a TCPAddr or UDPAddr to a sockaddr.
+func toSockaddr(addr Addr) (sockaddr, error) {
+	switch addr := addr.(type) {
+	case *TCPAddr:
+		return sockaddr{ip: addr.IP, port: addr.Port}, nil
+	case *UDPAddr:
+		return sockaddr{ip: addr.IP, port: addr.Port}, nil
+	}
+	return sockaddr{}, &AddrError{Err: "unexpected address type", Addr: addr.String()}
+}
+
+// socketType returns the socket type and protocol for the given network.
+func socketType(network string) (stype, proto int, err error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return _SOCK_STREAM, _IPPROTO_TCP, nil
+	case "udp", "udp4", "udp6":
+		return _SOCK_DGRAM, _IPPROTO_UDP, nil
+	}
+	return 0, 0, UnknownNetworkError(network)
+}
+
+// socketDomain returns the address family of a socket for the given network
+// and IP address.
+func socketDomain(network string, ip IP) int {
+	if ip == nil {
+		if network == "tcp6" || network == "udp6" {
+			return _AF_INET6
+		}
+		return _AF_INET
+	}
+	if ip.To4() != nil {
+		return _AF_INET
+	}
+	return _AF_INET6
+}
+
+// resolveSockaddr resolves an address of the form "host:port" for the given
+// network. An empty host results in a nil IP address.
+func resolveSockaddr(network, address string) (sockaddr, error) {
+	if _, _, err := socketType(network); err != nil {
+		return sockaddr{}, err
+	}
+	host, service, err := SplitHostPort(address)
+	if err != nil {
+		return sockaddr{}, err
+	}
+	port, err := LookupPort(network, service)
+	if err != nil {
+		return sockaddr{}, err
+	}
+	if host == "" {
+		return sockaddr{port: port}, nil
+	}
+	ip, _ := parseIPZone(host)
+	if ip == nil {
+		ip, err = netdev.GetHostByName(host)
+		if err != nil {
+			return sockaddr{}, &DNSError{Err: err.Error(), Name: host}
+		}
+	}
+	switch network[len(network)-1] {
+	case '4':
+		if ip.To4() == nil {
+			return sockaddr{}, &AddrError{Err: "no suitable address found", Addr: host}
+		}
+	case '6':
+		if ip.To4() != nil {
+			return sockaddr{}, &AddrError{Err: "no suitable address found", Addr: host}
+		}
+	}
+	return sockaddr{host: host, ip: ip, port: port}, nil
+}
+
+// dialSocket creates a socket and connects it to raddr. The deadline (if not
+// zero) is only used when the driver supports it, see netdevConnectDeadline.
+func dialSocket(network string, laddr, raddr *sockaddr, deadline time.Time) (*netFD, error) {
+	if raddr.ip == nil {
+		// Like upstream Go, dialing the unspecified address means dialing the
+		// local system.
+		raddr.ip = IPv4(127, 0, 0, 1)
+		if network == "tcp6" || network == "udp6" {
+			raddr.ip = IPv6loopback
+		}
+	}
+	fd, err := newSocket(network, raddr.ip)
+	if err != nil {
+		return nil, err
+	}
+	if laddr != nil {
+		err = fd.dev.Bind(fd.sysfd, laddr.ip, laddr.port)
+		if err != nil {
+			fd.Close()
+			return nil, err
+		}
+		fd.laddr = laddr.addr(network)
+	}
+	if dev, ok := fd.dev.(netdevConnectDeadline); ok {
+		err = dev.ConnectDeadline(fd.sysfd, raddr.host, raddr.ip, raddr.port, deadline)
+	} else {
+		err = fd.dev.Connect(fd.sysfd, raddr.host, raddr.ip, raddr.port)
+	}
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	fd.raddr = raddr.addr(network)
+	fd.updateLocalAddr()
+	return fd, nil
+}
+
+// listenSocket creates a socket bound to laddr. Stream sockets are marked as
+// listening sockets, datagram sockets are left unconnected.
+func listenSocket(network string, laddr sockaddr) (*netFD, error) {
+	fd, err := newSocket(network, laddr.ip)
+	if err != nil {
+		return nil, err
+	}
+	err = fd.dev.Bind(fd.sysfd, laddr.ip, laddr.port)
+	if err == nil && fd.stype == _SOCK_STREAM {
+		err = fd.dev.Listen(fd.sysfd, listenerBacklog)
+	}
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	fd.laddr = laddr.addr(network)
+	fd.updateLocalAddr()
+	return fd, nil
+}
+
+// Maximum number of pending connections of a listening socket.
+const listenerBacklog = 4
+
+// newSocket creates a new socket with the registered network driver.
+func newSocket(network string, ip IP) (*netFD, error) {
+	stype, proto, err := socketType(network)
+	if err != nil {
+		return nil, err
+	}
+	dev := netdev
+	sysfd, err := dev.Socket(socketDomain(network, ip), stype, proto)
+	if err != nil {
+		return nil, err
+	}
+	return &netFD{dev: dev, sysfd: sysfd, stype: stype, net: network}, nil
+}
+
+// newConn wraps a connected socket in a TCPConn or UDPConn.
+func newConn(fd *netFD) Conn {
+	if fd.stype == _SOCK_DGRAM {
+		return &UDPConn{conn{fd}}
+	}
+	return &TCPConn{conn{fd}}
 }
@@ -8,3 +8,15 @@ var (
 
 	ErrNotImplemented = errors.New("operation not implemented")
 )
+
+// errTimeout is returned when an I/O deadline has passed.
+var errTimeout error = &timeoutError{}
+
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+// errMissingAddress is returned when no address was given.
+var errMissingAddress = errors.New("missing address")
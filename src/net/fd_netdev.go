@@ -0,0 +1,127 @@
+package net
+
+import (
+	"io"
+	"time"
+)
+
+// netFD is a socket of a network driver (see Netdev).
+type netFD struct {
+	dev    Netdev // the driver that created this socket
+	sysfd  int    // socket descriptor of the driver
+	stype  int    // socket type: _SOCK_STREAM or _SOCK_DGRAM
+	net    string // network name, like "tcp"
+	laddr  Addr
+	raddr  Addr
+	closed bool
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (fd *netFD) Read(b []byte) (int, error) {
+	if fd.closed {
+		return 0, ErrClosed
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	n, err := fd.dev.Recv(fd.sysfd, b, 0, fd.readDeadline)
+	if err != nil {
+		return n, err
+	}
+	if n == 0 && fd.stype == _SOCK_STREAM {
+		// The connection was closed by the peer.
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (fd *netFD) Write(b []byte) (int, error) {
+	if fd.closed {
+		return 0, ErrClosed
+	}
+	if fd.stype != _SOCK_STREAM {
+		// Datagrams must be sent in one piece.
+		return fd.dev.Send(fd.sysfd, b, 0, fd.writeDeadline)
+	}
+	// Drivers may send less than requested, so keep sending until all data
+	// has been sent.
+	n := 0
+	for n < len(b) {
+		m, err := fd.dev.Send(fd.sysfd, b[n:], 0, fd.writeDeadline)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// readFrom receives a datagram on an unconnected socket.
+func (fd *netFD) readFrom(b []byte) (int, sockaddr, error) {
+	if fd.closed {
+		return 0, sockaddr{}, ErrClosed
+	}
+	dev, ok := fd.dev.(netdevPacket)
+	if !ok {
+		return 0, sockaddr{}, ErrNotImplemented
+	}
+	n, ip, port, err := dev.RecvFrom(fd.sysfd, b, 0, fd.readDeadline)
+	return n, sockaddr{ip: ip, port: port}, err
+}
+
+// writeTo sends a datagram to the given address on an unconnected socket.
+func (fd *netFD) writeTo(b []byte, addr sockaddr) (int, error) {
+	if fd.closed {
+		return 0, ErrClosed
+	}
+	dev, ok := fd.dev.(netdevPacket)
+	if !ok {
+		return 0, ErrNotImplemented
+	}
+	return dev.SendTo(fd.sysfd, b, 0, addr.ip, addr.port, fd.writeDeadline)
+}
+
+// updateLocalAddr sets the local address to the address reported by the
+// driver, if the driver supports it.
+func (fd *netFD) updateLocalAddr() {
+	dev, ok := fd.dev.(netdevSockname)
+	if !ok {
+		return
+	}
+	ip, port, err := dev.Getsockname(fd.sysfd)
+	if err == nil {
+		fd.laddr = sockaddr{ip: ip, port: port}.addr(fd.net)
+	}
+}
+
+func (fd *netFD) Close() error {
+	if fd.closed {
+		return ErrClosed
+	}
+	fd.closed = true
+	return fd.dev.Close(fd.sysfd)
+}
+
+// accept waits for a new connection on a listening socket.
+func (fd *netFD) accept() (*netFD, error) {
+	if fd.closed {
+		return nil, ErrClosed
+	}
+	sysfd, ip, port, err := fd.dev.Accept(fd.sysfd)
+	if err != nil {
+		return nil, err
+	}
+	return &netFD{
+		dev:   fd.dev,
+		sysfd: sysfd,
+		stype: fd.stype,
+		net:   fd.net,
+		laddr: fd.laddr,
+		raddr: &TCPAddr{IP: ip, Port: port},
+	}, nil
+}
@@ -0,0 +1,82 @@
+//go:build linux && !baremetal && !nintendoswitch
+// +build linux,!baremetal,!nintendoswitch
+
+package net
+
+import (
+	"os"
+	"syscall"
+)
+
+// FileConn returns a copy of the network connection corresponding to the open
+// file f.
+//
+// Unlike upstream Go, the file descriptor is not duplicated: the connection
+// takes over the socket, and f must not be used or closed afterwards.
+func FileConn(f *os.File) (c Conn, err error) {
+	fd, err := fileSocket(f, "tcp")
+	if err != nil {
+		return nil, &OpError{Op: "file", Net: "file+net", Source: nil, Addr: fileAddr(f.Name()), Err: err}
+	}
+	return newConn(fd), nil
+}
+
+// FileListener returns a copy of the network listener corresponding to the
+// open file f. This is the only way to listen on a socket in WASI, where
+// listening sockets are preopened by the host.
+//
+// Unlike upstream Go, the file descriptor is not duplicated: the listener
+// takes over the socket, and f must not be used or closed afterwards.
+func FileListener(f *os.File) (ln Listener, err error) {
+	fd, err := fileSocket(f, "tcp")
+	if err != nil {
+		return nil, &OpError{Op: "file", Net: "file+net", Source: nil, Addr: fileAddr(f.Name()), Err: err}
+	}
+	return &TCPListener{fd: fd}, nil
+}
+
+// FilePacketConn returns a copy of the packet network connection corresponding
+// to the open file f.
+//
+// Unlike upstream Go, the file descriptor is not duplicated: the connection
+// takes over the socket, and f must not be used or closed afterwards.
+func FilePacketConn(f *os.File) (c PacketConn, err error) {
+	fd, err := fileSocket(f, "udp")
+	if err != nil {
+		return nil, &OpError{Op: "file", Net: "file+net", Source: nil, Addr: fileAddr(f.Name()), Err: err}
+	}
+	return &UDPConn{conn{fd}}, nil
+}
+
+// fileSocket returns a socket for the given file. The network is used when the
+// socket type cannot be determined, which is the case in WASI.
+func fileSocket(f *os.File, network string) (*netFD, error) {
+	sysfd := int(f.Fd())
+	if err := syscall.SetNonblock(sysfd, true); err != nil {
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if stype, err := syscall.GetsockoptInt(sysfd, syscall.SOL_SOCKET, syscall.SO_TYPE); err == nil {
+		switch stype {
+		case syscall.SOCK_STREAM:
+			network = "tcp"
+		case syscall.SOCK_DGRAM:
+			network = "udp"
+		default:
+			return nil, syscall.EPROTONOSUPPORT
+		}
+	}
+	stype, _, _ := socketType(network)
+	fd := &netFD{dev: socketNetdev{}, sysfd: sysfd, stype: stype, net: network}
+	fd.laddr = sockaddr{}.addr(network)
+	fd.updateLocalAddr()
+	if sa, err := syscall.Getpeername(sysfd); err == nil {
+		ip, port := fromSockaddr(sa)
+		fd.raddr = sockaddr{ip: ip, port: port}.addr(network)
+	}
+	return fd, nil
+}
+
+type fileAddr string
+
+func (fileAddr) Network() string  { return "file+net" }
+func (f fileAddr) String() string { return string(f) }
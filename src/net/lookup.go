@@ -0,0 +1,78 @@
+package net
+
+// lookupService returns the port number of some well-known services, as there
+// is no services database (/etc/services) on most systems TinyGo runs on.
+func lookupService(service string) (int, bool) {
+	switch service {
+	case "ftp":
+		return 21, true
+	case "ssh":
+		return 22, true
+	case "telnet":
+		return 23, true
+	case "smtp":
+		return 25, true
+	case "domain":
+		return 53, true
+	case "http":
+		return 80, true
+	case "ntp":
+		return 123, true
+	case "https":
+		return 443, true
+	case "mqtt":
+		return 1883, true
+	case "mqtts":
+		return 8883, true
+	}
+	return 0, false
+}
+
+// LookupHost looks up the given host using the registered network driver. It
+// returns a slice of that host's addresses.
+func LookupHost(host string) (addrs []string, err error) {
+	ips, err := LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
+
+// LookupIP looks up host using the registered network driver. It returns a
+// slice of that host's IPv4 and IPv6 addresses.
+func LookupIP(host string) ([]IP, error) {
+	if host == "" {
+		return nil, &DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	if ip, _ := parseIPZone(host); ip != nil {
+		return []IP{ip}, nil
+	}
+	ip, err := netdev.GetHostByName(host)
+	if err != nil {
+		return nil, &DNSError{Err: err.Error(), Name: host}
+	}
+	return []IP{ip}, nil
+}
+
+// LookupPort looks up the port for the given network and service.
+func LookupPort(network, service string) (port int, err error) {
+	if service == "" {
+		// Lock in the legacy behavior that an empty string
+		// means port 0. See golang.org/issue/13610.
+		return 0, nil
+	}
+	port, i, ok := dtoi(service)
+	if ok && i == len(service) {
+		if port < 0 || port > 0xFFFF {
+			return 0, &AddrError{Err: "invalid port", Addr: service}
+		}
+		return port, nil
+	}
+	if port, ok := lookupService(service); ok {
+		return port, nil
+	}
+	return 0, &DNSError{Err: "unknown port", Name: network + "/" + service}
+}
@@ -0,0 +1,110 @@
+package net
+
+import "time"
+
+// Netdev is the interface implemented by network drivers, such as drivers for
+// WiFi co-processors. A driver provides BSD socket-like operations, which are
+// used by the net package to implement Dial, Listen, Conn and friends.
+//
+// Sockets are identified by an integer descriptor chosen by the driver. The
+// domain, type and protocol values passed to Socket use the same values as
+// Linux: AF_INET (2) and AF_INET6 (10) for the domain, SOCK_STREAM (1) and
+// SOCK_DGRAM (2) for the type and IPPROTO_TCP (6) and IPPROTO_UDP (17) for the
+// protocol.
+//
+// Send and Recv should block until the operation is complete or the deadline
+// (if not zero) has passed, returning an error with a Timeout() bool method
+// that returns true in the latter case. Recv returns 0 bytes and no error when
+// the connection has been closed by the peer.
+//
+// The driver may be used from multiple goroutines at the same time. In
+// particular, a blocking Recv must not prevent other sockets from being used.
+//
+// Drivers may also implement the following optional methods:
+//
+//     // ConnectDeadline is like Connect, but gives up when the deadline (if
+//     // not zero) has passed. It is used instead of Connect if available.
+//     ConnectDeadline(sockfd int, host string, ip IP, port int, deadline time.Time) error
+//
+//     // Getsockname returns the local address of the socket, for example
+//     // the port chosen when binding to port 0.
+//     Getsockname(sockfd int) (ip IP, port int, err error)
+//
+//     // SendTo sends a datagram to the given address, for ListenPacket.
+//     SendTo(sockfd int, buf []byte, flags int, ip IP, port int, deadline time.Time) (int, error)
+//
+//     // RecvFrom receives a datagram and returns the address of the
+//     // sender, for ListenPacket.
+//     RecvFrom(sockfd int, buf []byte, flags int, deadline time.Time) (n int, ip IP, port int, err error)
+type Netdev interface {
+	// GetHostByName returns the IP address of the given host name.
+	GetHostByName(name string) (IP, error)
+
+	// Socket creates a new socket and returns its descriptor.
+	Socket(domain int, stype int, protocol int) (int, error)
+
+	// Bind assigns a local address to the socket.
+	Bind(sockfd int, ip IP, port int) error
+
+	// Connect connects the socket to the given address. The host name (if
+	// any) is passed as well, for drivers that do TLS or DNS resolution on
+	// the device.
+	Connect(sockfd int, host string, ip IP, port int) error
+
+	// Listen marks the socket as a listening socket.
+	Listen(sockfd int, backlog int) error
+
+	// Accept waits for an incoming connection on a listening socket and
+	// returns the new socket with the address of the peer.
+	Accept(sockfd int) (newfd int, ip IP, port int, err error)
+
+	// Send sends data on a connected socket.
+	Send(sockfd int, buf []byte, flags int, deadline time.Time) (int, error)
+
+	// Recv receives data from a connected socket.
+	Recv(sockfd int, buf []byte, flags int, deadline time.Time) (int, error)
+
+	// Close closes the socket.
+	Close(sockfd int) error
+}
+
+// netdevConnectDeadline is implemented by drivers that support a timeout while
+// connecting.
+type netdevConnectDeadline interface {
+	ConnectDeadline(sockfd int, host string, ip IP, port int, deadline time.Time) error
+}
+
+// netdevSockname is implemented by drivers that support Getsockname.
+type netdevSockname interface {
+	Getsockname(sockfd int) (ip IP, port int, err error)
+}
+
+// netdevPacket is implemented by drivers that support unconnected datagram
+// sockets.
+type netdevPacket interface {
+	SendTo(sockfd int, buf []byte, flags int, ip IP, port int, deadline time.Time) (int, error)
+	RecvFrom(sockfd int, buf []byte, flags int, deadline time.Time) (n int, ip IP, port int, err error)
+}
+
+// Socket constants, using the Linux values (see Netdev).
+const (
+	_AF_INET     = 2
+	_AF_INET6    = 10
+	_SOCK_STREAM = 1
+	_SOCK_DGRAM  = 2
+	_IPPROTO_TCP = 6
+	_IPPROTO_UDP = 17
+)
+
+// The currently registered network driver. On Linux and WASI, this is a driver
+// for the sockets of the operating system. Elsewhere, it is the loopback
+// driver until another driver is registered.
+var netdev Netdev = newDefaultNetdev()
+
+// UseNetdev registers the network driver used by the net package. It should
+// be called by the board or driver setup code before any network connection
+// is made. Until a driver is registered on baremetal systems, only the
+// loopback network is available.
+func UseNetdev(dev Netdev) {
+	netdev = dev
+}
@@ -0,0 +1,425 @@
+package net
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// loopback is a network driver that implements the loopback network (127.0.0.1
+// and ::1) entirely in memory. It is the default driver until a real driver is
+// registered with UseNetdev, which makes it possible to test network code
+// without any network hardware.
+type loopback struct {
+	mu       sync.Mutex
+	sockets  map[int]*loopbackSocket
+	bound    map[loopbackPort]*loopbackSocket
+	nextFD   int
+	nextPort int
+}
+
+// loopbackPort is a port number of a given socket type. TCP and UDP have
+// separate port numbers.
+type loopbackPort struct {
+	stype int
+	port  int
+}
+
+type loopbackSocket struct {
+	stype     int
+	ip        IP                // local IP address
+	port      int               // local port, or 0 if not bound
+	peerPort  int               // remote port (UDP)
+	peer      *loopbackSocket   // connected peer (TCP)
+	listening bool              // whether this is a listening socket (TCP)
+	backlog   int               // maximum number of pending connections
+	pending   []*loopbackSocket // connections waiting to be accepted
+	data      []byte            // received data (TCP)
+	packets   []loopbackPacket  // received datagrams (UDP)
+	connected bool
+	closed    bool          // closed locally
+	eof       bool          // closed by the peer
+	wake      chan struct{} // signaled when the state of the socket changes
+}
+
+// loopbackPacket is a datagram with the port of the sender.
+type loopbackPacket struct {
+	data []byte
+	port int
+}
+
+var (
+	errLoopbackUnreachable = errors.New("network is unreachable")
+	errLoopbackRefused     = errors.New("connection refused")
+	errLoopbackAddrInUse   = errors.New("address already in use")
+	errLoopbackAddr        = errors.New("cannot assign requested address")
+	errLoopbackNotConn     = errors.New("socket is not connected")
+	errLoopbackReset       = errors.New("connection reset by peer")
+	errLoopbackNoHost      = errors.New("no such host")
+	errLoopbackInvalid     = errors.New("invalid argument")
+)
+
+func newLoopback() *loopback {
+	return &loopback{
+		sockets:  make(map[int]*loopbackSocket),
+		bound:    make(map[loopbackPort]*loopbackSocket),
+		nextFD:   1,
+		nextPort: 49152, // start of the dynamic port range
+	}
+}
+
+// isLoopbackIP returns whether ip can be used as the address of a loopback
+// socket. A nil or unspecified IP means the local system.
+func isLoopbackIP(ip IP) bool {
+	return ip == nil || ip.IsUnspecified() || ip.IsLoopback()
+}
+
+func (l *loopback) GetHostByName(name string) (IP, error) {
+	if name == "localhost" {
+		return IPv4(127, 0, 0, 1), nil
+	}
+	return nil, errLoopbackNoHost
+}
+
+func (l *loopback) Socket(domain int, stype int, protocol int) (int, error) {
+	if stype != _SOCK_STREAM && stype != _SOCK_DGRAM {
+		return -1, errLoopbackInvalid
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fd := l.nextFD
+	l.nextFD++
+	l.sockets[fd] = &loopbackSocket{
+		stype: stype,
+		wake:  make(chan struct{}, 1),
+	}
+	return fd, nil
+}
+
+// get returns the socket with the given descriptor. It must be called with
+// the lock held.
+func (l *loopback) get(sockfd int) (*loopbackSocket, error) {
+	s := l.sockets[sockfd]
+	if s == nil || s.closed {
+		return nil, ErrClosed
+	}
+	return s, nil
+}
+
+// bind binds the socket to the given port, or to a free port if port is zero.
+// It must be called with the lock held.
+func (l *loopback) bind(s *loopbackSocket, port int) error {
+	if port == 0 {
+		for {
+			port = l.nextPort
+			l.nextPort++
+			if l.nextPort > 0xffff {
+				l.nextPort = 49152
+			}
+			if l.bound[loopbackPort{s.stype, port}] == nil {
+				break
+			}
+		}
+	} else if l.bound[loopbackPort{s.stype, port}] != nil {
+		return errLoopbackAddrInUse
+	}
+	s.port = port
+	l.bound[loopbackPort{s.stype, port}] = s
+	return nil
+}
+
+func (l *loopback) Bind(sockfd int, ip IP, port int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return err
+	}
+	if !isLoopbackIP(ip) {
+		return errLoopbackAddr
+	}
+	if s.port != 0 {
+		return errLoopbackInvalid
+	}
+	s.ip = ip
+	return l.bind(s, port)
+}
+
+func (l *loopback) Connect(sockfd int, host string, ip IP, port int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return err
+	}
+	if !isLoopbackIP(ip) {
+		return errLoopbackUnreachable
+	}
+	if s.connected || s.listening {
+		return errLoopbackInvalid
+	}
+	if s.port == 0 {
+		if err := l.bind(s, 0); err != nil {
+			return err
+		}
+	}
+	s.setLocalIP()
+	if s.stype == _SOCK_DGRAM {
+		// Datagrams are sent to whichever socket is bound to the port.
+		s.peerPort = port
+		s.connected = true
+		return nil
+	}
+
+	listener := l.bound[loopbackPort{_SOCK_STREAM, port}]
+	if listener == nil || !listener.listening || len(listener.pending) >= listener.backlog {
+		return errLoopbackRefused
+	}
+	// Create the other end of the connection, which will be returned by
+	// Accept.
+	server := &loopbackSocket{
+		stype:     _SOCK_STREAM,
+		port:      port,
+		peer:      s,
+		connected: true,
+		wake:      make(chan struct{}, 1),
+	}
+	s.peer = server
+	s.connected = true
+	listener.pending = append(listener.pending, server)
+	listener.notify()
+	return nil
+}
+
+func (l *loopback) Listen(sockfd int, backlog int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return err
+	}
+	if s.stype != _SOCK_STREAM || s.connected {
+		return errLoopbackInvalid
+	}
+	if s.port == 0 {
+		if err := l.bind(s, 0); err != nil {
+			return err
+		}
+	}
+	s.listening = true
+	s.backlog = backlog
+	return nil
+}
+
+func (l *loopback) Accept(sockfd int) (int, IP, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		s, err := l.get(sockfd)
+		if err != nil {
+			return -1, nil, 0, err
+		}
+		if !s.listening {
+			return -1, nil, 0, errLoopbackInvalid
+		}
+		if len(s.pending) != 0 {
+			conn := s.pending[0]
+			s.pending = s.pending[1:]
+			fd := l.nextFD
+			l.nextFD++
+			l.sockets[fd] = conn
+			return fd, IPv4(127, 0, 0, 1), conn.peer.port, nil
+		}
+		l.wait(s, time.Time{})
+	}
+}
+
+func (l *loopback) Send(sockfd int, buf []byte, flags int, deadline time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return 0, err
+	}
+	if !s.connected {
+		return 0, errLoopbackNotConn
+	}
+	if s.stype == _SOCK_DGRAM {
+		l.sendPacket(s, buf, s.peerPort)
+		return len(buf), nil
+	}
+	if s.peer.closed {
+		return 0, errLoopbackReset
+	}
+	s.peer.data = append(s.peer.data, buf...)
+	s.peer.notify()
+	return len(buf), nil
+}
+
+func (l *loopback) Recv(sockfd int, buf []byte, flags int, deadline time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		s, err := l.get(sockfd)
+		if err != nil {
+			return 0, err
+		}
+		if s.stype == _SOCK_DGRAM {
+			if len(s.packets) != 0 {
+				n := copy(buf, s.packets[0].data)
+				s.packets = s.packets[1:]
+				return n, nil
+			}
+		} else {
+			if !s.connected {
+				return 0, errLoopbackNotConn
+			}
+			if len(s.data) != 0 {
+				n := copy(buf, s.data)
+				s.data = s.data[n:]
+				return n, nil
+			}
+			if s.eof {
+				return 0, nil
+			}
+		}
+		if err := l.wait(s, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (l *loopback) Getsockname(sockfd int) (IP, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.ip, s.port, nil
+}
+
+func (l *loopback) SendTo(sockfd int, buf []byte, flags int, ip IP, port int, deadline time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return 0, err
+	}
+	if s.stype != _SOCK_DGRAM {
+		return 0, errLoopbackInvalid
+	}
+	if !isLoopbackIP(ip) {
+		return 0, errLoopbackUnreachable
+	}
+	if s.port == 0 {
+		if err := l.bind(s, 0); err != nil {
+			return 0, err
+		}
+	}
+	s.setLocalIP()
+	l.sendPacket(s, buf, port)
+	return len(buf), nil
+}
+
+func (l *loopback) RecvFrom(sockfd int, buf []byte, flags int, deadline time.Time) (int, IP, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		s, err := l.get(sockfd)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		if s.stype != _SOCK_DGRAM {
+			return 0, nil, 0, errLoopbackInvalid
+		}
+		if len(s.packets) != 0 {
+			p := s.packets[0]
+			s.packets = s.packets[1:]
+			return copy(buf, p.data), IPv4(127, 0, 0, 1), p.port, nil
+		}
+		if err := l.wait(s, deadline); err != nil {
+			return 0, nil, 0, err
+		}
+	}
+}
+
+// sendPacket delivers a datagram from s to the socket bound to the given port.
+// Datagrams to a port without socket are silently dropped. It must be called
+// with the lock held.
+func (l *loopback) sendPacket(s *loopbackSocket, buf []byte, port int) {
+	if dst := l.bound[loopbackPort{_SOCK_DGRAM, port}]; dst != nil {
+		dst.packets = append(dst.packets, loopbackPacket{
+			data: append([]byte(nil), buf...),
+			port: s.port,
+		})
+		dst.notify()
+	}
+}
+
+func (l *loopback) Close(sockfd int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, err := l.get(sockfd)
+	if err != nil {
+		return err
+	}
+	s.closed = true
+	delete(l.sockets, sockfd)
+	if s.port != 0 && l.bound[loopbackPort{s.stype, s.port}] == s {
+		delete(l.bound, loopbackPort{s.stype, s.port})
+	}
+	if s.peer != nil {
+		s.peer.eof = true
+		s.peer.notify()
+	}
+	for _, conn := range s.pending {
+		// Connections that were never accepted are closed as well.
+		conn.closed = true
+		conn.peer.eof = true
+		conn.peer.notify()
+	}
+	s.pending = nil
+	// Wake up goroutines blocked on this socket.
+	s.notify()
+	return nil
+}
+
+// setLocalIP sets the local IP address of a socket that communicates over the
+// loopback network, if it isn't bound to a specific address.
+func (s *loopbackSocket) setLocalIP() {
+	if s.ip == nil || s.ip.IsUnspecified() {
+		s.ip = IPv4(127, 0, 0, 1)
+	}
+}
+
+// notify wakes up a goroutine waiting for a change in the socket state.
+func (s *loopbackSocket) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// wait waits until the state of the socket changes or the deadline has passed.
+// It must be called with the lock held, which is released while waiting.
+func (l *loopback) wait(s *loopbackSocket, deadline time.Time) error {
+	l.mu.Unlock()
+	defer l.mu.Lock()
+	if deadline.IsZero() {
+		<-s.wake
+		return nil
+	}
+	// Poll until the deadline, as there are no timers to wait on.
+	for {
+		select {
+		case <-s.wake:
+			return nil
+		default:
+		}
+		if !time.Now().Before(deadline) {
+			return errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux || baremetal || nintendoswitch || netloopback
+// +build !linux baremetal nintendoswitch netloopback
+
+package net
+
+// The loopback driver is the default where there are no sockets. It can also
+// be selected on Linux with -tags=netloopback, to test it on a host.
+func newDefaultNetdev() Netdev {
+	return newLoopback()
+}
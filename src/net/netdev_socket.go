@@ -0,0 +1,352 @@
+//go:build linux && !baremetal && !nintendoswitch
+// +build linux,!baremetal,!nintendoswitch
+
+package net
+
+import (
+	"errors"
+	"internal/itoa"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// socketNetdev is the network driver for Linux and WASI hosts, which uses the
+// sockets of the operating system.
+//
+// All sockets are non-blocking. When an operation would block, the goroutine
+// is parked in the runtime until the socket is ready, so that other goroutines
+// can run in the meantime.
+//
+// Note that WASI cannot create sockets: only connections to sockets that were
+// preopened by the host can be accepted (see FileListener).
+type socketNetdev struct{}
+
+// Implemented in the runtime. It waits until fd is ready for reading (mode
+// 'r') or writing (mode 'w'), or until the timeout (in nanoseconds, negative
+// for no timeout) expires.
+func runtime_pollWait(fd, mode int, timeout int64) int
+
+// Implemented in the runtime. It wakes up the goroutines waiting for fd.
+func runtime_pollUnblock(fd int)
+
+// Return values of runtime_pollWait.
+const (
+	pollErrClosing = 1
+	pollErrTimeout = 2
+)
+
+var (
+	errNoSuchHost   = errors.New("no such host")
+	errTryAgainHost = errors.New("temporary failure in name resolution")
+)
+
+// wait waits until the socket is ready for reading (mode 'r') or writing (mode
+// 'w'), or until the deadline (if not zero) has passed.
+func (socketNetdev) wait(sockfd, mode int, deadline time.Time) error {
+	timeout := int64(-1)
+	if !deadline.IsZero() {
+		timeout = int64(time.Until(deadline))
+		if timeout < 0 {
+			timeout = 0
+		}
+	}
+	switch runtime_pollWait(sockfd, mode, timeout) {
+	case pollErrClosing:
+		return ErrClosed
+	case pollErrTimeout:
+		return errTimeout
+	}
+	return nil
+}
+
+// struct addrinfo, as defined by musl.
+type addrinfo struct {
+	flags     int32
+	family    int32
+	socktype  int32
+	protocol  int32
+	addrlen   uint32
+	addr      unsafe.Pointer
+	canonname *byte
+	next      *addrinfo
+}
+
+// Error codes returned by getaddrinfo in musl.
+const (
+	eaiNoName = -2
+	eaiAgain  = -3
+	eaiNoData = -5
+)
+
+// int getaddrinfo(const char *node, const char *service, const struct addrinfo *hints, struct addrinfo **res);
+//export getaddrinfo
+func libc_getaddrinfo(node *byte, service *byte, hints *addrinfo, res **addrinfo) int32
+
+// void freeaddrinfo(struct addrinfo *res);
+//export freeaddrinfo
+func libc_freeaddrinfo(res *addrinfo)
+
+// GetHostByName resolves the name with getaddrinfo from musl, which looks in
+// /etc/hosts and then asks the name servers in /etc/resolv.conf. The first
+// IPv4 address is returned, or the first IPv6 address if there is none.
+//
+// The lookup is done in a blocking call, so no other goroutine runs until it
+// has finished.
+func (socketNetdev) GetHostByName(name string) (IP, error) {
+	node := make([]byte, len(name)+1)
+	copy(node, name)
+	hints := addrinfo{
+		family:   syscall.AF_UNSPEC,
+		socktype: syscall.SOCK_STREAM,
+	}
+	var res *addrinfo
+	switch ret := libc_getaddrinfo(&node[0], nil, &hints, &res); ret {
+	case 0:
+	case eaiNoName, eaiNoData:
+		return nil, errNoSuchHost
+	case eaiAgain:
+		return nil, errTryAgainHost
+	default:
+		return nil, errors.New("getaddrinfo failed with error " + itoa.Itoa(int(ret)))
+	}
+	defer libc_freeaddrinfo(res)
+
+	var ip IP
+	for ai := res; ai != nil; ai = ai.next {
+		switch ai.family {
+		case syscall.AF_INET:
+			// The address is at offset 4 of struct sockaddr_in.
+			addr := (*[4]byte)(unsafe.Pointer(uintptr(ai.addr) + 4))
+			return IPv4(addr[0], addr[1], addr[2], addr[3]), nil
+		case syscall.AF_INET6:
+			// The address is at offset 8 of struct sockaddr_in6.
+			if ip == nil {
+				addr := (*[16]byte)(unsafe.Pointer(uintptr(ai.addr) + 8))
+				ip = make(IP, IPv6len)
+				copy(ip, addr[:])
+			}
+		}
+	}
+	if ip == nil {
+		return nil, errNoSuchHost
+	}
+	return ip, nil
+}
+
+func (socketNetdev) Socket(domain int, stype int, protocol int) (int, error) {
+	switch domain {
+	case _AF_INET:
+		domain = syscall.AF_INET
+	case _AF_INET6:
+		domain = syscall.AF_INET6
+	default:
+		return -1, syscall.EAFNOSUPPORT
+	}
+	switch stype {
+	case _SOCK_STREAM:
+		stype = syscall.SOCK_STREAM
+	case _SOCK_DGRAM:
+		stype = syscall.SOCK_DGRAM
+	default:
+		return -1, syscall.EPROTONOSUPPORT
+	}
+	fd, err := syscall.Socket(domain, stype|syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC, protocol)
+	if err != nil {
+		return -1, os.NewSyscallError("socket", err)
+	}
+	if stype == syscall.SOCK_STREAM {
+		// Allow listening on a port again right after the previous listener
+		// was closed, like upstream Go.
+		syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	}
+	return fd, nil
+}
+
+// sockaddr converts ip and port to a socket address in the address family of
+// the given socket.
+func (socketNetdev) sockaddr(sockfd int, ip IP, port int) (syscall.Sockaddr, error) {
+	sa, err := syscall.Getsockname(sockfd)
+	if err != nil {
+		return nil, os.NewSyscallError("getsockname", err)
+	}
+	if _, ok := sa.(*syscall.SockaddrInet6); ok {
+		sa6 := &syscall.SockaddrInet6{Port: port}
+		if ip != nil {
+			copy(sa6.Addr[:], ip.To16())
+		}
+		return sa6, nil
+	}
+	sa4 := &syscall.SockaddrInet4{Port: port}
+	if ip != nil {
+		ip4 := ip.To4()
+		if ip4 == nil {
+			return nil, &AddrError{Err: "non-IPv4 address", Addr: ip.String()}
+		}
+		copy(sa4.Addr[:], ip4)
+	}
+	return sa4, nil
+}
+
+// fromSockaddr converts a socket address to an IP address and port.
+func fromSockaddr(sa syscall.Sockaddr) (IP, int) {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return IPv4(sa.Addr[0], sa.Addr[1], sa.Addr[2], sa.Addr[3]), sa.Port
+	case *syscall.SockaddrInet6:
+		ip := make(IP, IPv6len)
+		copy(ip, sa.Addr[:])
+		return ip, sa.Port
+	}
+	return nil, 0
+}
+
+func (d socketNetdev) Bind(sockfd int, ip IP, port int) error {
+	sa, err := d.sockaddr(sockfd, ip, port)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Bind(sockfd, sa); err != nil {
+		return os.NewSyscallError("bind", err)
+	}
+	return nil
+}
+
+func (d socketNetdev) Connect(sockfd int, host string, ip IP, port int) error {
+	return d.ConnectDeadline(sockfd, host, ip, port, time.Time{})
+}
+
+func (d socketNetdev) ConnectDeadline(sockfd int, host string, ip IP, port int, deadline time.Time) error {
+	sa, err := d.sockaddr(sockfd, ip, port)
+	if err != nil {
+		return err
+	}
+	err = syscall.Connect(sockfd, sa)
+	for err == syscall.EINTR {
+		err = syscall.Connect(sockfd, sa)
+	}
+	if err == syscall.EINPROGRESS {
+		// Wait until the connection attempt has finished, and then check
+		// whether it succeeded.
+		if err := d.wait(sockfd, 'w', deadline); err != nil {
+			return err
+		}
+		var errno int
+		errno, err = syscall.GetsockoptInt(sockfd, syscall.SOL_SOCKET, syscall.SO_ERROR)
+		if err == nil && errno != 0 {
+			err = syscall.Errno(errno)
+		}
+	}
+	if err != nil {
+		return os.NewSyscallError("connect", err)
+	}
+	return nil
+}
+
+func (socketNetdev) Listen(sockfd int, backlog int) error {
+	if err := syscall.Listen(sockfd, backlog); err != nil {
+		return os.NewSyscallError("listen", err)
+	}
+	return nil
+}
+
+func (d socketNetdev) Accept(sockfd int) (int, IP, int, error) {
+	for {
+		fd, sa, err := syscall.Accept(sockfd)
+		if err == nil {
+			syscall.CloseOnExec(fd)
+			if err := syscall.SetNonblock(fd, true); err != nil {
+				syscall.Close(fd)
+				return -1, nil, 0, os.NewSyscallError("setnonblock", err)
+			}
+			ip, port := fromSockaddr(sa)
+			return fd, ip, port, nil
+		}
+		switch err {
+		case syscall.EINTR, syscall.ECONNABORTED:
+			// Try again.
+		case syscall.EAGAIN:
+			if err := d.wait(sockfd, 'r', time.Time{}); err != nil {
+				return -1, nil, 0, err
+			}
+		default:
+			return -1, nil, 0, os.NewSyscallError("accept", err)
+		}
+	}
+}
+
+func (d socketNetdev) Send(sockfd int, buf []byte, flags int, deadline time.Time) (int, error) {
+	return d.SendTo(sockfd, buf, flags, nil, 0, deadline)
+}
+
+func (d socketNetdev) Recv(sockfd int, buf []byte, flags int, deadline time.Time) (int, error) {
+	n, _, _, err := d.RecvFrom(sockfd, buf, flags, deadline)
+	return n, err
+}
+
+// SendTo sends data to the given address, or to the connected peer if ip is
+// nil.
+func (d socketNetdev) SendTo(sockfd int, buf []byte, flags int, ip IP, port int, deadline time.Time) (int, error) {
+	var to syscall.Sockaddr
+	if ip != nil {
+		var err error
+		to, err = d.sockaddr(sockfd, ip, port)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for {
+		// Don't raise SIGPIPE when the connection was closed by the peer.
+		n, err := syscall.SendmsgN(sockfd, buf, nil, to, flags|syscall.MSG_NOSIGNAL)
+		switch err {
+		case nil:
+			return n, nil
+		case syscall.EINTR:
+			// Try again.
+		case syscall.EAGAIN:
+			if err := d.wait(sockfd, 'w', deadline); err != nil {
+				return 0, err
+			}
+		default:
+			return 0, os.NewSyscallError("write", err)
+		}
+	}
+}
+
+func (d socketNetdev) RecvFrom(sockfd int, buf []byte, flags int, deadline time.Time) (int, IP, int, error) {
+	for {
+		n, sa, err := syscall.Recvfrom(sockfd, buf, flags)
+		switch err {
+		case nil:
+			ip, port := fromSockaddr(sa)
+			return n, ip, port, nil
+		case syscall.EINTR:
+			// Try again.
+		case syscall.EAGAIN:
+			if err := d.wait(sockfd, 'r', deadline); err != nil {
+				return 0, nil, 0, err
+			}
+		default:
+			return 0, nil, 0, os.NewSyscallError("read", err)
+		}
+	}
+}
+
+func (socketNetdev) Getsockname(sockfd int) (IP, int, error) {
+	sa, err := syscall.Getsockname(sockfd)
+	if err != nil {
+		return nil, 0, os.NewSyscallError("getsockname", err)
+	}
+	ip, port := fromSockaddr(sa)
+	return ip, port, nil
+}
+
+func (socketNetdev) Close(sockfd int) error {
+	// Goroutines that are blocked on this socket return ErrClosed.
+	runtime_pollUnblock(sockfd)
+	if err := syscall.Close(sockfd); err != nil {
+		return os.NewSyscallError("close", err)
+	}
+	return nil
+}
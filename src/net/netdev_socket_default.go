@@ -0,0 +1,8 @@
+//go:build linux && !baremetal && !nintendoswitch && !netloopback
+// +build linux,!baremetal,!nintendoswitch,!netloopback
+
+package net
+
+func newDefaultNetdev() Netdev {
+	return socketNetdev{}
+}
@@ -16,6 +16,9 @@ var (
 	ErrNotImplemented = errors.New("operation not implemented")
 	ErrNotExist       = errors.New("file not found")
 	ErrExist          = errors.New("file exists")
+
+	// ErrProcessDone indicates a Process has finished.
+	ErrProcessDone = errors.New("os: process already finished")
 )
 
 // The following code is copied from the official implementation.
@@ -24,45 +24,47 @@ type ProcAttr struct {
 	Sys   *syscall.SysProcAttr
 }
 
-type ProcessState struct {
-}
-
-func (p *ProcessState) String() string {
-	return "" // TODO
-}
-func (p *ProcessState) Success() bool {
-	return false // TODO
-}
-
-// Sys returns system-dependent exit information about
-// the process. Convert it to the appropriate underlying
-// type, such as syscall.WaitStatus on Unix, to access its contents.
-func (p *ProcessState) Sys() interface{} {
-	return nil // TODO
-}
-
-// ExitCode returns the exit code of the exited process, or -1
-// if the process hasn't exited or was terminated by a signal.
-func (p *ProcessState) ExitCode() int {
-	return -1 // TODO
-}
-
+// Process stores the information about a process created by StartProcess.
 type Process struct {
-	Pid int
+	Pid  int
+	done bool // set when the process has been waited for
 }
 
+// StartProcess starts a new process with the program, arguments and attributes
+// specified by name, argv and attr. The argv slice will become os.Args in the
+// new process, so it normally starts with the program name.
+//
+// StartProcess is a low-level interface. The os/exec package provides
+// higher-level interfaces.
+//
+// If there is an error, it will be of type *PathError.
 func StartProcess(name string, argv []string, attr *ProcAttr) (*Process, error) {
-	return nil, &PathError{"fork/exec", name, ErrNotImplemented}
+	return startProcess(name, argv, attr)
 }
 
-func (p *Process) Wait() (*ProcessState, error) {
-	return nil, ErrNotImplemented
+// Release releases any resources associated with the Process p,
+// rendering it unusable in the future.
+// Release only needs to be called if Wait is not.
+func (p *Process) Release() error {
+	p.Pid = -1
+	return nil
 }
 
+// Kill causes the Process to exit immediately. Kill does not wait until
+// the Process has actually exited. This only kills the Process itself,
+// not any other processes it may have started.
 func (p *Process) Kill() error {
-	return ErrNotImplemented
+	return p.Signal(Kill)
+}
+
+// Wait waits for the Process to exit, and then returns a
+// ProcessState describing its status and an error, if any.
+// Wait releases any resources associated with the Process.
+func (p *Process) Wait() (*ProcessState, error) {
+	return p.wait()
 }
 
+// Signal sends a signal to the Process.
 func (p *Process) Signal(sig Signal) error {
-	return ErrNotImplemented
+	return p.signal(sig)
 }
@@ -0,0 +1,293 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi
+// +build linux,!baremetal,!nintendoswitch,!wasi
+
+package os
+
+// Processes are started using posix_spawn from musl, which uses vfork under
+// the hood. This avoids having to run Go code in the child process between
+// fork and exec.
+
+import (
+	"errors"
+	"internal/itoa"
+	"syscall"
+	"unsafe"
+)
+
+// ProcessState stores information about a process, as reported by Wait.
+type ProcessState struct {
+	pid    int                // The process's id.
+	status syscall.WaitStatus // System-dependent status info.
+	rusage *syscall.Rusage
+}
+
+// Pid returns the process id of the exited process.
+func (p *ProcessState) Pid() int {
+	return p.pid
+}
+
+// Exited reports whether the program has exited.
+// On Unix systems this reports true if the program exited due to calling exit,
+// but false if the program terminated due to a signal.
+func (p *ProcessState) Exited() bool {
+	return p.status.Exited()
+}
+
+// Success reports whether the program exited successfully,
+// such as with exit status 0 on Unix.
+func (p *ProcessState) Success() bool {
+	return p.status.ExitStatus() == 0
+}
+
+// Sys returns system-dependent exit information about
+// the process. Convert it to the appropriate underlying
+// type, such as syscall.WaitStatus on Unix, to access its contents.
+func (p *ProcessState) Sys() interface{} {
+	return p.status
+}
+
+// SysUsage returns system-dependent resource usage information about
+// the exited process. Convert it to the appropriate underlying
+// type, such as *syscall.Rusage on Unix, to access its contents.
+// (On Unix, *syscall.Rusage matches struct rusage as defined in the
+// getrusage(2) manual page.)
+func (p *ProcessState) SysUsage() interface{} {
+	return p.rusage
+}
+
+// ExitCode returns the exit code of the exited process, or -1
+// if the process hasn't exited or was terminated by a signal.
+func (p *ProcessState) ExitCode() int {
+	// return -1 if the process hasn't started.
+	if p == nil {
+		return -1
+	}
+	return p.status.ExitStatus()
+}
+
+func (p *ProcessState) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	status := p.status
+	res := ""
+	switch {
+	case status.Exited():
+		res = "exit status " + itoa.Itoa(status.ExitStatus())
+	case status.Signaled():
+		res = "signal: " + status.Signal().String()
+	case status.Stopped():
+		res = "stop signal: " + status.StopSignal().String()
+		if status.StopSignal() == syscall.SIGTRAP && status.TrapCause() != 0 {
+			res += " (trap " + itoa.Itoa(status.TrapCause()) + ")"
+		}
+	case status.Continued():
+		res = "continued"
+	}
+	if status.CoreDump() {
+		res += " (core dumped)"
+	}
+	return res
+}
+
+// The pidfd_open system call number, which is the same on all architectures.
+const _SYS_PIDFD_OPEN = 434
+
+// posix_spawn_file_actions_t is only used through pointers, so only the size
+// and alignment matter. The musl type is 80 bytes on 64-bit systems and 76
+// bytes on 32-bit systems.
+type spawnFileActions [10]uint64
+
+// int posix_spawn(pid_t *pid, const char *path, const posix_spawn_file_actions_t *fa, const posix_spawnattr_t *attr, char *const argv[], char *const envp[]);
+//export posix_spawn
+func libc_posix_spawn(pid *int32, path *byte, fa *spawnFileActions, attr unsafe.Pointer, argv **byte, envp **byte) int32
+
+// int posix_spawn_file_actions_init(posix_spawn_file_actions_t *fa);
+//export posix_spawn_file_actions_init
+func libc_posix_spawn_file_actions_init(fa *spawnFileActions) int32
+
+// int posix_spawn_file_actions_destroy(posix_spawn_file_actions_t *fa);
+//export posix_spawn_file_actions_destroy
+func libc_posix_spawn_file_actions_destroy(fa *spawnFileActions) int32
+
+// int posix_spawn_file_actions_adddup2(posix_spawn_file_actions_t *fa, int srcfd, int fd);
+//export posix_spawn_file_actions_adddup2
+func libc_posix_spawn_file_actions_adddup2(fa *spawnFileActions, srcfd, fd int32) int32
+
+// int posix_spawn_file_actions_addclose(posix_spawn_file_actions_t *fa, int fd);
+//export posix_spawn_file_actions_addclose
+func libc_posix_spawn_file_actions_addclose(fa *spawnFileActions, fd int32) int32
+
+// int posix_spawn_file_actions_addchdir_np(posix_spawn_file_actions_t *fa, const char *path);
+//export posix_spawn_file_actions_addchdir_np
+func libc_posix_spawn_file_actions_addchdir_np(fa *spawnFileActions, path *byte) int32
+
+// startProcess starts a new process with posix_spawn. Note that attr.Sys is
+// ignored, as posix_spawn supports few of its options.
+func startProcess(name string, argv []string, attr *ProcAttr) (*Process, error) {
+	if attr == nil {
+		attr = &ProcAttr{}
+	}
+
+	// Double-check existence of the directory we want to chdir into. We can
+	// make the error clearer this way.
+	if attr.Dir != "" {
+		if _, err := Stat(attr.Dir); err != nil {
+			if pe, ok := err.(*PathError); ok {
+				pe.Op = "chdir"
+			}
+			return nil, err
+		}
+	}
+
+	env := attr.Env
+	if env == nil {
+		env = Environ()
+	}
+	path, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, &PathError{"fork/exec", name, err}
+	}
+	argvp, err := cstringArray(argv)
+	if err != nil {
+		return nil, &PathError{"fork/exec", name, err}
+	}
+	envp, err := cstringArray(env)
+	if err != nil {
+		return nil, &PathError{"fork/exec", name, err}
+	}
+
+	// File descriptors that would be overwritten by an earlier dup2 in the
+	// child are first moved out of the way. They are closed in the parent
+	// afterwards; in the child they are closed on exec.
+	fds := make([]int, len(attr.Files))
+	for i, f := range attr.Files {
+		fds[i] = -1
+		if f == nil {
+			continue
+		}
+		fds[i] = int(f.Fd())
+		if fds[i] < len(fds) && fds[i] != i {
+			fd, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fds[i]), syscall.F_DUPFD_CLOEXEC, uintptr(len(fds)))
+			if errno != 0 {
+				return nil, &PathError{"fork/exec", name, errno}
+			}
+			defer syscall.Close(int(fd))
+			fds[i] = int(fd)
+		}
+	}
+
+	var fa spawnFileActions
+	if errno := libc_posix_spawn_file_actions_init(&fa); errno != 0 {
+		return nil, &PathError{"fork/exec", name, syscall.Errno(errno)}
+	}
+	defer libc_posix_spawn_file_actions_destroy(&fa)
+	for i, fd := range fds {
+		var errno int32
+		if fd < 0 {
+			errno = libc_posix_spawn_file_actions_addclose(&fa, int32(i))
+		} else {
+			// This also clears the close-on-exec flag if fd == i.
+			errno = libc_posix_spawn_file_actions_adddup2(&fa, int32(fd), int32(i))
+		}
+		if errno != 0 {
+			return nil, &PathError{"fork/exec", name, syscall.Errno(errno)}
+		}
+	}
+	// Like upstream Go, close stdin, stdout and stderr in the child if they
+	// weren't passed explicitly.
+	for i := len(fds); i < 3; i++ {
+		if errno := libc_posix_spawn_file_actions_addclose(&fa, int32(i)); errno != 0 {
+			return nil, &PathError{"fork/exec", name, syscall.Errno(errno)}
+		}
+	}
+	if attr.Dir != "" {
+		dir, err := syscall.BytePtrFromString(attr.Dir)
+		if err != nil {
+			return nil, &PathError{"fork/exec", name, err}
+		}
+		if errno := libc_posix_spawn_file_actions_addchdir_np(&fa, dir); errno != 0 {
+			return nil, &PathError{"fork/exec", name, syscall.Errno(errno)}
+		}
+	}
+
+	var pid int32
+	if errno := libc_posix_spawn(&pid, path, &fa, nil, &argvp[0], &envp[0]); errno != 0 {
+		return nil, &PathError{"fork/exec", name, syscall.Errno(errno)}
+	}
+	return &Process{Pid: int(pid)}, nil
+}
+
+// cstringArray converts a list of strings to a NULL-terminated array of C
+// strings.
+func cstringArray(list []string) ([]*byte, error) {
+	array := make([]*byte, len(list)+1)
+	for i, s := range list {
+		p, err := syscall.BytePtrFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		array[i] = p
+	}
+	return array, nil
+}
+
+func (p *Process) wait() (*ProcessState, error) {
+	if p.Pid == -1 {
+		return nil, syscall.EINVAL
+	}
+
+	// Let other goroutines run until the process has exited, if the kernel
+	// supports pidfds (Linux 5.3 and later). Otherwise wait4 below blocks the
+	// whole program.
+	pidfd, _, errno := syscall.Syscall(_SYS_PIDFD_OPEN, uintptr(p.Pid), 0, 0)
+	if errno == 0 {
+		runtime_pollWait(int(pidfd), 'r')
+		syscall.Close(int(pidfd))
+	}
+
+	var status syscall.WaitStatus
+	var rusage syscall.Rusage
+	var pid int
+	var err error
+	for {
+		pid, err = syscall.Wait4(p.Pid, &status, 0, &rusage)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		return nil, NewSyscallError("wait", err)
+	}
+	if pid != 0 {
+		p.done = true
+	}
+	return &ProcessState{
+		pid:    pid,
+		status: status,
+		rusage: &rusage,
+	}, nil
+}
+
+func (p *Process) signal(sig Signal) error {
+	if p.Pid == -1 {
+		return errors.New("os: process already released")
+	}
+	if p.Pid == 0 {
+		return errors.New("os: process not initialized")
+	}
+	if p.done {
+		return ErrProcessDone
+	}
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return errors.New("os: unsupported signal type")
+	}
+	if err := syscall.Kill(p.Pid, s); err != nil {
+		if err == syscall.ESRCH {
+			return ErrProcessDone
+		}
+		return NewSyscallError("kill", err)
+	}
+	return nil
+}
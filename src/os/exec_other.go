@@ -0,0 +1,65 @@
+//go:build !linux || baremetal || nintendoswitch || wasi
+// +build !linux baremetal nintendoswitch wasi
+
+package os
+
+// Starting processes is not supported on this system: StartProcess, Wait and
+// Signal return an error wrapping ErrUnsupported.
+
+// ProcessState stores information about a process, as reported by Wait. As
+// processes cannot be started on this system, a ProcessState is never
+// returned by Wait and it only describes a process that never ran.
+type ProcessState struct{}
+
+// Pid returns the process id of the exited process, which is always -1 on this
+// system.
+func (p *ProcessState) Pid() int {
+	return -1
+}
+
+// Exited reports whether the program has exited.
+func (p *ProcessState) Exited() bool {
+	return false
+}
+
+// Success reports whether the program exited successfully.
+func (p *ProcessState) Success() bool {
+	return false
+}
+
+// Sys returns system-dependent exit information about the process, which is
+// not available on this system.
+func (p *ProcessState) Sys() interface{} {
+	return nil
+}
+
+// SysUsage returns system-dependent resource usage information about the
+// exited process, which is not available on this system.
+func (p *ProcessState) SysUsage() interface{} {
+	return nil
+}
+
+// ExitCode returns the exit code of the exited process, or -1 if the process
+// hasn't exited or was terminated by a signal. It is always -1 on this system.
+func (p *ProcessState) ExitCode() int {
+	return -1
+}
+
+func (p *ProcessState) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return "not started"
+}
+
+func startProcess(name string, argv []string, attr *ProcAttr) (*Process, error) {
+	return nil, &PathError{Op: "fork/exec", Path: name, Err: ErrUnsupported}
+}
+
+func (p *Process) wait() (*ProcessState, error) {
+	return nil, NewSyscallError("wait", ErrUnsupported)
+}
+
+func (p *Process) signal(sig Signal) error {
+	return NewSyscallError("kill", ErrUnsupported)
+}
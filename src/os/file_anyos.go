@@ -86,7 +86,8 @@ func (fs unixFilesystem) Remove(path string) error {
 }
 
 func (fs unixFilesystem) OpenFile(path string, flag int, perm FileMode) (uintptr, error) {
-	fp, err := syscall.Open(path, flag, uint32(perm))
+	// Files must not leak into processes started with StartProcess.
+	fp, err := syscall.Open(path, flag|syscall.O_CLOEXEC, uint32(perm))
 	return uintptr(fp), handleSyscallError(err)
 }
 
@@ -97,6 +98,7 @@ type unixFileHandle uintptr
 // Read reads up to len(b) bytes from the File. It returns the number of bytes
 // read and any error encountered. At end of file, Read returns 0, io.EOF.
 func (f unixFileHandle) Read(b []byte) (n int, err error) {
+	f.waitRead()
 	n, err = syscall.Read(syscallFd(f), b)
 	err = handleSyscallError(err)
 	if n == 0 && len(b) > 0 && err == nil {
@@ -108,6 +110,7 @@ func (f unixFileHandle) Read(b []byte) (n int, err error) {
 // Write writes len(b) bytes to the File. It returns the number of bytes written
 // and an error, if any. Write returns a non-nil error when n != len(b).
 func (f unixFileHandle) Write(b []byte) (n int, err error) {
+	f.waitWrite()
 	n, err = syscall.Write(syscallFd(f), b)
 	err = handleSyscallError(err)
 	return
@@ -0,0 +1,22 @@
+//go:build linux && !baremetal && !nintendoswitch
+// +build linux,!baremetal,!nintendoswitch
+
+package os
+
+// runtime_pollWait waits until fd is ready for reading (mode 'r') or writing
+// (mode 'w'), letting other goroutines run in the meantime. It returns
+// immediately when the file descriptor cannot be polled, or when there are no
+// other goroutines that could run.
+//
+// Implemented in the runtime.
+func runtime_pollWait(fd, mode int)
+
+// waitRead waits until a read from the file won't block.
+func (f unixFileHandle) waitRead() {
+	runtime_pollWait(int(f), 'r')
+}
+
+// waitWrite waits until a write to the file won't block.
+func (f unixFileHandle) waitWrite() {
+	runtime_pollWait(int(f), 'w')
+}
@@ -0,0 +1,14 @@
+//go:build !baremetal && !js && (!linux || nintendoswitch)
+// +build !baremetal
+// +build !js
+// +build !linux nintendoswitch
+
+package os
+
+// Reads and writes simply block on this system, see file_poll.go.
+
+func (f unixFileHandle) waitRead() {
+}
+
+func (f unixFileHandle) waitWrite() {
+}
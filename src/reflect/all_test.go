@@ -53,8 +53,6 @@ func init() {
 	cycleMap3["different"] = cycleMap3
 }
 
-// Note: all tests involving maps have been commented out because they aren't
-// supported yet.
 var deepEqualTests = []DeepEqualTest{
 	// Equalities
 	{nil, nil, true},
@@ -67,7 +65,7 @@ var deepEqualTests = []DeepEqualTest{
 	{&[3]int{1, 2, 3}, &[3]int{1, 2, 3}, true},
 	{Basic{1, 0.5}, Basic{1, 0.5}, true},
 	{error(nil), error(nil), true},
-	//{map[int]string{1: "one", 2: "two"}, map[int]string{2: "two", 1: "one"}, true},
+	{map[int]string{1: "one", 2: "two"}, map[int]string{2: "two", 1: "one"}, true},
 	{fn1, fn2, true},
 	{[]byte{1, 2, 3}, []byte{1, 2, 3}, true},
 	{[]MyByte{1, 2, 3}, []MyByte{1, 2, 3}, true},
@@ -83,10 +81,10 @@ var deepEqualTests = []DeepEqualTest{
 	{&[3]int{1, 2, 3}, &[3]int{1, 2, 4}, false},
 	{Basic{1, 0.5}, Basic{1, 0.6}, false},
 	{Basic{1, 0}, Basic{2, 0}, false},
-	//{map[int]string{1: "one", 3: "two"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{1: "one", 2: "txo"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{1: "one"}, map[int]string{2: "two", 1: "one"}, false},
-	//{map[int]string{2: "two", 1: "one"}, map[int]string{1: "one"}, false},
+	{map[int]string{1: "one", 3: "two"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{1: "one", 2: "txo"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{1: "one"}, map[int]string{2: "two", 1: "one"}, false},
+	{map[int]string{2: "two", 1: "one"}, map[int]string{1: "one"}, false},
 	{nil, 1, false},
 	{1, nil, false},
 	{fn1, fn3, false},
@@ -100,16 +98,16 @@ var deepEqualTests = []DeepEqualTest{
 	{&[1]float64{math.NaN()}, self{}, true},
 	{[]float64{math.NaN()}, []float64{math.NaN()}, false},
 	{[]float64{math.NaN()}, self{}, true},
-	//{map[float64]float64{math.NaN(): 1}, map[float64]float64{1: 2}, false},
-	//{map[float64]float64{math.NaN(): 1}, self{}, true},
+	{map[float64]float64{math.NaN(): 1}, map[float64]float64{1: 2}, false},
+	{map[float64]float64{math.NaN(): 1}, self{}, true},
 
 	// Nil vs empty: not the same.
 	{[]int{}, []int(nil), false},
 	{[]int{}, []int{}, true},
 	{[]int(nil), []int(nil), true},
-	//{map[int]int{}, map[int]int(nil), false},
-	//{map[int]int{}, map[int]int{}, true},
-	//{map[int]int(nil), map[int]int(nil), true},
+	{map[int]int{}, map[int]int(nil), false},
+	{map[int]int{}, map[int]int{}, true},
+	{map[int]int(nil), map[int]int(nil), true},
 
 	// Mismatched types
 	{1, 1.0, false},
@@ -126,8 +124,8 @@ var deepEqualTests = []DeepEqualTest{
 	// Possible loops.
 	{&loopy1, &loopy1, true},
 	{&loopy1, &loopy2, true},
-	//{&cycleMap1, &cycleMap2, true},
-	//{&cycleMap1, &cycleMap3, false},
+	{&cycleMap1, &cycleMap2, true},
+	{&cycleMap1, &cycleMap3, false},
 }
 
 func TestDeepEqual(t *testing.T) {
@@ -186,3 +184,160 @@ func TestDeepEqualComplexStructInequality(t *testing.T) {
 
 type MyBytes []byte
 type MyByte byte
+
+type MyInt int
+type MyString string
+type MyRunes []rune
+
+var convertTests = []struct {
+	in  interface{}
+	out interface{}
+}{
+	// numbers
+	{int8(-3), int(-3)},
+	{int(-1), uint8(255)},
+	{uint16(300), int8(44)},
+	{int64(1 << 40), uint32(0)},
+	{uint64(1 << 60), int64(1 << 60)},
+	{float64(3.9), int(3)},
+	{float64(-3.9), int16(-3)},
+	{float32(250.5), uint8(250)},
+	{int(-7), float32(-7)},
+	{uint64(1 << 53), float64(1 << 53)},
+	{float64(1.5), float32(1.5)},
+	{complex64(1 + 2i), complex128(1 + 2i)},
+	{complex128(3 - 4i), complex64(3 - 4i)},
+
+	// named types
+	{int(5), MyInt(5)},
+	{MyInt(-5), int64(-5)},
+	{"foo", MyString("foo")},
+	{MyString("bar"), "bar"},
+	{Basic{1, 0.5}, NotBasic{1, 0.5}},
+	{MyBytes{1, 2}, []byte{1, 2}},
+
+	// strings
+	{int(65), "A"},
+	{uint32(0x4e16), "世"},
+	{int(-1), "�"},
+	{[]byte("hello"), "hello"},
+	{[]rune("wörld"), "wörld"},
+	{MyRunes{'x', 'y'}, "xy"},
+	{"bytes", []byte("bytes")},
+	{"rünes", []rune("rünes")},
+	{"named", MyBytes("named")},
+}
+
+func TestConvert(t *testing.T) {
+	for i, tc := range convertTests {
+		in := ValueOf(tc.in)
+		outType := TypeOf(tc.out)
+		if !in.Type().ConvertibleTo(outType) {
+			t.Errorf("test %d: expected type to be convertible", i)
+			continue
+		}
+		out := in.Convert(outType)
+		if out.Type() != outType {
+			t.Errorf("test %d: converted value has the wrong type", i)
+		}
+		if !DeepEqual(out.Interface(), tc.out) {
+			t.Errorf("test %d: got %#v, want %#v", i, out.Interface(), tc.out)
+		}
+	}
+}
+
+type MyAny interface{}
+
+func TestConvertInterface(t *testing.T) {
+	for _, itfType := range []Type{
+		TypeOf((*interface{})(nil)).Elem(),
+		TypeOf((*MyAny)(nil)).Elem(),
+	} {
+		if !TypeOf(MyInt(0)).ConvertibleTo(itfType) {
+			t.Errorf("expected MyInt to be convertible to %s", itfType)
+		}
+		v := ValueOf(MyInt(3)).Convert(itfType)
+		if v.Kind() != Interface {
+			t.Errorf("converted value has kind %s, want interface", v.Kind())
+		}
+		if v.Elem().Type() != TypeOf(MyInt(0)) || v.Elem().Int() != 3 {
+			t.Errorf("converted value has the wrong contents")
+		}
+	}
+}
+
+type MyArray [4]int
+
+func TestConvertCopy(t *testing.T) {
+	a := [4]int{1, 2, 3, 4}
+	v := ValueOf(&a).Elem()
+	c := v.Convert(TypeOf(MyArray{}))
+	v.Index(0).SetInt(5)
+	if c.Index(0).Int() != 1 {
+		t.Errorf("converted value shares memory with the original value")
+	}
+}
+
+func TestNotConvertible(t *testing.T) {
+	for i, tc := range []struct {
+		in  interface{}
+		out interface{}
+	}{
+		{"foo", 5},
+		{1.5, "x"},
+		{[]int{1}, "x"},
+		{[]byte{1}, []int{1}},
+		{Basic{}, 5},
+		{true, 1},
+		{complex64(1), 1.0},
+	} {
+		if TypeOf(tc.in).ConvertibleTo(TypeOf(tc.out)) {
+			t.Errorf("test %d: expected type not to be convertible", i)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	if ovf := ValueOf(float64(0)).OverflowFloat(1e300); ovf {
+		t.Errorf("%v wrongly overflows float64", 1e300)
+	}
+
+	maxFloat32 := float64((1<<24 - 1) << (127 - 23))
+	if ovf := ValueOf(float32(0)).OverflowFloat(maxFloat32); ovf {
+		t.Errorf("%v wrongly overflows float32", maxFloat32)
+	}
+	ovfFloat32 := float64((1<<24-1)<<(127-23) + 1<<(127-52))
+	if ovf := ValueOf(float32(0)).OverflowFloat(ovfFloat32); !ovf {
+		t.Errorf("%v should overflow float32", ovfFloat32)
+	}
+	if ovf := ValueOf(float32(0)).OverflowFloat(-ovfFloat32); !ovf {
+		t.Errorf("%v should overflow float32", -ovfFloat32)
+	}
+
+	maxInt32 := int64(0x7fffffff)
+	if ovf := ValueOf(int32(0)).OverflowInt(maxInt32); ovf {
+		t.Errorf("%v wrongly overflows int32", maxInt32)
+	}
+	if ovf := ValueOf(int32(0)).OverflowInt(-1 << 31); ovf {
+		t.Errorf("%v wrongly overflows int32", -int64(1)<<31)
+	}
+	ovfInt32 := int64(1 << 31)
+	if ovf := ValueOf(int32(0)).OverflowInt(ovfInt32); !ovf {
+		t.Errorf("%v should overflow int32", ovfInt32)
+	}
+	if ovf := ValueOf(int8(0)).OverflowInt(-129); !ovf {
+		t.Errorf("%v should overflow int8", -129)
+	}
+
+	maxUint32 := uint64(0xffffffff)
+	if ovf := ValueOf(uint32(0)).OverflowUint(maxUint32); ovf {
+		t.Errorf("%v wrongly overflows uint32", maxUint32)
+	}
+	ovfUint32 := uint64(1 << 32)
+	if ovf := ValueOf(uint32(0)).OverflowUint(ovfUint32); !ovf {
+		t.Errorf("%v should overflow uint32", ovfUint32)
+	}
+	if ovf := ValueOf(uint64(0)).OverflowUint(1<<64 - 1); ovf {
+		t.Errorf("%v wrongly overflows uint64", uint64(1<<64-1))
+	}
+}
@@ -0,0 +1,249 @@
+package reflect
+
+// This file implements Value.Convert, following the conversion rules in the
+// Go spec: https://go.dev/ref/spec#Conversions
+
+import (
+	"unsafe"
+)
+
+const (
+	maxFloat32 = 0x1p127 * (1 + (1 - 0x1p-23))
+	maxFloat64 = 0x1p1023 * (1 + (1 - 0x1p-52))
+)
+
+// The kind of conversion between two types, as returned by convertOp.
+type convertKind uint8
+
+const (
+	convertInvalid     convertKind = iota
+	convertIdentical               // same underlying type, no change in representation
+	convertNumber                  // integer and floating point conversions
+	convertComplex                 // complex to complex
+	convertIntString               // integer to string
+	convertBytesString             // []byte or []rune to string
+	convertStringBytes             // string to []byte or []rune
+	convertInterface               // any type to an empty interface
+)
+
+// convertOp returns what kind of conversion is needed to convert a value of
+// type t to type u, or convertInvalid if the conversion isn't possible.
+//
+// Conversions to a non-empty interface type are reported as invalid (unless
+// both types have the same underlying type), because it isn't possible to
+// check at runtime whether a type implements an interface. Callers should
+// panic instead of reporting that such a conversion isn't possible.
+func (t rawType) convertOp(u rawType) convertKind {
+	tk := t.Kind()
+	uk := u.Kind()
+	switch {
+	case isNumber(tk) && isNumber(uk):
+		return convertNumber
+	case isComplex(tk) && isComplex(uk):
+		return convertComplex
+	case uk == Interface && u.isEmptyInterface():
+		return convertInterface
+	case t.underlying() == u.underlying():
+		return convertIdentical
+	case tk == Pointer && uk == Pointer && t.underlying() == t && u.underlying() == u && t.elem().underlying() == u.elem().underlying():
+		// Unnamed pointer types with identical underlying base types.
+		return convertIdentical
+	case uk == String && (isInt(tk) || isUint(tk)):
+		return convertIntString
+	case uk == String && tk == Slice && isByteOrRune(t.elem().Kind()):
+		return convertBytesString
+	case tk == String && uk == Slice && isByteOrRune(u.elem().Kind()):
+		return convertStringBytes
+	default:
+		return convertInvalid
+	}
+}
+
+// isEmptyInterface returns whether t is an interface type without methods. The
+// reflect lowering pass gives the empty interface type number zero, see
+// getNonBasicTypeCode in transform/reflect.go.
+func (t rawType) isEmptyInterface() bool {
+	return t.Kind() == Interface && t.underlying().stripPrefix() == 0
+}
+
+func isInt(k Kind) bool {
+	return k >= Int && k <= Int64
+}
+
+func isUint(k Kind) bool {
+	return k >= Uint && k <= Uintptr
+}
+
+func isFloat(k Kind) bool {
+	return k == Float32 || k == Float64
+}
+
+func isNumber(k Kind) bool {
+	return isInt(k) || isUint(k) || isFloat(k)
+}
+
+func isComplex(k Kind) bool {
+	return k == Complex64 || k == Complex128
+}
+
+func isByteOrRune(k Kind) bool {
+	return k == Uint8 || k == Int32
+}
+
+// Convert returns the value v converted to type t. If the usual Go conversion
+// rules do not allow conversion of the value v to type t, Convert panics.
+func (v Value) Convert(t Type) Value {
+	typ := t.(rawType)
+	flags := v.flags & valueFlagExported
+	switch v.typecode.convertOp(typ) {
+	case convertIdentical:
+		// The representation doesn't change, but the result must not be
+		// addressable. It also must not share memory with v, or changing v
+		// would change the result as well.
+		if v.isIndirect() {
+			if size := typ.Size(); size > unsafe.Sizeof(uintptr(0)) {
+				ptr := alloc(size, nil)
+				memcpy(ptr, v.value, size)
+				return Value{
+					typecode: typ,
+					value:    ptr,
+					flags:    flags,
+				}
+			}
+			return loadValueFromPointer(typ, v.value, flags)
+		}
+		return Value{
+			typecode: typ,
+			value:    v.value,
+			flags:    flags,
+		}
+	case convertNumber:
+		k := v.Kind()
+		switch {
+		case isInt(typ.Kind()) || isUint(typ.Kind()):
+			var bits uint64
+			switch {
+			case isInt(k):
+				bits = uint64(v.Int())
+			case isUint(k):
+				bits = v.Uint()
+			case isUint(typ.Kind()):
+				bits = uint64(v.Float())
+			default:
+				bits = uint64(int64(v.Float()))
+			}
+			return makeValueFromBits(typ, bits, flags)
+		default:
+			var f float64
+			switch {
+			case isInt(k):
+				f = float64(v.Int())
+			case isUint(k):
+				f = float64(v.Uint())
+			default:
+				f = v.Float()
+			}
+			return makeFloat(typ, f, flags)
+		}
+	case convertComplex:
+		c := v.Complex()
+		if typ.Kind() == Complex64 {
+			c64 := complex64(c)
+			return makeValueFromBits(typ, *(*uint64)(unsafe.Pointer(&c64)), flags)
+		}
+		ptr := alloc(typ.Size(), nil)
+		*(*complex128)(ptr) = c
+		return Value{
+			typecode: typ,
+			value:    ptr,
+			flags:    flags,
+		}
+	case convertIntString:
+		s := "\uFFFD"
+		if isInt(v.Kind()) {
+			if x := v.Int(); int64(rune(x)) == x {
+				s = string(rune(x))
+			}
+		} else {
+			if x := v.Uint(); uint64(rune(x)) == x {
+				s = string(rune(x))
+			}
+		}
+		return makeString(typ, s, flags)
+	case convertBytesString:
+		var s string
+		if v.typecode.elem().Kind() == Uint8 {
+			s = string(*(*[]byte)(v.value))
+		} else {
+			s = string(*(*[]rune)(v.value))
+		}
+		return makeString(typ, s, flags)
+	case convertStringBytes:
+		s := *(*string)(v.value)
+		var slice *sliceHeader
+		if typ.elem().Kind() == Uint8 {
+			buf := []byte(s)
+			slice = (*sliceHeader)(unsafe.Pointer(&buf))
+		} else {
+			buf := []rune(s)
+			slice = (*sliceHeader)(unsafe.Pointer(&buf))
+		}
+		return Value{
+			typecode: typ,
+			value:    unsafe.Pointer(slice),
+			flags:    flags,
+		}
+	case convertInterface:
+		itf := valueInterfaceUnsafe(v)
+		return Value{
+			typecode: typ,
+			value:    unsafe.Pointer(&itf),
+			flags:    flags,
+		}
+	default:
+		if typ.Kind() == Interface {
+			panic("reflect: unimplemented: Convert to a non-empty interface")
+		}
+		panic("reflect.Value.Convert: value cannot be converted to the given type")
+	}
+}
+
+// makeValueFromBits returns a new Value of the given type that has the
+// (little-endian) bits as its contents. The size of the type must be at most 8
+// bytes.
+func makeValueFromBits(typ rawType, bits uint64, flags valueFlags) Value {
+	size := typ.Size()
+	if size <= unsafe.Sizeof(uintptr(0)) {
+		// The value is stored directly in the pointer.
+		return Value{
+			typecode: typ,
+			value:    unsafe.Pointer(maskAndShift(uintptr(bits), 0, size)),
+			flags:    flags,
+		}
+	}
+	ptr := alloc(size, nil)
+	memcpy(ptr, unsafe.Pointer(&bits), size)
+	return Value{
+		typecode: typ,
+		value:    ptr,
+		flags:    flags,
+	}
+}
+
+// makeFloat returns a new Value of the given floating point type.
+func makeFloat(typ rawType, f float64, flags valueFlags) Value {
+	if typ.Kind() == Float32 {
+		f32 := float32(f)
+		return makeValueFromBits(typ, uint64(*(*uint32)(unsafe.Pointer(&f32))), flags)
+	}
+	return makeValueFromBits(typ, *(*uint64)(unsafe.Pointer(&f)), flags)
+}
+
+// makeString returns a new Value of the given string type.
+func makeString(typ rawType, s string, flags valueFlags) Value {
+	return Value{
+		typecode: typ,
+		value:    unsafe.Pointer(&s),
+		flags:    flags,
+	}
+}
@@ -19,6 +19,22 @@ var structNamesSidetable byte
 //go:extern reflect.arrayTypesSidetable
 var arrayTypesSidetable byte
 
+//go:extern reflect.mapTypesSidetable
+var mapTypesSidetable byte
+
+//go:extern reflect.funcTypesSidetable
+var funcTypesSidetable byte
+
+// The methods sidetable lists the exported methods of each type, when compiled
+// with -reflect-methods. The functions to call these methods are stored
+// separately in methodFunctionsSidetable, so that they are only kept when a
+// method is actually called.
+//go:extern reflect.methodsSidetable
+var methodsSidetable byte
+
+//go:extern reflect.methodFunctionsSidetable
+var methodFunctionsSidetable uintptr
+
 // readStringSidetable reads a string from the given table (like
 // structNamesSidetable) and returns this string. No heap allocation is
 // necessary because it makes the string point directly to the raw bytes of the
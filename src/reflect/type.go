@@ -195,7 +195,7 @@ type Type interface {
 	//
 	// Only exported methods are accessible and they are sorted in
 	// lexicographic order.
-	//Method(int) Method
+	Method(int) Method
 
 	// MethodByName returns the method with that name in the type's
 	// method set and a boolean indicating if the method was found.
@@ -379,8 +379,8 @@ func (t rawType) Kind() Kind {
 	}
 }
 
-// Elem returns the element type for channel, slice and array types, the
-// pointed-to value for pointer types, and the key type for map types.
+// Elem returns the element type for channel, slice, array and map types, and
+// the pointed-to value for pointer types.
 func (t rawType) Elem() Type {
 	return t.elem()
 }
@@ -393,8 +393,63 @@ func (t rawType) elem() rawType {
 		index := t.stripPrefix()
 		elem, _ := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&arrayTypesSidetable)) + uintptr(index)))
 		return rawType(elem)
-	default: // not implemented: Map
-		panic("unimplemented: (reflect.Type).Elem()")
+	case Map:
+		// skip past the key type
+		index := t.stripPrefix()
+		_, p := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&mapTypesSidetable)) + uintptr(index)))
+		elem, _ := readVarint(p)
+		return rawType(elem)
+	default:
+		panic(&TypeError{"Elem"})
+	}
+}
+
+// key returns the key type of a map type. It panics for other type kinds.
+func (t rawType) key() rawType {
+	if t.Kind() != Map {
+		panic(&TypeError{"Key"})
+	}
+	index := t.stripPrefix()
+	key, _ := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&mapTypesSidetable)) + uintptr(index)))
+	return rawType(key)
+}
+
+// underlying returns the underlying type of a named type, or the type itself
+// if it isn't named.
+func (t rawType) underlying() rawType {
+	if t%2 == 0 {
+		// Basic type: the name is stored in the upper bits.
+		return t % 64
+	}
+	if (t>>4)%2 != 0 {
+		// Named non-basic type: clear the 'n' bit and replace the upper bits
+		// with the underlying type data.
+		return t.stripPrefix()<<5 | t%16
+	}
+	return t
+}
+
+// isBinary returns whether values of this type can be compared by comparing
+// their raw bytes. This must match hashmapIsBinaryKey in the compiler, as it
+// determines how map keys are stored.
+func (t rawType) isBinary() bool {
+	switch t.Kind() {
+	case Bool, Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		return true
+	case Pointer:
+		return true
+	case Array:
+		return t.elem().isBinary()
+	case Struct:
+		numField := t.NumField()
+		for i := 0; i < numField; i++ {
+			if !t.rawField(i).Type.isBinary() {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
 	}
 }
 
@@ -713,44 +768,200 @@ func (t rawType) ChanDir() ChanDir {
 	panic("unimplemented: (reflect.Type).ChanDir()")
 }
 
+// ConvertibleTo returns whether a value of type t can be converted to type u.
+//
+// Conversions to non-empty interface types are not supported: it isn't yet
+// possible to check whether a type implements an interface at runtime.
 func (t rawType) ConvertibleTo(u Type) bool {
-	panic("unimplemented: (reflect.Type).ConvertibleTo()")
+	op := t.convertOp(u.(rawType))
+	if op == convertInvalid && u.Kind() == Interface {
+		panic("reflect: unimplemented: ConvertibleTo with a non-empty interface")
+	}
+	return op != convertInvalid
 }
 
+// IsVariadic returns whether the last parameter of a func type is a "..."
+// parameter. It panics if the type kind is not Func.
 func (t rawType) IsVariadic() bool {
-	panic("unimplemented: (reflect.Type).IsVariadic()")
+	_, _, variadic, _ := t.funcSignature("IsVariadic")
+	return variadic
 }
 
+// NumIn returns the number of parameters of a func type. It panics if the type
+// kind is not Func.
 func (t rawType) NumIn() int {
-	panic("unimplemented: (reflect.Type).NumIn()")
+	numIn, _, _, _ := t.funcSignature("NumIn")
+	return numIn
 }
 
+// NumOut returns the number of results of a func type. It panics if the type
+// kind is not Func.
 func (t rawType) NumOut() int {
-	panic("unimplemented: (reflect.Type).NumOut()")
+	_, numOut, _, _ := t.funcSignature("NumOut")
+	return numOut
 }
 
+// funcSignature reads the signature of a func type from the func sidetable. It
+// returns the number of parameters, the number of results, whether the last
+// parameter is variadic, and a pointer to the list of parameter types followed
+// by the result types. It panics (with the given method name) if the type kind
+// is not Func.
+func (t rawType) funcSignature(method string) (numIn, numOut int, variadic bool, types unsafe.Pointer) {
+	if t.Kind() != Func {
+		panic(&TypeError{method})
+	}
+	index := t.stripPrefix()
+	length, p := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&funcTypesSidetable)) + uintptr(index)))
+	out, p := readVarint(p)
+	return int(length >> 1), int(out), length&1 != 0, p
+}
+
+// readTypeList returns the i'th type code in a list of varint type codes, as
+// used for func parameters and results.
+func readTypeList(p unsafe.Pointer, i int) rawType {
+	for ; i > 0; i-- {
+		_, p = readVarint(p)
+	}
+	typecode, _ := readVarint(p)
+	return rawType(typecode)
+}
+
+// NumMethod returns the number of exported methods in the method set of this
+// type. Method information is only available when compiling with
+// -reflect-methods, otherwise it returns 0 for all types.
 func (t rawType) NumMethod() int {
-	panic("unimplemented: (reflect.Type).NumMethod()")
+	_, n := t.methods()
+	return n
+}
+
+// Method returns the i'th exported method in the method set of this type. It
+// panics if i is not in the range [0, NumMethod()).
+//
+// Unlike the standard library, the Type of the returned method never includes
+// the receiver and Func is the zero Value. Use Value.Method to call a method.
+func (t rawType) Method(i int) Method {
+	m := t.rawMethod(i)
+	return Method{
+		Name:  m.name,
+		Type:  m.signature,
+		Index: i,
+	}
+}
+
+// rawMethod is a single method as stored in the methods sidetable.
+type rawMethod struct {
+	name      string
+	signature rawType
+	fn        uintptr // index into methodFunctionsSidetable plus one, or 0
+}
+
+// methods returns a pointer to the first method of this type in the methods
+// sidetable and the number of methods. If the type has no exported methods,
+// it returns (nil, 0).
+func (t rawType) methods() (unsafe.Pointer, int) {
+	p := unsafe.Pointer(&methodsSidetable)
+	for {
+		var typecode, n uintptr
+		typecode, p = readVarint(p)
+		if typecode == 0 {
+			// Reached the end of the sidetable.
+			return nil, 0
+		}
+		n, p = readVarint(p)
+		if rawType(typecode) == t {
+			return p, int(n)
+		}
+		// Skip past the methods of this type.
+		for i := uintptr(0); i < n; i++ {
+			_, p = readMethod(p)
+		}
+	}
+}
+
+// readMethod reads a single method from the methods sidetable and returns the
+// pointer to the next method.
+func readMethod(p unsafe.Pointer) (rawMethod, unsafe.Pointer) {
+	nameLen, p := readVarint(p)
+	name := *(*string)(unsafe.Pointer(&stringHeader{
+		data: p,
+		len:  nameLen,
+	}))
+	p = unsafe.Pointer(uintptr(p) + nameLen)
+	signature, p := readVarint(p)
+	fn, p := readVarint(p)
+	return rawMethod{name, rawType(signature), fn}, p
+}
+
+// rawMethod returns the i'th method of this type. It panics if i is out of
+// range.
+func (t rawType) rawMethod(i int) rawMethod {
+	p, n := t.methods()
+	if uint(i) >= uint(n) {
+		panic("reflect: Method index out of range")
+	}
+	var m rawMethod
+	for ; i >= 0; i-- {
+		m, p = readMethod(p)
+	}
+	return m
+}
+
+// methodByName returns the method with the given name and its index, or false
+// if there is no such method.
+func (t rawType) methodByName(name string) (rawMethod, int, bool) {
+	p, n := t.methods()
+	for i := 0; i < n; i++ {
+		var m rawMethod
+		m, p = readMethod(p)
+		if m.name == name {
+			return m, i, true
+		}
+	}
+	return rawMethod{}, 0, false
 }
 
 func (t rawType) Name() string {
 	panic("unimplemented: (reflect.Type).Name()")
 }
 
+// Key returns the key type of a map type. It panics for other type kinds.
 func (t rawType) Key() Type {
-	panic("unimplemented: (reflect.Type).Key()")
+	return t.key()
 }
 
+// In returns the type of the i'th parameter of a func type. It panics if the
+// type kind is not Func or if i is not in the range [0, NumIn()).
 func (t rawType) In(i int) Type {
-	panic("unimplemented: (reflect.Type).In()")
+	numIn, _, _, types := t.funcSignature("In")
+	if uint(i) >= uint(numIn) {
+		panic("reflect: In of out-of-range index")
+	}
+	return readTypeList(types, i)
 }
 
+// Out returns the type of the i'th result of a func type. It panics if the
+// type kind is not Func or if i is not in the range [0, NumOut()).
 func (t rawType) Out(i int) Type {
-	panic("unimplemented: (reflect.Type).Out()")
+	numIn, numOut, _, types := t.funcSignature("Out")
+	if uint(i) >= uint(numOut) {
+		panic("reflect: Out of out-of-range index")
+	}
+	return readTypeList(types, numIn+i)
 }
 
+// MethodByName returns the exported method with the given name, and whether
+// it was found. See Method for how the result differs from the standard
+// library.
 func (t rawType) MethodByName(name string) (Method, bool) {
-	panic("unimplemented: (reflect.Type).MethodByName()")
+	m, i, ok := t.methodByName(name)
+	if !ok {
+		return Method{}, false
+	}
+	return Method{
+		Name:  m.name,
+		Type:  m.signature,
+		Index: i,
+	}, true
 }
 
 func (t rawType) PkgPath() string {
@@ -872,6 +1083,11 @@ func align(offset uintptr, alignment uintptr) uintptr {
 	return (offset + alignment - 1) &^ (alignment - 1)
 }
 
+// SliceOf returns the slice type with element type t.
 func SliceOf(t Type) Type {
-	panic("unimplemented: reflect.SliceOf()")
+	sliceType := t.(rawType)<<5 | 7 // 0b0111 == 7
+	if sliceType>>5 != t {
+		panic("reflect: SliceOf type does not fit")
+	}
+	return sliceType
 }
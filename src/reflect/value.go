@@ -13,6 +13,7 @@ type valueFlags uint8
 const (
 	valueFlagIndirect valueFlags = 1 << iota
 	valueFlagExported
+	valueFlagMethod // a Func value created by Method or MethodByName
 )
 
 type Value struct {
@@ -61,6 +62,9 @@ func (v Value) Interface() interface{} {
 	if !v.isExported() {
 		panic("(reflect.Value).Interface: unexported")
 	}
+	if v.flags&valueFlagMethod != 0 {
+		panic("unimplemented: (reflect.Value).Interface() of a method value")
+	}
 	return valueInterfaceUnsafe(v)
 }
 
@@ -331,16 +335,97 @@ func (v Value) String() string {
 	}
 }
 
+// Bytes returns the underlying byte slice of v. It panics if v is not a slice
+// of bytes.
 func (v Value) Bytes() []byte {
-	panic("unimplemented: (reflect.Value).Bytes()")
+	if v.Kind() != Slice || v.typecode.elem().Kind() != Uint8 {
+		panic(&ValueError{Method: "Bytes", Kind: v.Kind()})
+	}
+	return *(*[]byte)(v.value)
 }
 
+// Slice returns v[i:j]. It panics if v is not a slice, string or addressable
+// array, or if the indices are out of bounds.
 func (v Value) Slice(i, j int) Value {
-	panic("unimplemented: (reflect.Value).Slice()")
+	switch v.Kind() {
+	case Slice:
+		slice := (*sliceHeader)(v.value)
+		if i < 0 || j < i || uintptr(j) > slice.cap {
+			panic("reflect.Value.Slice: slice index out of bounds")
+		}
+		return v.makeSubslice(v.typecode, slice.data, i, j, int(slice.cap))
+	case Array:
+		if !v.isIndirect() {
+			panic("reflect.Value.Slice: slice of unaddressable array")
+		}
+		length := v.typecode.Len()
+		if i < 0 || j < i || j > length {
+			panic("reflect.Value.Slice: slice index out of bounds")
+		}
+		return v.makeSubslice(SliceOf(v.typecode.elem()).(rawType), v.value, i, j, length)
+	case String:
+		s := (*stringHeader)(v.value)
+		if i < 0 || j < i || uintptr(j) > s.len {
+			panic("reflect.Value.Slice: string index out of bounds")
+		}
+		str := &stringHeader{
+			data: unsafe.Pointer(uintptr(s.data) + uintptr(i)),
+			len:  uintptr(j - i),
+		}
+		return Value{
+			typecode: v.typecode,
+			value:    unsafe.Pointer(str),
+			flags:    v.flags &^ valueFlagIndirect,
+		}
+	default:
+		panic(&ValueError{Method: "Slice", Kind: v.Kind()})
+	}
 }
 
+// Slice3 is the 3-index form of the slice operation: it returns v[i:j:k]. It
+// panics if v is not a slice or addressable array, or if the indices are out
+// of bounds.
 func (v Value) Slice3(i, j, k int) Value {
-	panic("unimplemented: (reflect.Value).Slice3()")
+	switch v.Kind() {
+	case Slice:
+		slice := (*sliceHeader)(v.value)
+		if i < 0 || j < i || k < j || uintptr(k) > slice.cap {
+			panic("reflect.Value.Slice3: slice index out of bounds")
+		}
+		return v.makeSubslice(v.typecode, slice.data, i, j, k)
+	case Array:
+		if !v.isIndirect() {
+			panic("reflect.Value.Slice3: slice of unaddressable array")
+		}
+		length := v.typecode.Len()
+		if i < 0 || j < i || k < j || k > length {
+			panic("reflect.Value.Slice3: slice index out of bounds")
+		}
+		return v.makeSubslice(SliceOf(v.typecode.elem()).(rawType), v.value, i, j, k)
+	default:
+		panic(&ValueError{Method: "Slice3", Kind: v.Kind()})
+	}
+}
+
+// makeSubslice returns a new slice Value of the given slice type, pointing to
+// the [i:j:k] part of the backing array starting at data. The bounds must
+// already have been checked by the caller.
+func (v Value) makeSubslice(typecode rawType, data unsafe.Pointer, i, j, k int) Value {
+	if k != i {
+		// Only move the data pointer when the new slice has a non-zero
+		// capacity, to avoid pointing past the end of the backing array.
+		data = unsafe.Pointer(uintptr(data) + typecode.elem().Size()*uintptr(i))
+	}
+	slice := &sliceHeader{
+		data: data,
+		len:  uintptr(j - i),
+		cap:  uintptr(k - i),
+	}
+	return Value{
+		typecode: typecode,
+		value:    unsafe.Pointer(slice),
+		flags:    v.flags &^ valueFlagIndirect,
+	}
 }
 
 //go:linkname maplen runtime.hashmapLenUnsafePointer
@@ -584,35 +669,294 @@ func (v Value) NumMethod() int {
 	return v.typecode.NumMethod()
 }
 
+// methodValue is what the value pointer of a Func Value created by Method or
+// MethodByName points to.
+type methodValue struct {
+	receiver unsafe.Pointer // receiver as stored in an interface
+	fn       uintptr        // index into methodFunctionsSidetable plus one
+}
+
+// Method returns a Func value for the i'th method of v, with v as the
+// receiver. The resulting value can be called with Call.
+//
+// Method information is only available when compiling with -reflect-methods.
+func (v Value) Method(i int) Value {
+	if v.Kind() == Interface {
+		// Index into the method set of the interface type, but call the
+		// method of the dynamic type.
+		return v.MethodByName(v.typecode.rawMethod(i).name)
+	}
+	_, receiver := decomposeInterface(valueInterfaceUnsafe(v))
+	return makeMethodValue(v.typecode.rawMethod(i), receiver, v.flags)
+}
+
+// MethodByName returns a Func value for the method of v with the given name,
+// with v as the receiver. It returns the zero Value if no method was found.
+func (v Value) MethodByName(name string) Value {
+	if v.Kind() == Interface {
+		if _, _, ok := v.typecode.methodByName(name); !ok {
+			return Value{}
+		}
+	}
+	typecode, receiver := decomposeInterface(valueInterfaceUnsafe(v))
+	if typecode == 0 {
+		panic("reflect: Method on nil interface value")
+	}
+	m, _, ok := typecode.methodByName(name)
+	if !ok {
+		return Value{}
+	}
+	return makeMethodValue(m, receiver, v.flags)
+}
+
+func makeMethodValue(m rawMethod, receiver unsafe.Pointer, flags valueFlags) Value {
+	return Value{
+		typecode: m.signature,
+		value: unsafe.Pointer(&methodValue{
+			receiver: receiver,
+			fn:       m.fn,
+		}),
+		flags: flags&valueFlagExported | valueFlagMethod,
+	}
+}
+
+// OverflowFloat reports whether the float64 x cannot be represented by v's
+// type. It panics if v's Kind is not Float32 or Float64.
 func (v Value) OverflowFloat(x float64) bool {
-	panic("unimplemented: (reflect.Value).OverflowFloat()")
+	switch v.Kind() {
+	case Float32:
+		if x < 0 {
+			x = -x
+		}
+		return maxFloat32 < x && x <= maxFloat64
+	case Float64:
+		return false
+	default:
+		panic(&ValueError{Method: "OverflowFloat", Kind: v.Kind()})
+	}
+}
+
+//go:linkname hashmapMake runtime.hashmapMakeUnsafePointer
+func hashmapMake(keySize, valueSize uint8, sizeHint uintptr) unsafe.Pointer
+
+//go:linkname hashmapNext runtime.hashmapNextUnsafePointer
+func hashmapNext(m unsafe.Pointer, it unsafe.Pointer, key, value unsafe.Pointer) bool
+
+//go:linkname hashmapBinarySet runtime.hashmapBinarySetUnsafePointer
+func hashmapBinarySet(m unsafe.Pointer, key, value unsafe.Pointer)
+
+//go:linkname hashmapBinaryGet runtime.hashmapBinaryGetUnsafePointer
+func hashmapBinaryGet(m unsafe.Pointer, key, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapBinaryDelete runtime.hashmapBinaryDeleteUnsafePointer
+func hashmapBinaryDelete(m unsafe.Pointer, key unsafe.Pointer)
+
+//go:linkname hashmapStringSet runtime.hashmapStringSetUnsafePointer
+func hashmapStringSet(m unsafe.Pointer, key string, value unsafe.Pointer)
+
+//go:linkname hashmapStringGet runtime.hashmapStringGetUnsafePointer
+func hashmapStringGet(m unsafe.Pointer, key string, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapStringDelete runtime.hashmapStringDeleteUnsafePointer
+func hashmapStringDelete(m unsafe.Pointer, key string)
+
+//go:linkname hashmapInterfaceSet runtime.hashmapInterfaceSetUnsafePointer
+func hashmapInterfaceSet(m unsafe.Pointer, key interface{}, value unsafe.Pointer)
+
+//go:linkname hashmapInterfaceGet runtime.hashmapInterfaceGetUnsafePointer
+func hashmapInterfaceGet(m unsafe.Pointer, key interface{}, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapInterfaceDelete runtime.hashmapInterfaceDeleteUnsafePointer
+func hashmapInterfaceDelete(m unsafe.Pointer, key interface{})
+
+// Same as runtime.hashmapIterator. It must have the same layout.
+type hashmapIterator struct {
+	bucketNumber uintptr
+	bucket       unsafe.Pointer
+	bucketIndex  uint8
+}
+
+// The way keys are stored in a map depends on the key type, see
+// createMakeMap in the compiler for details.
+const (
+	mapKeyString = iota
+	mapKeyBinary
+	mapKeyInterface
+)
+
+// mapKeyKind returns how keys of the given map key type are stored in a map.
+func mapKeyKind(keyType rawType) int {
+	if keyType.Kind() == String {
+		return mapKeyString
+	}
+	if keyType.isBinary() {
+		return mapKeyBinary
+	}
+	return mapKeyInterface
+}
+
+// mapKeySize returns the size of a key as stored in a map.
+func mapKeySize(keyType rawType) uintptr {
+	switch mapKeyKind(keyType) {
+	case mapKeyString:
+		return unsafe.Sizeof("")
+	case mapKeyBinary:
+		return keyType.Size()
+	default:
+		return unsafe.Sizeof(interface{}(nil))
+	}
+}
+
+// mapKeyToInterface returns the key as an interface value, the way it is stored
+// in maps with non-trivially comparable keys. The compiler stores these keys
+// with their underlying type, unless the key type is an interface already.
+func mapKeyToInterface(keyType rawType, key Value) interface{} {
+	itf := valueInterfaceUnsafe(key)
+	if keyType.Kind() != Interface {
+		typecode, value := decomposeInterface(itf)
+		itf = composeInterface(typecode.underlying(), value)
+	}
+	return itf
+}
+
+// valuePointer returns a pointer to the value, copying it to a new memory
+// location first if it is stored directly in the pointer.
+func (v Value) valuePointer() unsafe.Pointer {
+	if v.isIndirect() || v.typecode.Size() > unsafe.Sizeof(uintptr(0)) {
+		return v.value
+	}
+	value := v.value
+	return unsafe.Pointer(&value)
+}
+
+// loadValueFromPointer returns a new (non-addressable) Value of the given type
+// from the value stored at ptr.
+func loadValueFromPointer(typecode rawType, ptr unsafe.Pointer, flags valueFlags) Value {
+	size := typecode.Size()
+	if size > unsafe.Sizeof(uintptr(0)) {
+		return Value{
+			typecode: typecode,
+			value:    ptr,
+			flags:    flags,
+		}
+	}
+	return Value{
+		typecode: typecode,
+		value:    unsafe.Pointer(loadValue(ptr, size)),
+		flags:    flags,
+	}
 }
 
+// MapKeys returns a slice with all the keys in the map, in unspecified order.
+// It panics if v's Kind is not Map.
 func (v Value) MapKeys() []Value {
-	panic("unimplemented: (reflect.Value).MapKeys()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapKeys", Kind: v.Kind()})
+	}
+	keys := make([]Value, 0, v.Len())
+	it := v.MapRange()
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	return keys
 }
 
+// MapIndex returns the value associated with key in the map v. It returns the
+// zero Value if key is not found in the map.
 func (v Value) MapIndex(key Value) Value {
-	panic("unimplemented: (reflect.Value).MapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapIndex", Kind: v.Kind()})
+	}
+	keyType := v.typecode.key()
+	if key.typecode != keyType && keyType.Kind() != Interface {
+		panic("reflect.Value.MapIndex: incompatible key type")
+	}
+	elemType := v.typecode.elem()
+	elemSize := elemType.Size()
+	elem := alloc(elemSize, nil)
+	m := v.pointer()
+	var ok bool
+	switch mapKeyKind(keyType) {
+	case mapKeyString:
+		ok = hashmapStringGet(m, *(*string)(key.value), elem, elemSize)
+	case mapKeyBinary:
+		ok = hashmapBinaryGet(m, key.valuePointer(), elem, elemSize)
+	default:
+		ok = hashmapInterfaceGet(m, mapKeyToInterface(keyType, key), elem, elemSize)
+	}
+	if !ok {
+		return Value{}
+	}
+	return loadValueFromPointer(elemType, elem, v.flags&valueFlagExported)
 }
 
+// MapRange returns an iterator over the map v. It panics if v's Kind is not
+// Map.
 func (v Value) MapRange() *MapIter {
-	panic("unimplemented: (reflect.Value).MapRange()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapRange", Kind: v.Kind()})
+	}
+	return &MapIter{
+		m: v,
+	}
 }
 
+// A MapIter is an iterator for ranging over a map. See Value.MapRange.
 type MapIter struct {
+	m     Value
+	it    hashmapIterator
+	key   Value
+	value Value
+	valid bool
 }
 
+// Key returns the key of the iterator's current map entry.
 func (it *MapIter) Key() Value {
-	panic("unimplemented: (*reflect.MapIter).Key()")
+	if !it.valid {
+		panic("reflect.MapIter.Key called before Next")
+	}
+	return it.key
 }
 
+// Value returns the value of the iterator's current map entry.
 func (it *MapIter) Value() Value {
-	panic("unimplemented: (*reflect.MapIter).Value()")
+	if !it.valid {
+		panic("reflect.MapIter.Value called before Next")
+	}
+	return it.value
 }
 
+// Next advances the map iterator and reports whether there is another entry.
+// It returns false when the iterator is exhausted.
 func (it *MapIter) Next() bool {
-	panic("unimplemented: (*reflect.MapIter).Next()")
+	keyType := it.m.typecode.key()
+	elemType := it.m.typecode.elem()
+
+	// Allocate new buffers for every entry, as the returned Values refer to
+	// them.
+	key := alloc(mapKeySize(keyType), nil)
+	elem := alloc(elemType.Size(), nil)
+	if !hashmapNext(it.m.pointer(), unsafe.Pointer(&it.it), key, elem) {
+		it.valid = false
+		return false
+	}
+	it.valid = true
+
+	flags := it.m.flags & valueFlagExported
+	if mapKeyKind(keyType) == mapKeyInterface && keyType.Kind() != Interface {
+		// The key is stored as an interface but it isn't of interface type.
+		// Extract the underlying value.
+		_, value := decomposeInterface(*(*interface{})(key))
+		it.key = Value{
+			typecode: keyType,
+			value:    value,
+			flags:    flags,
+		}
+	} else {
+		it.key = loadValueFromPointer(keyType, key, flags)
+	}
+	it.value = loadValueFromPointer(elemType, elem, flags)
+	return true
 }
 
 func (v Value) Set(x Value) {
@@ -711,16 +1055,42 @@ func (v Value) SetString(x string) {
 	}
 }
 
+// SetBytes sets v's underlying value. It panics if v is not an addressable
+// slice of bytes.
 func (v Value) SetBytes(x []byte) {
-	panic("unimplemented: (reflect.Value).SetBytes()")
+	v.checkAddressable()
+	if v.Kind() != Slice || v.typecode.elem().Kind() != Uint8 {
+		panic(&ValueError{Method: "SetBytes", Kind: v.Kind()})
+	}
+	*(*[]byte)(v.value) = x
 }
 
+// SetCap sets v's capacity to n. It panics if v is not an addressable slice, or
+// if n is smaller than the length or greater than the capacity of the slice.
 func (v Value) SetCap(n int) {
-	panic("unimplemented: (reflect.Value).SetCap()")
+	v.checkAddressable()
+	if v.Kind() != Slice {
+		panic(&ValueError{Method: "SetCap", Kind: v.Kind()})
+	}
+	slice := (*sliceHeader)(v.value)
+	if n < int(slice.len) || uintptr(n) > slice.cap {
+		panic("reflect.Value.SetCap: slice capacity out of range")
+	}
+	slice.cap = uintptr(n)
 }
 
+// SetLen sets v's length to n. It panics if v is not an addressable slice, or if
+// n is negative or greater than the capacity of the slice.
 func (v Value) SetLen(n int) {
-	panic("unimplemented: (reflect.Value).SetLen()")
+	v.checkAddressable()
+	if v.Kind() != Slice {
+		panic(&ValueError{Method: "SetLen", Kind: v.Kind()})
+	}
+	slice := (*sliceHeader)(v.value)
+	if n < 0 || uintptr(n) > slice.cap {
+		panic("reflect.Value.SetLen: slice length out of range")
+	}
+	slice.len = uintptr(n)
 }
 
 func (v Value) checkAddressable() {
@@ -729,24 +1099,69 @@ func (v Value) checkAddressable() {
 	}
 }
 
+// OverflowInt reports whether the int64 x cannot be represented by v's type.
+// It panics if v's Kind is not Int, Int8, Int16, Int32, or Int64.
 func (v Value) OverflowInt(x int64) bool {
-	panic("unimplemented: reflect.OverflowInt()")
+	switch v.Kind() {
+	case Int, Int8, Int16, Int32, Int64:
+		bitSize := v.typecode.Size() * 8
+		trunc := (x << (64 - bitSize)) >> (64 - bitSize)
+		return x != trunc
+	default:
+		panic(&ValueError{Method: "OverflowInt", Kind: v.Kind()})
+	}
 }
 
+// OverflowUint reports whether the uint64 x cannot be represented by v's type.
+// It panics if v's Kind is not Uint, Uintptr, Uint8, Uint16, Uint32, or
+// Uint64.
 func (v Value) OverflowUint(x uint64) bool {
-	panic("unimplemented: reflect.OverflowUint()")
-}
-
-func (v Value) Convert(t Type) Value {
-	panic("unimplemented: (reflect.Value).Convert()")
+	switch v.Kind() {
+	case Uint, Uintptr, Uint8, Uint16, Uint32, Uint64:
+		bitSize := v.typecode.Size() * 8
+		trunc := (x << (64 - bitSize)) >> (64 - bitSize)
+		return x != trunc
+	default:
+		panic(&ValueError{Method: "OverflowUint", Kind: v.Kind()})
+	}
 }
 
+// MakeSlice returns a new zero-initialized slice value for the specified slice
+// type, length, and capacity.
 func MakeSlice(typ Type, len, cap int) Value {
-	panic("unimplemented: reflect.MakeSlice()")
+	if typ.Kind() != Slice {
+		panic("reflect.MakeSlice of non-slice type")
+	}
+	if len < 0 || cap < len {
+		panic("reflect.MakeSlice: len out of range")
+	}
+	elemSize := typ.(rawType).elem().Size()
+	slice := &sliceHeader{
+		data: alloc(elemSize*uintptr(cap), nil),
+		len:  uintptr(len),
+		cap:  uintptr(cap),
+	}
+	return Value{
+		typecode: typ.(rawType),
+		value:    unsafe.Pointer(slice),
+		flags:    valueFlagExported,
+	}
 }
 
+// Zero returns a Value representing the zero value for the specified type. The
+// returned value is neither addressable nor settable.
 func Zero(typ Type) Value {
-	panic("unimplemented: reflect.Zero()")
+	t := typ.(rawType)
+	var value unsafe.Pointer
+	if t.Size() > unsafe.Sizeof(uintptr(0)) {
+		// Values that don't fit in a pointer are stored indirectly.
+		value = alloc(t.Size(), nil)
+	}
+	return Value{
+		typecode: t,
+		value:    value,
+		flags:    valueFlagExported,
+	}
 }
 
 // New is the reflect equivalent of the new(T) keyword, returning a pointer to a
@@ -812,16 +1227,86 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer
 //go:linkname sliceAppend runtime.sliceAppend
 func sliceAppend(srcBuf, elemsBuf unsafe.Pointer, srcLen, srcCap, elemsLen uintptr, elemSize uintptr) (unsafe.Pointer, uintptr, uintptr)
 
+//go:linkname sliceCopy runtime.sliceCopy
+func sliceCopy(dst, src unsafe.Pointer, dstLen, srcLen uintptr, elemSize uintptr) int
+
 // Copy copies the contents of src into dst until either
 // dst has been filled or src has been exhausted.
 func Copy(dst, src Value) int {
-	panic("unimplemented: reflect.Copy()")
+	var dstData unsafe.Pointer
+	var dstLen uintptr
+	switch dst.Kind() {
+	case Slice:
+		slice := (*sliceHeader)(dst.value)
+		dstData, dstLen = slice.data, slice.len
+	case Array:
+		dst.checkAddressable()
+		dstData, dstLen = dst.value, uintptr(dst.typecode.Len())
+	default:
+		panic(&ValueError{Method: "Copy", Kind: dst.Kind()})
+	}
+	if !dst.isExported() {
+		panic("reflect.Copy: unexported")
+	}
+
+	elemType := dst.typecode.elem()
+	var srcData unsafe.Pointer
+	var srcLen uintptr
+	switch src.Kind() {
+	case Slice:
+		slice := (*sliceHeader)(src.value)
+		srcData, srcLen = slice.data, slice.len
+	case Array:
+		srcData, srcLen = src.valuePointer(), uintptr(src.typecode.Len())
+	case String:
+		if elemType.Kind() != Uint8 {
+			panic("reflect.Copy: string source requires a byte slice destination")
+		}
+		s := (*stringHeader)(src.value)
+		srcData, srcLen = s.data, s.len
+	default:
+		panic(&ValueError{Method: "Copy", Kind: src.Kind()})
+	}
+	if src.Kind() != String && src.typecode.elem() != elemType {
+		panic("reflect.Copy: incompatible element types")
+	}
+
+	return sliceCopy(dstData, srcData, dstLen, srcLen, elemType.Size())
 }
 
 // Append appends the values x to a slice s and returns the resulting slice.
 // As in Go, each x's value must be assignable to the slice's element type.
 func Append(s Value, x ...Value) Value {
-	panic("unimplemented: reflect.Append()")
+	if s.Kind() != Slice {
+		panic(&ValueError{Method: "Append", Kind: s.Kind()})
+	}
+	if !s.isExported() {
+		panic("reflect.Append: unexported")
+	}
+	slice := *(*sliceHeader)(s.value)
+	elemType := s.typecode.elem()
+	elemSize := elemType.Size()
+	for _, elem := range x {
+		if !elem.isExported() {
+			panic("reflect.Append: unexported")
+		}
+		var elemPtr unsafe.Pointer
+		if elem.typecode == elemType {
+			elemPtr = elem.valuePointer()
+		} else if elemType.Kind() == Interface {
+			// Store the value in an interface first.
+			itf := valueInterfaceUnsafe(elem)
+			elemPtr = unsafe.Pointer(&itf)
+		} else {
+			panic("reflect.Append: incompatible element type")
+		}
+		slice.data, slice.len, slice.cap = sliceAppend(slice.data, elemPtr, slice.len, slice.cap, 1, elemSize)
+	}
+	return Value{
+		typecode: s.typecode,
+		value:    unsafe.Pointer(&slice),
+		flags:    valueFlagExported,
+	}
 }
 
 // AppendSlice appends a slice t to a slice s and returns the resulting slice.
@@ -852,8 +1337,57 @@ func AppendSlice(s, t Value) Value {
 	}
 }
 
+// SetMapIndex sets the element associated with key in the map v to elem. If
+// elem is the zero Value, SetMapIndex deletes the key from the map.
 func (v Value) SetMapIndex(key, elem Value) {
-	panic("unimplemented: (reflect.Value).SetMapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "SetMapIndex", Kind: v.Kind()})
+	}
+	if !v.isExported() || !key.isExported() {
+		panic("reflect.Value.SetMapIndex: unexported")
+	}
+	keyType := v.typecode.key()
+	if key.typecode != keyType && keyType.Kind() != Interface {
+		panic("reflect.Value.SetMapIndex: incompatible key type")
+	}
+	m := v.pointer()
+
+	if !elem.IsValid() {
+		// Delete the key from the map.
+		switch mapKeyKind(keyType) {
+		case mapKeyString:
+			hashmapStringDelete(m, *(*string)(key.value))
+		case mapKeyBinary:
+			hashmapBinaryDelete(m, key.valuePointer())
+		default:
+			hashmapInterfaceDelete(m, mapKeyToInterface(keyType, key))
+		}
+		return
+	}
+
+	if !elem.isExported() {
+		panic("reflect.Value.SetMapIndex: unexported")
+	}
+	elemType := v.typecode.elem()
+	if elem.typecode != elemType && elemType.Kind() != Interface {
+		panic("reflect.Value.SetMapIndex: incompatible element type")
+	}
+	var elemPtr unsafe.Pointer
+	if elemType.Kind() == Interface && elem.typecode.Kind() != Interface {
+		// Store the value in an interface first.
+		itf := valueInterfaceUnsafe(elem)
+		elemPtr = unsafe.Pointer(&itf)
+	} else {
+		elemPtr = elem.valuePointer()
+	}
+	switch mapKeyKind(keyType) {
+	case mapKeyString:
+		hashmapStringSet(m, *(*string)(key.value), elemPtr)
+	case mapKeyBinary:
+		hashmapBinarySet(m, key.valuePointer(), elemPtr)
+	default:
+		hashmapInterfaceSet(m, mapKeyToInterface(keyType, key), elemPtr)
+	}
 }
 
 // FieldByIndex returns the nested field corresponding to index.
@@ -872,15 +1406,135 @@ func (v Value) FieldByName(name string) Value {
 
 // MakeMap creates a new map with the specified type.
 func MakeMap(typ Type) Value {
-	panic("unimplemented: reflect.MakeMap()")
+	return MakeMapWithSize(typ, 8)
+}
+
+// MakeMapWithSize creates a new map with the specified type and initial space
+// for approximately n elements.
+func MakeMapWithSize(typ Type, n int) Value {
+	if typ.Kind() != Map {
+		panic(&ValueError{Method: "MakeMap", Kind: typ.Kind()})
+	}
+	if n < 0 {
+		panic("reflect.MakeMapWithSize: negative size hint")
+	}
+	t := typ.(rawType)
+	keySize := mapKeySize(t.key())
+	elemSize := t.elem().Size()
+	if keySize > 255 || elemSize > 255 {
+		panic("reflect.MakeMapWithSize: key or element type too big")
+	}
+	return Value{
+		typecode: t,
+		value:    hashmapMake(uint8(keySize), uint8(elemSize), uintptr(n)),
+		flags:    valueFlagExported,
+	}
 }
 
+// Call calls the function v with the input arguments in and returns the
+// results. If v is variadic, the extra arguments are passed as a slice.
+//
+// Only method values (as returned by Method and MethodByName) can be called.
 func (v Value) Call(in []Value) []Value {
-	panic("unimplemented: (reflect.Value).Call()")
+	return v.call("Call", in, false)
 }
 
-func (v Value) MethodByName(name string) Value {
-	panic("unimplemented: (reflect.Value).MethodByName()")
+// CallSlice calls the variadic function v with the input arguments in, where
+// the last argument is the slice for the variadic parameter.
+func (v Value) CallSlice(in []Value) []Value {
+	return v.call("CallSlice", in, true)
+}
+
+func (v Value) call(op string, in []Value, isSlice bool) []Value {
+	if v.Kind() != Func {
+		panic(&ValueError{Method: op, Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect.Value." + op + ": unexported")
+	}
+	if v.flags&valueFlagMethod == 0 {
+		panic("unimplemented: (reflect.Value)." + op + "() of a func that is not a method value")
+	}
+	numIn, numOut, variadic, types := v.typecode.funcSignature(op)
+	if isSlice && !variadic {
+		panic("reflect: CallSlice of non-variadic function")
+	}
+	if variadic && !isSlice {
+		if len(in) < numIn-1 {
+			panic("reflect: Call with too few input arguments")
+		}
+		// Pack the variadic arguments in a slice.
+		extra := MakeSlice(readTypeList(types, numIn-1), 0, len(in)-(numIn-1))
+		extra = Append(extra, in[numIn-1:]...)
+		in = append(in[:numIn-1:numIn-1], extra)
+	}
+	if len(in) != numIn {
+		panic("reflect: wrong argument count")
+	}
+
+	// Store the parameters in a buffer, laid out like a struct. This is what
+	// the wrapper generated by the compiler expects.
+	paramsSize, resultTypes := funcFieldsSize(types, numIn)
+	params := alloc(paramsSize, nil)
+	p := types
+	offset := uintptr(0)
+	for _, arg := range in {
+		var typecode uintptr
+		typecode, p = readVarint(p)
+		paramType := rawType(typecode)
+		if !arg.isExported() {
+			panic("reflect.Value." + op + ": unexported")
+		}
+		var argPtr unsafe.Pointer
+		if arg.typecode == paramType {
+			argPtr = arg.valuePointer()
+		} else if paramType.Kind() == Interface {
+			// Store the value in an interface first.
+			itf := valueInterfaceUnsafe(arg)
+			argPtr = unsafe.Pointer(&itf)
+		} else {
+			panic("reflect.Value." + op + ": incompatible argument type")
+		}
+		offset = align(offset, uintptr(paramType.Align()))
+		memcpy(unsafe.Pointer(uintptr(params)+offset), argPtr, paramType.Size())
+		offset += paramType.Size()
+	}
+
+	// Call the method through its wrapper.
+	method := (*methodValue)(v.value)
+	resultsSize, _ := funcFieldsSize(resultTypes, numOut)
+	results := alloc(resultsSize, nil)
+	fn := *(*uintptr)(unsafe.Pointer(uintptr(unsafe.Pointer(&methodFunctionsSidetable)) + (method.fn-1)*unsafe.Sizeof(uintptr(0))))
+	wrapper := *(*func(receiver, params, results unsafe.Pointer))(unsafe.Pointer(&funcHeader{
+		Code: unsafe.Pointer(fn),
+	}))
+	wrapper(method.receiver, params, results)
+
+	// Load the results from the results buffer.
+	out := make([]Value, numOut)
+	p = resultTypes
+	offset = 0
+	for i := range out {
+		var typecode uintptr
+		typecode, p = readVarint(p)
+		resultType := rawType(typecode)
+		offset = align(offset, uintptr(resultType.Align()))
+		out[i] = loadValueFromPointer(resultType, unsafe.Pointer(uintptr(results)+offset), valueFlagExported)
+		offset += resultType.Size()
+	}
+	return out
+}
+
+// funcFieldsSize returns the size of a struct with the first n types in the
+// given type list as fields, and a pointer just past these types.
+func funcFieldsSize(types unsafe.Pointer, n int) (uintptr, unsafe.Pointer) {
+	size := uintptr(0)
+	for i := 0; i < n; i++ {
+		var typecode uintptr
+		typecode, types = readVarint(types)
+		size = align(size, uintptr(rawType(typecode).Align())) + rawType(typecode).Size()
+	}
+	return size, types
 }
 
 func (v Value) Recv() (x Value, ok bool) {
@@ -19,15 +19,11 @@ var globalsStartSymbol [0]byte
 //go:extern _globals_end
 var globalsEndSymbol [0]byte
 
-//go:extern _stack_top
-var stackTopSymbol [0]byte
-
 var (
 	heapStart    = uintptr(unsafe.Pointer(&heapStartSymbol))
 	heapEnd      = uintptr(unsafe.Pointer(&heapEndSymbol))
 	globalsStart = uintptr(unsafe.Pointer(&globalsStartSymbol))
 	globalsEnd   = uintptr(unsafe.Pointer(&globalsEndSymbol))
-	stackTop     = uintptr(unsafe.Pointer(&stackTopSymbol))
 )
 
 // growHeap tries to grow the heap size. It returns true if it succeeds, false
@@ -0,0 +1,20 @@
+//go:build baremetal && tinygo.library
+// +build baremetal,tinygo.library
+
+package runtime
+
+// stackTop is the stack pointer of the call to tinygo_init. The C program may
+// not define _stack_top, and the stack above tinygo_init belongs to C code that
+// doesn't store Go pointers anyway. The garbage collector scans the stack up to
+// this address, so tinygo_init should be called close to the start of the
+// program (for example, from main) and Go functions should only be called
+// from the same or a deeper stack frame.
+var stackTop uintptr
+
+// libraryPreinit is called from tinygo_init in programs built as a library.
+// The C startup code has already initialized memory, and the linker script of
+// the C program must define the symbols used in baremetal.go (_heap_start,
+// _heap_end, _globals_start and _globals_end).
+func libraryPreinit() {
+	stackTop = getCurrentStackPointer()
+}
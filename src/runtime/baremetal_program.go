@@ -0,0 +1,11 @@
+//go:build baremetal && !tinygo.library
+// +build baremetal,!tinygo.library
+
+package runtime
+
+import "unsafe"
+
+//go:extern _stack_top
+var stackTopSymbol [0]byte
+
+var stackTop = uintptr(unsafe.Pointer(&stackTopSymbol))
@@ -25,7 +25,6 @@ package runtime
 
 import (
 	"internal/task"
-	"runtime/interrupt"
 	"unsafe"
 )
 
@@ -309,17 +308,17 @@ func (ch *channel) trySend(value unsafe.Pointer) bool {
 		return false
 	}
 
-	i := interrupt.Disable()
+	i := lockScheduler()
 
 	switch ch.state {
 	case chanStateEmpty, chanStateBuf:
 		// try to dump the value directly into the buffer
 		if ch.push(value) {
 			ch.state = chanStateBuf
-			interrupt.Restore(i)
+			unlockScheduler(i)
 			return true
 		}
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return false
 	case chanStateRecv:
 		// unblock reciever
@@ -333,21 +332,21 @@ func (ch *channel) trySend(value unsafe.Pointer) bool {
 			ch.state = chanStateEmpty
 		}
 
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return true
 	case chanStateSend:
 		// something else is already waiting to send
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return false
 	case chanStateClosed:
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		runtimePanic("send on closed channel")
 	default:
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		runtimePanic("invalid channel state")
 	}
 
-	interrupt.Restore(i)
+	unlockScheduler(i)
 	return false
 }
 
@@ -361,7 +360,7 @@ func (ch *channel) tryRecv(value unsafe.Pointer) (bool, bool) {
 		return false, false
 	}
 
-	i := interrupt.Disable()
+	i := lockScheduler()
 
 	switch ch.state {
 	case chanStateBuf, chanStateSend:
@@ -385,7 +384,7 @@ func (ch *channel) tryRecv(value unsafe.Pointer) (bool, bool) {
 				ch.state = chanStateEmpty
 			}
 
-			interrupt.Restore(i)
+			unlockScheduler(i)
 			return true, true
 		} else if ch.blocked != nil {
 			// unblock next sender if applicable
@@ -399,24 +398,24 @@ func (ch *channel) tryRecv(value unsafe.Pointer) (bool, bool) {
 				ch.state = chanStateEmpty
 			}
 
-			interrupt.Restore(i)
+			unlockScheduler(i)
 			return true, true
 		}
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return false, false
 	case chanStateRecv, chanStateEmpty:
 		// something else is already waiting to recieve
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return false, false
 	case chanStateClosed:
 		if ch.pop(value) {
-			interrupt.Restore(i)
+			unlockScheduler(i)
 			return true, true
 		}
 
 		// channel closed - nothing to recieve
 		memzero(value, ch.elementSize)
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return true, false
 	default:
 		runtimePanic("invalid channel state")
@@ -465,19 +464,19 @@ type chanSelectState struct {
 // This operation will block unless a value is immediately available.
 // May panic if the channel is closed.
 func chanSend(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList) {
-	i := interrupt.Disable()
+	i := lockScheduler()
 
 	if ch.trySend(value) {
 		// value immediately sent
 		chanDebug(ch)
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return
 	}
 
 	if ch == nil {
 		// A nil channel blocks forever. Do not schedule this goroutine again.
-		interrupt.Restore(i)
-		deadlock()
+		unlockScheduler(i)
+		blockForever(task.WaitChanSend)
 	}
 
 	// wait for reciever
@@ -490,7 +489,9 @@ func chanSend(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList
 	}
 	ch.blocked = blockedlist
 	chanDebug(ch)
-	interrupt.Restore(i)
+	task.SetWaitReason(task.WaitChanSend, unsafe.Pointer(ch))
+	task.DisablePreemption()
+	unlockScheduler(i)
 	task.Pause()
 	sender.Ptr = nil
 }
@@ -500,19 +501,19 @@ func chanSend(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList
 // The recieved value is copied into the value pointer.
 // Returns the comma-ok value.
 func chanRecv(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList) bool {
-	i := interrupt.Disable()
+	i := lockScheduler()
 
 	if rx, ok := ch.tryRecv(value); rx {
 		// value immediately available
 		chanDebug(ch)
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		return ok
 	}
 
 	if ch == nil {
 		// A nil channel blocks forever. Do not schedule this goroutine again.
-		interrupt.Restore(i)
-		deadlock()
+		unlockScheduler(i)
+		blockForever(task.WaitChanRecv)
 	}
 
 	// wait for a value
@@ -525,7 +526,9 @@ func chanRecv(ch *channel, value unsafe.Pointer, blockedlist *channelBlockedList
 	}
 	ch.blocked = blockedlist
 	chanDebug(ch)
-	interrupt.Restore(i)
+	task.SetWaitReason(task.WaitChanRecv, unsafe.Pointer(ch))
+	task.DisablePreemption()
+	unlockScheduler(i)
 	task.Pause()
 	ok := receiver.Data == 1
 	receiver.Ptr, receiver.Data = nil, 0
@@ -539,18 +542,18 @@ func chanClose(ch *channel) {
 		// Not allowed by the language spec.
 		runtimePanic("close of nil channel")
 	}
-	i := interrupt.Disable()
+	i := lockScheduler()
 	switch ch.state {
 	case chanStateClosed:
 		// Not allowed by the language spec.
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		runtimePanic("close of closed channel")
 	case chanStateSend:
 		// This panic should ideally on the sending side, not in this goroutine.
 		// But when a goroutine tries to send while the channel is being closed,
 		// that is clearly invalid: the send should have been completed already
 		// before the close.
-		interrupt.Restore(i)
+		unlockScheduler(i)
 		runtimePanic("close channel during send")
 	case chanStateRecv:
 		// unblock all receivers with the zero value
@@ -562,7 +565,7 @@ func chanClose(ch *channel) {
 		// Easy case. No available sender or receiver.
 	}
 	ch.state = chanStateClosed
-	interrupt.Restore(i)
+	unlockScheduler(i)
 	chanDebug(ch)
 }
 
@@ -573,11 +576,11 @@ func chanClose(ch *channel) {
 // TODO: do this in a round-robin fashion (as specified in the Go spec) instead
 // of picking the first one that can proceed.
 func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelBlockedList) (uintptr, bool) {
-	istate := interrupt.Disable()
+	istate := lockScheduler()
 
 	if selected, ok := tryChanSelect(recvbuf, states); selected != ^uintptr(0) {
 		// one channel was immediately ready
-		interrupt.Restore(istate)
+		unlockScheduler(istate)
 		return selected, ok
 	}
 
@@ -605,7 +608,7 @@ func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelB
 			case chanStateRecv:
 				// already in correct state
 			default:
-				interrupt.Restore(istate)
+				unlockScheduler(istate)
 				runtimePanic("invalid channel state")
 			}
 		} else {
@@ -618,7 +621,7 @@ func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelB
 			case chanStateBuf:
 				// already in correct state
 			default:
-				interrupt.Restore(istate)
+				unlockScheduler(istate)
 				runtimePanic("invalid channel state")
 			}
 		}
@@ -631,7 +634,9 @@ func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelB
 	t.Data = 1
 
 	// wait for one case to fire
-	interrupt.Restore(istate)
+	task.SetWaitReason(task.WaitSelect, nil)
+	task.DisablePreemption()
+	unlockScheduler(istate)
 	task.Pause()
 
 	// figure out which one fired and return the ok value
@@ -640,7 +645,7 @@ func chanSelect(recvbuf unsafe.Pointer, states []chanSelectState, ops []channelB
 
 // tryChanSelect is like chanSelect, but it does a non-blocking select operation.
 func tryChanSelect(recvbuf unsafe.Pointer, states []chanSelectState) (uintptr, bool) {
-	istate := interrupt.Disable()
+	istate := lockScheduler()
 
 	// See whether we can receive from one of the channels.
 	for i, state := range states {
@@ -648,19 +653,19 @@ func tryChanSelect(recvbuf unsafe.Pointer, states []chanSelectState) (uintptr, b
 			// A receive operation.
 			if rx, ok := state.ch.tryRecv(recvbuf); rx {
 				chanDebug(state.ch)
-				interrupt.Restore(istate)
+				unlockScheduler(istate)
 				return uintptr(i), ok
 			}
 		} else {
 			// A send operation: state.value is not nil.
 			if state.ch.trySend(state.value) {
 				chanDebug(state.ch)
-				interrupt.Restore(istate)
+				unlockScheduler(istate)
 				return uintptr(i), true
 			}
 		}
 	}
 
-	interrupt.Restore(istate)
+	unlockScheduler(istate)
 	return ^uintptr(0), false
 }
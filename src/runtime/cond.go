@@ -74,10 +74,13 @@ func (c *Cond) Wait() {
 		case nil:
 			// Condition variable has not been notified.
 			// Block the current task on the condition variable.
+			task.SetWaitReason(task.WaitInterrupt, unsafe.Pointer(c))
+			task.DisablePreemption()
 			if atomic.CompareAndSwapPointer((*unsafe.Pointer)(unsafe.Pointer(&c.t)), nil, unsafe.Pointer(cur)) {
 				task.Pause()
 				return
 			}
+			task.EnablePreemption()
 		case &notifiedPlaceholder:
 			// A notification arrived and there is no waiting goroutine.
 			// Clear the notification and return.
@@ -0,0 +1,79 @@
+// Signal handler for the CPU profiler on Linux, see
+// src/runtime/cpuprof_linux.go. This is written in C because the location of
+// the program counter, frame pointer and stack pointer in the signal context
+// is different on every architecture.
+
+#define _GNU_SOURCE
+#include <signal.h>
+#include <stdint.h>
+#include <string.h>
+#include <sys/time.h>
+#include <ucontext.h>
+
+// Implemented in Go.
+void tinygo_profileSignal(uintptr_t pc, uintptr_t fp, uintptr_t sp);
+
+// The signal handler runs on a separate stack, so that it doesn't overflow the
+// (small) stack of a goroutine.
+static char signalStack[32 * 1024];
+
+static void handleProfileSignal(int sig, siginfo_t *info, void *context) {
+	ucontext_t *uc = context;
+	uintptr_t pc = 0, fp = 0, sp = 0;
+#if defined(__x86_64__)
+	pc = uc->uc_mcontext.gregs[REG_RIP];
+	fp = uc->uc_mcontext.gregs[REG_RBP];
+	sp = uc->uc_mcontext.gregs[REG_RSP];
+#elif defined(__i386__)
+	pc = uc->uc_mcontext.gregs[REG_EIP];
+	fp = uc->uc_mcontext.gregs[REG_EBP];
+	sp = uc->uc_mcontext.gregs[REG_ESP];
+#elif defined(__aarch64__)
+	pc = uc->uc_mcontext.pc;
+	fp = uc->uc_mcontext.regs[29];
+	sp = uc->uc_mcontext.sp;
+#elif defined(__arm__)
+	pc = uc->uc_mcontext.arm_pc;
+	sp = uc->uc_mcontext.arm_sp;
+#if defined(__thumb__)
+	fp = uc->uc_mcontext.arm_r7;
+#else
+	fp = uc->uc_mcontext.arm_fp;
+#endif
+#endif
+	tinygo_profileSignal(pc, fp, sp);
+}
+
+// Start the profiling timer with the given interval in microseconds, or stop
+// it when the interval is zero. Returns zero on success.
+int tinygo_setProfileTimer(long usec) {
+	struct itimerval timer;
+	memset(&timer, 0, sizeof(timer));
+	if (usec == 0) {
+		setitimer(ITIMER_PROF, &timer, NULL);
+		signal(SIGPROF, SIG_IGN);
+		return 0;
+	}
+
+	stack_t stack;
+	memset(&stack, 0, sizeof(stack));
+	stack.ss_sp = signalStack;
+	stack.ss_size = sizeof(signalStack);
+	if (sigaltstack(&stack, NULL) != 0) {
+		return -1;
+	}
+
+	struct sigaction action;
+	memset(&action, 0, sizeof(action));
+	action.sa_sigaction = handleProfileSignal;
+	action.sa_flags = SA_SIGINFO | SA_ONSTACK | SA_RESTART;
+	sigemptyset(&action.sa_mask);
+	if (sigaction(SIGPROF, &action, NULL) != 0) {
+		return -1;
+	}
+
+	timer.it_interval.tv_sec = usec / 1000000;
+	timer.it_interval.tv_usec = usec % 1000000;
+	timer.it_value = timer.it_interval;
+	return setitimer(ITIMER_PROF, &timer, NULL);
+}
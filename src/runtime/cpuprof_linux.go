@@ -0,0 +1,76 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi
+// +build linux,!baremetal,!nintendoswitch,!wasi
+
+package runtime
+
+// CPU profiling on Linux. A profiling timer (ITIMER_PROF) sends a SIGPROF
+// signal at the requested rate, and the signal handler records the stack of
+// the interrupted code. The signal handler itself is written in C (see
+// src/runtime/cpuprof/signal_linux.c) because it needs to read the program
+// counter and frame pointer of the interrupted code from the signal context.
+
+import "internal/task"
+
+// Number of different stacks that can be stored in the CPU profile.
+const cpuProfileBuckets = 1024
+
+// Start the profiling timer with the given interval in microseconds, or stop it
+// if the interval is zero. It returns zero on success.
+//export tinygo_setProfileTimer
+func setProfileTimer(usec int) int32
+
+func setCPUProfileRate(hz int) bool {
+	if hz <= 0 {
+		setProfileTimer(0)
+		cpuProfile.hz = 0
+		return true
+	}
+	cpuProfile.table.init(cpuProfileBuckets)
+	usec := 1000000 / hz
+	if usec == 0 {
+		usec = 1
+	}
+	if setProfileTimer(usec) != 0 {
+		return false
+	}
+	cpuProfile.hz = hz
+	return true
+}
+
+// profileSignal is called from the SIGPROF signal handler with the program
+// counter, frame pointer and stack pointer of the interrupted code. It must not
+// allocate memory.
+//export tinygo_profileSignal
+func profileSignal(pc, fp, sp uintptr) {
+	var stack [maxProfileStack]uintptr
+	// Store the program counter as if it were a return address, like the other
+	// entries in the stack.
+	stack[0] = pc + 1
+	n := 1
+	// The interrupted code may be in the middle of setting up a stack frame,
+	// so the frame pointer can't be trusted. Only walk frames that are on the
+	// same stack as the stack pointer.
+	if top := profileStackTop(sp); top != 0 {
+		n += callersFrom(fp, sp, top, 0, stack[1:])
+	}
+	b := cpuProfile.table.lookup(stack[:n])
+	if b == nil {
+		cpuProfile.table.lost++
+		return
+	}
+	b.count++
+}
+
+// profileStackTop returns the top of the stack that sp points into, or 0 if it
+// is not known.
+func profileStackTop(sp uintptr) uintptr {
+	if top := task.StackTop(sp); top != 0 {
+		// Running on a goroutine stack.
+		return top
+	}
+	if sp < stackTop {
+		// Running on the system stack.
+		return stackTop
+	}
+	return 0
+}
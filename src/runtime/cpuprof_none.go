@@ -0,0 +1,9 @@
+//go:build !linux || baremetal || nintendoswitch || wasi
+// +build !linux baremetal nintendoswitch wasi
+
+package runtime
+
+// CPU profiling is not supported on this system.
+func setCPUProfileRate(hz int) bool {
+	return hz <= 0
+}
@@ -13,8 +13,3 @@ func NumCPU() int {
 func NumCgoCall() int {
 	return 0
 }
-
-// Stub for NumGoroutine, does not return the real value
-func NumGoroutine() int {
-	return 1
-}
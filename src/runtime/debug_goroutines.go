@@ -0,0 +1,107 @@
+//go:build goroutinedebug
+// +build goroutinedebug
+
+package runtime
+
+// Goroutine debugging, enabled with -tags=goroutinedebug. See
+// internal/task/task_debug.go for how the goroutine state is recorded.
+
+import (
+	"internal/task"
+	"unsafe"
+)
+
+// NumGoroutine returns the number of goroutines that currently exist.
+func NumGoroutine() int {
+	n := 0
+	task.ForEach(func(t *task.Task) {
+		n++
+	})
+	return n
+}
+
+// PrintGoroutines prints all goroutines that haven't exited, together with
+// what they are waiting for and where. It is meant for debugging, for example
+// from a watchdog handler, and may be called from an interrupt.
+//
+// A goroutine that was woken up but hasn't continued yet is still reported as
+// waiting.
+func PrintGoroutines() {
+	task.ForEach(printGoroutine)
+}
+
+func printGoroutine(t *task.Task) {
+	d := t.Debug()
+	printstring("goroutine ")
+	printhex(uintptr(unsafe.Pointer(t)))
+	printstring(": ")
+	switch {
+	case t == task.Current():
+		printstring("running")
+	case d.Reason == task.WaitNone:
+		printstring("runnable")
+	default:
+		printstring(d.Reason.String())
+		if d.Object != nil {
+			printstring(" ")
+			printhex(uintptr(d.Object))
+		} else if d.Reason == task.WaitChanSend || d.Reason == task.WaitChanRecv {
+			printstring(" (nil chan)")
+		}
+	}
+	if d.Reason == task.WaitNone {
+		printnl()
+		return
+	}
+
+	// Skip the frames of the runtime itself, they are the same for every
+	// goroutine waiting for the same reason.
+	pcs := d.PCs[:]
+	for len(pcs) != 0 && pcs[len(pcs)-1] == 0 {
+		pcs = pcs[:len(pcs)-1]
+	}
+	for len(pcs) > 1 {
+		loc, ok := lookupPC(pcs[0] - 1)
+		if !ok || !isRuntimeFunction(loc.name) {
+			break
+		}
+		pcs = pcs[1:]
+	}
+	if len(pcs) == 0 {
+		printnl()
+		return
+	}
+	if _, ok := lookupPC(pcs[0] - 1); !ok {
+		// printCallers prints the compact form on the same line.
+		printstring(", ")
+	}
+	printCallers(pcs)
+}
+
+// isRuntimeFunction returns whether the function name belongs to one of the
+// packages that implement blocking operations.
+func isRuntimeFunction(name string) bool {
+	for _, prefix := range [...]string{"runtime.", "sync.", "internal/task."} {
+		if len(name) >= len(prefix) && name[:len(prefix)] == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// printedBlockedGoroutines is set once the goroutines have been printed by
+// reportBlockedGoroutines, to avoid printing them again every time the system
+// goes idle.
+var printedBlockedGoroutines bool
+
+// reportBlockedGoroutines is called by the scheduler when no goroutine can run
+// and none of them is sleeping, right before it waits for an interrupt (or
+// panics with a deadlock when there is no event source).
+func reportBlockedGoroutines() {
+	if printedBlockedGoroutines {
+		return
+	}
+	printedBlockedGoroutines = true
+	printstring("all goroutines are blocked:\n")
+	PrintGoroutines()
+}
@@ -0,0 +1,19 @@
+//go:build !goroutinedebug
+// +build !goroutinedebug
+
+package runtime
+
+// Stub for NumGoroutine, does not return the real value. Build with
+// -tags=goroutinedebug to track all goroutines.
+func NumGoroutine() int {
+	return 1
+}
+
+// PrintGoroutines prints all goroutines that haven't exited. This is only
+// supported with -tags=goroutinedebug.
+func PrintGoroutines() {
+	printstring("goroutine information is only available with -tags=goroutinedebug\n")
+}
+
+func reportBlockedGoroutines() {
+}
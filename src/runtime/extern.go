@@ -1,7 +1,16 @@
 package runtime
 
+// Callers fills the slice pc with the return program counters of function
+// invocations on the calling goroutine's stack. The argument skip is the number
+// of stack frames to skip before recording in pc, with 0 identifying the frame
+// for Callers itself and 1 identifying the caller of Callers. It returns the
+// number of entries written to pc.
+//
+// Stack unwinding is not supported on all architectures. If it isn't, Callers
+// always returns 0.
+//go:noinline
 func Callers(skip int, pc []uintptr) int {
-	return 0
+	return callers(skip, pc)
 }
 
 // buildVersion is the Tinygo tree's version string at build time.
@@ -1,5 +1,7 @@
-//go:build gc.conservative && !baremetal && !tinygo.wasm
-// +build gc.conservative,!baremetal,!tinygo.wasm
+//go:build (gc.conservative || gc.precise) && !baremetal && !tinygo.wasm
+// +build gc.conservative gc.precise
+// +build !baremetal
+// +build !tinygo.wasm
 
 package runtime
 
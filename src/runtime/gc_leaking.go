@@ -22,6 +22,7 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 	// much. And by using platform-native data types (e.g. *uint8 for 8-bit
 	// systems).
 	size = align(size)
+	lockHeap()
 	addr := heapptr
 	heapptr += size
 	for heapptr >= heapEnd {
@@ -32,6 +33,9 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 		// Failed to make the heap bigger, so we must really be out of memory.
 		runtimePanic("out of memory")
 	}
+	gcTotalAlloc += uint64(size)
+	gcMallocs++
+	unlockHeap()
 	pointer := unsafe.Pointer(addr)
 	memzero(pointer, size)
 	return pointer
@@ -57,6 +61,17 @@ func GC() {
 	// No-op.
 }
 
+// heapStats fills in the heap statistics of m, see ReadMemStats. Everything
+// that has been allocated is still in use, because memory is never freed.
+func heapStats(m *MemStats) {
+	m.HeapAlloc = uint64(heapptr - heapStart)
+	m.HeapInuse = m.HeapAlloc
+	m.HeapIdle = uint64(heapEnd - heapptr)
+	m.HeapSys = m.HeapInuse + m.HeapIdle
+	m.GCSys = 0
+	m.Sys = m.HeapSys
+}
+
 func KeepAlive(x interface{}) {
 	// Unimplemented. Only required with SetFinalizer().
 }
@@ -23,6 +23,17 @@ func GC() {
 	// Unimplemented.
 }
 
+// heapStats fills in the heap statistics of m, see ReadMemStats. There is no
+// heap, so all heap statistics are zero.
+func heapStats(m *MemStats) {
+	m.HeapAlloc = 0
+	m.HeapInuse = 0
+	m.HeapIdle = 0
+	m.HeapSys = 0
+	m.GCSys = 0
+	m.Sys = 0
+}
+
 func KeepAlive(x interface{}) {
 	// Unimplemented. Only required with SetFinalizer().
 }
@@ -0,0 +1,121 @@
+//go:build gc.precise
+// +build gc.precise
+
+package runtime
+
+// This file implements the object scanner for the precise GC. It uses the same
+// allocator and mark/sweep implementation as the conservative GC (see
+// gc_blocks.go), but stores the object layout in the first word of every heap
+// allocation. This means that for most heap objects, the GC knows which words
+// contain a pointer and which don't. In particular, large buffers without any
+// pointers (such as []byte) are not scanned at all, which makes the GC faster
+// and avoids false positives that keep unreachable objects alive.
+//
+// The layout is the value passed to runtime.alloc by the compiler, see
+// createObjectLayout in compiler/llvm.go. It describes a bitstring of a given
+// size, where every bit indicates whether the word at that position may be a
+// pointer. The bitstring is repeated for the whole object, which means arrays
+// and slices only need to store the layout of a single element.
+//
+// If the least significant bit of the layout is set, the bitstring is stored
+// inside the layout value itself, of the form pppp_pppp_ppps_sss1 (for 16-bit
+// systems). The 's' bits contain the size of the bitstring and the 'p' bits
+// contain the bitstring itself. On 32-bit and 64-bit systems, there are 5 and 6
+// size bits respectively.
+//
+// If the least significant bit is not set, the layout is a pointer to a global
+// of the following form:
+//
+//     struct {
+//         size uintptr
+//         bits [...]uint8
+//     }
+//
+// The 'size' field is the number of bits in the bitstring and 'bits' contains
+// the bitstring itself in little endian form.
+//
+// A nil layout means the layout is unknown (for example, for memory allocated
+// with malloc from C). In that case, every word is treated as a possible
+// pointer just like in the conservative GC.
+
+import "unsafe"
+
+// The precise GC stores the object layout at the start of every heap object.
+const preciseHeap = true
+
+// gcObjectScanner scans an object for pointers, using the layout stored at the
+// start of the object.
+type gcObjectScanner struct {
+	index      uintptr        // current index in the bitstring
+	size       uintptr        // size of the bitstring
+	bitmap     uintptr        // the bitstring, if stored inline
+	bitmapAddr unsafe.Pointer // the bitstring, if stored in a global
+}
+
+func newGCObjectScanner(block gcBlock) gcObjectScanner {
+	if gcAsserts && block != block.findHead() {
+		runtimePanic("gc: object scanner must start at head")
+	}
+	scanner := gcObjectScanner{}
+	layout := *(*uintptr)(unsafe.Pointer(block.address()))
+	if layout == 0 {
+		// Unknown layout. Assume all words in the object could be pointers.
+		// This is the same layout as for example a []*byte slice.
+		scanner.size = 1
+		scanner.bitmap = 1
+	} else if layout&1 != 0 {
+		// The layout is stored directly in the integer value.
+		const layoutBits = uint64(unsafe.Sizeof(layout) * 8)
+		var sizeFieldBits uint64
+		switch layoutBits { // this switch is resolved at compile time
+		case 16:
+			sizeFieldBits = 4
+		case 32:
+			sizeFieldBits = 5
+		case 64:
+			sizeFieldBits = 6
+		default:
+			runtimePanic("gc: unknown pointer size")
+		}
+		scanner.size = (layout >> 1) & (1<<sizeFieldBits - 1)
+		scanner.bitmap = layout >> (1 + sizeFieldBits)
+	} else {
+		// The layout is stored separately in a global.
+		scanner.size = *(*uintptr)(unsafe.Pointer(layout))
+		scanner.bitmapAddr = unsafe.Pointer(layout + unsafe.Sizeof(layout))
+	}
+	return scanner
+}
+
+// pointerFree returns whether the object is known to contain no pointers, in
+// which case it doesn't need to be scanned at all.
+func (scanner *gcObjectScanner) pointerFree() bool {
+	if scanner.bitmapAddr != nil {
+		// The compiler only emits a layout global when there is at least one
+		// pointer in the object.
+		return false
+	}
+	return scanner.bitmap == 0
+}
+
+// nextIsPointer returns whether the next word in the object could be a
+// pointer. It must be called for every word in the object, in order.
+func (scanner *gcObjectScanner) nextIsPointer(word uintptr) bool {
+	index := scanner.index
+	scanner.index++
+	if scanner.index == scanner.size {
+		scanner.index = 0
+	}
+
+	if !looksLikePointer(word) {
+		// Definitely not a pointer into the heap.
+		return false
+	}
+
+	// Might be a pointer. Look at the object layout to know for sure.
+	if scanner.bitmapAddr != nil {
+		bits := *(*uint8)(unsafe.Pointer(uintptr(scanner.bitmapAddr) + index/8))
+		return (bits>>(index%8))&1 != 0
+	}
+	return (scanner.bitmap>>index)&1 != 0
+}
@@ -1,5 +1,6 @@
-//go:build gc.conservative && !tinygo.wasm
-// +build gc.conservative,!tinygo.wasm
+//go:build (gc.conservative || gc.precise) && !tinygo.wasm
+// +build gc.conservative gc.precise
+// +build !tinygo.wasm
 
 package runtime
 
@@ -16,10 +17,18 @@ func markStack() {
 
 	if !task.OnSystemStack() {
 		// Mark system stack.
-		markRoots(getSystemStackPointer(), stackTop)
+		markRoots(getSystemStackPointer(), systemStackTop())
 	}
 }
 
+// systemStackTop returns the top of the system stack of the current core.
+func systemStackTop() uintptr {
+	if cpu := task.CPU(); cpu != 0 {
+		return secondaryStackTops[cpu-1]
+	}
+	return stackTop
+}
+
 //go:export tinygo_scanCurrentStack
 func scanCurrentStack()
 
@@ -31,7 +40,7 @@ func scanstack(sp uintptr) {
 	if task.OnSystemStack() {
 		// This is the system stack.
 		// Scan all words on the stack.
-		markRoots(sp, stackTop)
+		markRoots(sp, systemStackTop())
 	} else {
 		// This is a goroutine stack.
 		// It is an allocation, so scan it as if it were a value in a global.
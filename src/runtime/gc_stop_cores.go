@@ -0,0 +1,91 @@
+//go:build scheduler.cores && (gc.conservative || gc.precise)
+// +build scheduler.cores
+// +build gc.conservative gc.precise
+
+package runtime
+
+// Stopping the other cores while running the GC. The core running the GC asks
+// the other cores to stop, and they do so at the next safe point (in the
+// scheduler loop, while waiting for the heap lock, or in the inter-core
+// interrupt handler). Before stopping, each core marks its own stack as only
+// that core knows which stack it is running on and what is in its registers.
+//
+// The other cores are also interrupted (see gcInterruptCores) so that they stop
+// right away, even when they're running a goroutine that doesn't allocate
+// memory or yield to the scheduler. Chips without such an interrupt can't
+// support -scheduler=cores.
+
+import (
+	"internal/task"
+	"runtime/interrupt"
+	"runtime/volatile"
+)
+
+var (
+	// gcStopCycle is nonzero while a GC cycle is running. It is incremented
+	// for every cycle so that a core can tell cycles apart.
+	gcStopCycle uint32
+	gcLastCycle uint32
+
+	// gcCollector is the core running the GC (plus one), which must not
+	// stop itself.
+	gcCollector uint32
+
+	// gcCoreMarked is set to the current GC cycle by a core once it has
+	// marked its stack and stopped.
+	gcCoreMarked [task.NumCPU]uint32
+
+	// gcMarkLock makes sure only one core marks its stack at a time.
+	gcMarkLock task.Spinlock
+)
+
+// gcStopTheWorld stops all other cores and waits until they have marked their
+// stacks. It must be called with the heap lock held.
+func gcStopTheWorld() {
+	gcLastCycle++
+	if gcLastCycle == 0 {
+		gcLastCycle = 1
+	}
+	cpu := task.CPU()
+	volatile.StoreUint32(&gcCollector, uint32(cpu)+1)
+	volatile.StoreUint32(&gcStopCycle, gcLastCycle)
+	gcInterruptCores()
+	task.SendEvent()
+	for i := range gcCoreMarked {
+		if i == cpu {
+			continue
+		}
+		for volatile.LoadUint32(&gcCoreMarked[i]) != gcLastCycle {
+			task.WaitForEvent()
+		}
+	}
+}
+
+// gcStartTheWorld lets the other cores continue after a GC cycle.
+func gcStartTheWorld() {
+	volatile.StoreUint32(&gcStopCycle, 0)
+	volatile.StoreUint32(&gcCollector, 0)
+	task.SendEvent()
+}
+
+// gcSafePoint stops the current core while another core is running the GC.
+func gcSafePoint() {
+	cycle := volatile.LoadUint32(&gcStopCycle)
+	if cycle == 0 || volatile.LoadUint32(&gcCollector) == uint32(task.CPU())+1 {
+		return
+	}
+	// Don't let the inter-core interrupt run gcSafePoint again while this
+	// core is already stopping.
+	mask := interrupt.Disable()
+	if volatile.LoadUint32(&gcCoreMarked[task.CPU()]) != cycle {
+		gcMarkLock.Lock()
+		markStack()
+		gcMarkLock.Unlock()
+		volatile.StoreUint32(&gcCoreMarked[task.CPU()], cycle)
+		task.SendEvent()
+	}
+	for volatile.LoadUint32(&gcStopCycle) == cycle {
+		task.WaitForEvent()
+	}
+	interrupt.Restore(mask)
+}
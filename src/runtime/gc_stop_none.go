@@ -0,0 +1,16 @@
+//go:build !scheduler.cores || (!gc.conservative && !gc.precise)
+// +build !scheduler.cores !gc.conservative,!gc.precise
+
+package runtime
+
+// There are no other cores to stop while running the GC, or there is no GC to
+// run.
+
+func gcStopTheWorld() {
+}
+
+func gcStartTheWorld() {
+}
+
+func gcSafePoint() {
+}
@@ -107,10 +107,13 @@ type typecodeID struct {
 	// * interface: null
 	// * chan/pointer/slice/array: the element type
 	// * struct: bitcast of global with structField array
-	// * func/map: TODO
+	// * map: bitcast of global with {key, elem} typecode array
+	// * func: bitcast of global with {params..., results...} typecode array
 	references *typecodeID
 
-	// The array length, for array types.
+	// The array length, for array types. For function types, the number of
+	// parameters shifted left by one with the lowest bit set for variadic
+	// functions.
 	length uintptr
 
 	methodSet *interfaceMethodInfo // nil or a GEP of an array
@@ -123,6 +126,11 @@ type typecodeID struct {
 	// typeAssert is a ptrtoint of a declared interface assert function.
 	// It only exists to make the rtcalls pass easier.
 	typeAssert uintptr
+
+	// Exported methods of this type, for use in the reflect package. It is
+	// only set when compiling with -reflect-methods and is converted to a
+	// sidetable by the reflect lowering pass.
+	reflectMethods unsafe.Pointer
 }
 
 // structField is used by the compiler to pass information to the interface
@@ -0,0 +1,240 @@
+package runtime
+
+// Memory and CPU profiling, used by the runtime/pprof package.
+//
+// Both profiles are stored as a hash table of stacks (see profTable). Heap
+// samples are recorded in alloc (see mprof_blocks.go) and CPU samples in a
+// signal handler (see cpuprof_linux.go). On systems where a profile is not
+// supported, the profile is simply empty.
+
+import "unsafe"
+
+// MemProfileRate controls the fraction of memory allocations that are recorded
+// and reported in the memory profile. The profiler aims to sample an average
+// of one allocation per MemProfileRate bytes allocated.
+//
+// To include every allocated block in the profile, set MemProfileRate to 1. To
+// turn off profiling entirely, set MemProfileRate to 0.
+//
+// Heap profiling is only supported on hosted systems with the conservative or
+// precise garbage collector.
+var MemProfileRate int = 512 * 1024
+
+// A MemProfileRecord describes the live objects allocated by a particular call
+// sequence (stack trace).
+type MemProfileRecord struct {
+	AllocBytes, FreeBytes     int64       // number of bytes allocated, freed
+	AllocObjects, FreeObjects int64       // number of objects allocated, freed
+	Stack0                    [32]uintptr // stack trace for this record; ends at first 0 entry
+}
+
+// InUseBytes returns the number of bytes in use (AllocBytes - FreeBytes).
+func (r *MemProfileRecord) InUseBytes() int64 { return r.AllocBytes - r.FreeBytes }
+
+// InUseObjects returns the number of objects in use (AllocObjects - FreeObjects).
+func (r *MemProfileRecord) InUseObjects() int64 {
+	return r.AllocObjects - r.FreeObjects
+}
+
+// Stack returns the stack trace associated with the record, a prefix of
+// r.Stack0.
+func (r *MemProfileRecord) Stack() []uintptr {
+	for i, v := range r.Stack0 {
+		if v == 0 {
+			return r.Stack0[0:i]
+		}
+	}
+	return r.Stack0[0:]
+}
+
+// MemProfile returns a profile of memory allocated and freed per allocation
+// site.
+//
+// MemProfile returns n, the number of records in the current memory profile.
+// If len(p) >= n, MemProfile copies the profile into p and returns n, true. If
+// len(p) < n, MemProfile does not change p and returns n, false.
+//
+// If inuseZero is true, the profile includes allocation records where
+// r.AllocBytes > 0 but r.AllocBytes == r.FreeBytes. These are sites where
+// memory was allocated, but it has all been released back to the runtime.
+func MemProfile(p []MemProfileRecord, inuseZero bool) (n int, ok bool) {
+	buckets := memProfile.table.buckets
+	for i := range buckets {
+		b := &buckets[i]
+		if b.used && (inuseZero || b.bytes != b.freeBytes) {
+			n++
+		}
+	}
+	if n > len(p) {
+		return n, false
+	}
+	i := 0
+	for j := range buckets {
+		b := &buckets[j]
+		if !b.used || (!inuseZero && b.bytes == b.freeBytes) {
+			continue
+		}
+		r := &p[i]
+		r.AllocBytes = b.bytes
+		r.FreeBytes = b.freeBytes
+		r.AllocObjects = b.count
+		r.FreeObjects = b.freeCount
+		r.Stack0 = [32]uintptr{}
+		copy(r.Stack0[:], b.stack[:b.depth])
+		i++
+	}
+	return n, true
+}
+
+// SetCPUProfileRate sets the CPU profiling rate to hz samples per second. If hz
+// <= 0, SetCPUProfileRate turns off profiling. If the profiler is on, the rate
+// cannot be changed without first turning it off.
+//
+// Most clients should use the runtime/pprof package instead of calling
+// SetCPUProfileRate directly.
+func SetCPUProfileRate(hz int) {
+	if hz > 0 && cpuProfileRunning() {
+		println("runtime: cannot set cpu profile rate until previous profile has finished.")
+		return
+	}
+	setCPUProfileRate(hz)
+}
+
+// Maximum number of stack frames stored in a profile bucket.
+const maxProfileStack = 32
+
+// profBucket is a single entry in a profile: a stack with the number of
+// samples taken at that stack.
+type profBucket struct {
+	stack     [maxProfileStack]uintptr
+	depth     uintptr
+	used      bool
+	count     int64 // number of samples or allocated objects
+	bytes     int64 // number of allocated bytes
+	freeCount int64 // number of freed objects
+	freeBytes int64 // number of freed bytes
+}
+
+// profTable is a fixed size hash table of profile buckets. It does not
+// allocate memory once it has been created, so that it can be used from a
+// signal handler and from within alloc.
+type profTable struct {
+	buckets []profBucket
+	lost    int64 // number of samples that didn't fit in the table
+}
+
+// init allocates the buckets of the table, or clears them if the table was
+// already allocated.
+func (t *profTable) init(size int) {
+	if t.buckets == nil {
+		t.buckets = make([]profBucket, size)
+	} else {
+		memzero(unsafe.Pointer(&t.buckets[0]), uintptr(len(t.buckets))*unsafe.Sizeof(profBucket{}))
+	}
+	t.lost = 0
+}
+
+// lookup returns the bucket for the given stack, creating it if it doesn't
+// exist yet. It returns nil if the table is full (or not allocated).
+func (t *profTable) lookup(stack []uintptr) *profBucket {
+	if len(t.buckets) == 0 {
+		return nil
+	}
+	if len(stack) > maxProfileStack {
+		stack = stack[:maxProfileStack]
+	}
+	hash := uintptr(len(stack))
+	for _, pc := range stack {
+		hash = (hash ^ pc) * 16777619 // FNV-1 prime
+	}
+	index := hash % uintptr(len(t.buckets))
+	for i := 0; i < len(t.buckets); i++ {
+		b := &t.buckets[index]
+		if !b.used {
+			// Empty bucket, so the stack isn't in the table yet.
+			b.used = true
+			b.depth = uintptr(copy(b.stack[:], stack))
+			return b
+		}
+		if b.depth == uintptr(len(stack)) && profStackEqual(b.stack[:b.depth], stack) {
+			return b
+		}
+		index++
+		if index == uintptr(len(t.buckets)) {
+			index = 0
+		}
+	}
+	return nil
+}
+
+func profStackEqual(a, b []uintptr) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// The heap profile. Only filled in when heap profiling is supported.
+var memProfile struct {
+	table     profTable
+	objects   []memProfileObject // sampled objects that are still alive
+	nextBytes int                // number of bytes until the next sample
+	untracked int64              // sampled objects that didn't fit in objects
+	recording bool               // set while recording a sample, to avoid recursion
+}
+
+// memProfileObject is a sampled object, used to track when it is freed. The
+// pointer is stored inverted so that the garbage collector doesn't see it as a
+// reference to the object.
+type memProfileObject struct {
+	hiddenPtr uintptr
+	size      uintptr
+	bucket    *profBucket
+}
+
+//go:linkname pprof_startCPUProfile runtime/pprof.startCPUProfile
+func pprof_startCPUProfile(hz int) bool {
+	if cpuProfileRunning() {
+		return false
+	}
+	return setCPUProfileRate(hz)
+}
+
+//go:linkname pprof_stopCPUProfile runtime/pprof.stopCPUProfile
+func pprof_stopCPUProfile() {
+	setCPUProfileRate(0)
+}
+
+// pprof_readCPUProfile calls fn for each stack in the CPU profile, which must
+// have been stopped. It returns the number of lost samples.
+//go:linkname pprof_readCPUProfile runtime/pprof.readCPUProfile
+func pprof_readCPUProfile(fn func(count int64, stack []uintptr)) (lost int64) {
+	buckets := cpuProfile.table.buckets
+	for i := range buckets {
+		b := &buckets[i]
+		if b.used {
+			fn(b.count, b.stack[:b.depth])
+		}
+	}
+	return cpuProfile.table.lost
+}
+
+// pprof_readHeapProfileLost returns the number of heap samples that didn't fit
+// in the profile, and the number of sampled objects whose frees are not counted
+// because too many objects were tracked already.
+//go:linkname pprof_readHeapProfileLost runtime/pprof.readHeapProfileLost
+func pprof_readHeapProfileLost() (lost, untracked int64) {
+	return memProfile.table.lost, memProfile.untracked
+}
+
+// The CPU profile. Only filled in when CPU profiling is supported.
+var cpuProfile struct {
+	table profTable
+	hz    int
+}
+
+func cpuProfileRunning() bool {
+	return cpuProfile.hz != 0
+}
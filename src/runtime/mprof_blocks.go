@@ -0,0 +1,84 @@
+//go:build gc.conservative || gc.precise
+// +build gc.conservative gc.precise
+
+package runtime
+
+// Heap profiling for the block based garbage collector (gc_blocks.go).
+//
+// Allocations are sampled once every MemProfileRate bytes. The stack of each
+// sampled allocation is stored in the heap profile, and the object itself is
+// remembered until a GC cycle finds it unreachable so that frees can be
+// counted as well.
+
+import "unsafe"
+
+const (
+	memProfileBuckets = 512  // number of different stacks in the heap profile
+	memProfileObjects = 4096 // number of sampled objects to track
+)
+
+// memProfileAlloc is called from alloc for every allocated object. It is only
+// called on hosted systems.
+func memProfileAlloc(ptr unsafe.Pointer, size uintptr) {
+	rate := MemProfileRate
+	if rate <= 0 || memProfile.recording {
+		return
+	}
+	memProfile.nextBytes -= int(size)
+	if memProfile.nextBytes > 0 {
+		return
+	}
+	memProfile.nextBytes = rate
+	memProfile.recording = true
+
+	if memProfile.table.buckets == nil {
+		// Allocate the profile on the first sample. These allocations are not
+		// recorded themselves, because recording is set.
+		memProfile.table.init(memProfileBuckets)
+		memProfile.objects = make([]memProfileObject, 0, memProfileObjects)
+	}
+
+	// Skip memProfileAlloc and alloc.
+	var stack [maxProfileStack]uintptr
+	n := callers(2, stack[:])
+	b := memProfile.table.lookup(stack[:n])
+	if b == nil {
+		memProfile.table.lost++
+	} else {
+		b.count++
+		b.bytes += int64(size)
+		// Objects that don't fit in the list are never counted as freed.
+		// This is reported in the profile (see runtime/pprof).
+		if len(memProfile.objects) < cap(memProfile.objects) {
+			memProfile.objects = append(memProfile.objects, memProfileObject{
+				hiddenPtr: ^uintptr(ptr),
+				size:      size,
+				bucket:    b,
+			})
+		} else {
+			memProfile.untracked++
+		}
+	}
+
+	memProfile.recording = false
+}
+
+// memProfileSweep counts the sampled objects that are about to be freed. It
+// must be called after marking and before sweeping.
+func memProfileSweep() {
+	objects := memProfile.objects
+	for i := 0; i < len(objects); {
+		obj := &objects[i]
+		if blockFromAddr(^obj.hiddenPtr).state() == blockStateMark {
+			// Still reachable.
+			i++
+			continue
+		}
+		obj.bucket.freeCount++
+		obj.bucket.freeBytes += int64(obj.size)
+		// Remove the object by replacing it with the last one.
+		objects[i] = objects[len(objects)-1]
+		objects = objects[:len(objects)-1]
+	}
+	memProfile.objects = objects
+}
@@ -1,17 +1,27 @@
-//go:build gc.conservative
-// +build gc.conservative
-
 package runtime
 
 // Memory statistics
 
 // Subset of memory statistics from upstream Go.
-// Works with conservative gc only.
+// The heap statistics are provided by the GC implementation (see heapStats),
+// the counters below are kept up to date by all GC implementations.
 
 // A MemStats records statistics about the memory allocator.
 type MemStats struct {
 	// General statistics.
 
+	// Alloc is bytes of allocated heap objects.
+	//
+	// This is the same as HeapAlloc (see below).
+	Alloc uint64
+
+	// TotalAlloc is cumulative bytes allocated for heap objects.
+	//
+	// TotalAlloc increases as heap objects are allocated, but
+	// unlike Alloc and HeapAlloc, it does not decrease when
+	// objects are freed.
+	TotalAlloc uint64
+
 	// Sys is the total bytes of memory obtained from the OS.
 	//
 	// Sys is the sum of the XSys fields below. Sys measures the
@@ -19,8 +29,27 @@ type MemStats struct {
 	// heap, stacks, and other internal data structures.
 	Sys uint64
 
+	// Mallocs is the cumulative count of heap objects allocated.
+	// The number of live objects is Mallocs - Frees.
+	Mallocs uint64
+
+	// Frees is the cumulative count of heap objects freed.
+	Frees uint64
+
 	// Heap memory statistics.
 
+	// HeapAlloc is bytes of allocated heap objects.
+	//
+	// "Allocated" heap objects include all reachable objects, as
+	// well as unreachable objects that the garbage collector has
+	// not yet freed. Specifically, HeapAlloc increases as heap
+	// objects are allocated and decreases as the heap is swept
+	// and unreachable objects are freed.
+	//
+	// In TinyGo, allocations are rounded up to the allocation
+	// granularity of the GC, which is included in HeapAlloc.
+	HeapAlloc uint64
+
 	// HeapSys is bytes of heap memory, total.
 	//
 	// In TinyGo unlike upstream Go, we make no distinction between
@@ -38,6 +67,9 @@ type MemStats struct {
 	// HeapReleased is bytes of physical memory returned to the OS.
 	HeapReleased uint64
 
+	// HeapObjects is the number of allocated heap objects.
+	HeapObjects uint64
+
 	// Off-heap memory statistics.
 	//
 	// The following statistics measure runtime-internal
@@ -46,6 +78,45 @@ type MemStats struct {
 
 	// GCSys is bytes of memory in garbage collection metadata.
 	GCSys uint64
+
+	// Garbage collector statistics.
+
+	// LastGC is the time the last garbage collection finished, as
+	// nanoseconds since 1970 (the UNIX epoch).
+	LastGC uint64
+
+	// PauseTotalNs is the cumulative nanoseconds in GC
+	// stop-the-world pauses since the program started.
+	//
+	// TinyGo stops the world during the entire collection, so this
+	// is the total time spent in the garbage collector.
+	PauseTotalNs uint64
+
+	// NumGC is the number of completed GC cycles.
+	NumGC uint32
+}
+
+// Counters for MemStats, updated by the GC implementation.
+var (
+	gcTotalAlloc   uint64 // total number of bytes allocated
+	gcMallocs      uint64 // total number of objects allocated
+	gcFrees        uint64 // total number of objects freed
+	gcNumGC        uint32 // number of completed GC cycles
+	gcPauseTotalNs uint64 // total time spent in GC
+	gcLastGC       uint64 // end of the last GC cycle, in nanoseconds since the epoch
+)
+
+// gcStart returns the start time of a GC cycle, to be passed to gcFinish.
+func gcStart() int64 {
+	return nanotime()
+}
+
+// gcFinish updates the GC statistics at the end of a GC cycle.
+func gcFinish(start int64) {
+	gcNumGC++
+	gcPauseTotalNs += uint64(nanotime() - start)
+	sec, nsec, _ := now()
+	gcLastGC = uint64(sec)*1e9 + uint64(nsec)
 }
 
 // ReadMemStats populates m with memory statistics.
@@ -53,18 +124,16 @@ type MemStats struct {
 // The returned memory statistics are up to date as of the
 // call to ReadMemStats. This would not do GC implicitly for you.
 func ReadMemStats(m *MemStats) {
-	m.HeapIdle = 0
-	m.HeapInuse = 0
-	for block := gcBlock(0); block < endBlock; block++ {
-		bstate := block.state()
-		if bstate == blockStateFree {
-			m.HeapIdle += uint64(bytesPerBlock)
-		} else {
-			m.HeapInuse += uint64(bytesPerBlock)
-		}
-	}
+	m.TotalAlloc = gcTotalAlloc
+	m.Mallocs = gcMallocs
+	m.Frees = gcFrees
+	m.HeapObjects = gcMallocs - gcFrees
 	m.HeapReleased = 0 // always 0, we don't currently release memory back to the OS.
-	m.HeapSys = m.HeapInuse + m.HeapIdle
-	m.GCSys = uint64(heapEnd - uintptr(metadataStart))
-	m.Sys = uint64(heapEnd - heapStart)
+	m.NumGC = gcNumGC
+	m.PauseTotalNs = gcPauseTotalNs
+	m.LastGC = gcLastGC
+
+	// Let the GC implementation fill in the heap statistics.
+	heapStats(m)
+	m.Alloc = m.HeapAlloc
 }
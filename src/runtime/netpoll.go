@@ -0,0 +1,214 @@
+//go:build linux && !baremetal && !nintendoswitch
+// +build linux,!baremetal,!nintendoswitch
+
+package runtime
+
+// This file implements the netpoller. Goroutines that wait for a file
+// descriptor to become ready (for example, a socket that has no data yet) are
+// parked in a wait queue. The scheduler polls the file descriptors of the
+// parked goroutines when there is nothing else to run, and once in a while
+// when other goroutines are running, and makes the goroutines runnable again
+// when their file descriptor is ready.
+//
+// The actual polling is done using epoll on Linux and poll_oneoff on WASI.
+
+import (
+	"internal/task"
+)
+
+// Return values of netpollWait, the same as in internal/poll.
+const (
+	pollNoError        = 0 // the file descriptor is ready
+	pollErrClosing     = 1 // the file descriptor was closed while waiting
+	pollErrTimeout     = 2 // the deadline has passed
+	pollErrNotPollable = 3 // the file descriptor cannot be polled
+)
+
+// Number of scheduler iterations between checks for ready file descriptors
+// while other goroutines are running. This is the same as in the Go scheduler.
+const netpollCheckInterval = 61
+
+// netpollWaiter is a goroutine waiting for a file descriptor.
+type netpollWaiter struct {
+	next     *netpollWaiter
+	task     *task.Task // nil without scheduler
+	pd       *pollDesc  // nil if not waiting through internal/poll
+	fd       int32
+	mode     int32 // 'r' or 'w'
+	deadline int64 // in nanotime, or 0 for no deadline
+	result   int
+	done     bool
+}
+
+var (
+	netpollQueue *netpollWaiter // goroutines waiting for a file descriptor
+	netpollTicks uint32         // scheduler iterations since the last poll
+)
+
+// netpollPending returns whether there are goroutines waiting for a file
+// descriptor.
+func netpollPending() bool {
+	return netpollQueue != nil
+}
+
+// netpollOthers returns whether there are other goroutines that may be able to
+// run while the current goroutine waits. If not, blocking the whole program in
+// a system call is fine.
+func netpollOthers() bool {
+	return hasScheduler && (!runqueue.Empty() || sleepQueue != nil || netpollQueue != nil)
+}
+
+// netpollWait parks the current goroutine until fd is ready for reading (mode
+// 'r') or writing (mode 'w'), or until the deadline (in nanotime, or 0 for no
+// deadline) has passed. It returns one of the poll* result values.
+func netpollWait(fd, mode int, deadline int64, pd *pollDesc) int {
+	if deadline != 0 && deadline <= nanotime() {
+		return pollErrTimeout
+	}
+	w := &netpollWaiter{
+		pd:       pd,
+		fd:       int32(fd),
+		mode:     int32(mode),
+		deadline: deadline,
+	}
+	if hasScheduler {
+		w.task = task.Current()
+	}
+	if !netpollAdd(w) {
+		return pollErrNotPollable
+	}
+	if hasScheduler {
+		task.SetWaitReason(task.WaitIO, nil)
+		task.Pause()
+	} else {
+		// Without scheduler, there is nothing else to do but wait.
+		for !w.done {
+			netpoll(-1)
+		}
+	}
+	return w.result
+}
+
+// netpollAdd adds the waiter to the wait queue. It returns false if the file
+// descriptor cannot be polled.
+func netpollAdd(w *netpollWaiter) bool {
+	old := netpollEvents(w.fd)
+	w.next = netpollQueue
+	netpollQueue = w
+	if !netpollUpdate(w.fd, old) {
+		netpollQueue = w.next
+		w.next = nil
+		return false
+	}
+	return true
+}
+
+// netpollWake removes the waiter from the wait queue and makes its goroutine
+// runnable again.
+func netpollWake(w *netpollWaiter, result int) {
+	old := netpollEvents(w.fd)
+	for p := &netpollQueue; *p != nil; p = &(*p).next {
+		if *p == w {
+			*p = w.next
+			break
+		}
+	}
+	w.next = nil
+	netpollUpdate(w.fd, old)
+	w.result = result
+	w.done = true
+	if w.task != nil {
+		runqueue.Push(w.task)
+	}
+}
+
+// netpollUnblock wakes up all goroutines waiting for fd, because it is about
+// to be closed.
+func netpollUnblock(fd int) {
+	for w := netpollQueue; w != nil; {
+		next := w.next
+		if w.fd == int32(fd) {
+			netpollWake(w, pollErrClosing)
+		}
+		w = next
+	}
+}
+
+// netpollCheck is called by the scheduler before running a goroutine. Once in
+// a while, it checks for ready file descriptors without blocking so that
+// waiting goroutines don't starve while other goroutines are running.
+func netpollCheck() {
+	if netpollQueue == nil {
+		return
+	}
+	netpollTicks++
+	if netpollTicks >= netpollCheckInterval {
+		netpoll(0)
+	}
+}
+
+// netpoll wakes up the goroutines whose file descriptor is ready or whose
+// deadline has passed. It blocks for at most timeout nanoseconds (or forever
+// if the timeout is negative) until a file descriptor is ready.
+func netpoll(timeout int64) {
+	netpollTicks = 0
+	if timeout != 0 {
+		// Don't block past the earliest deadline.
+		now := nanotime()
+		for w := netpollQueue; w != nil; w = w.next {
+			if w.deadline != 0 {
+				left := w.deadline - now
+				if left < 0 {
+					left = 0
+				}
+				if timeout < 0 || left < timeout {
+					timeout = left
+				}
+			}
+		}
+	}
+	netpollPoll(timeout)
+
+	// Wake up goroutines whose deadline has passed.
+	now := nanotime()
+	for w := netpollQueue; w != nil; {
+		next := w.next
+		if w.deadline != 0 && w.deadline <= now {
+			netpollWake(w, pollErrTimeout)
+		}
+		w = next
+	}
+}
+
+// Wait until fd is ready for reading (mode 'r') or writing (mode 'w'), or
+// until the timeout (in nanoseconds, or negative for no timeout) expires. This
+// is called by the net package for non-blocking sockets.
+//go:linkname net_runtime_pollWait net.runtime_pollWait
+func net_runtime_pollWait(fd, mode int, timeout int64) int {
+	var deadline int64
+	if timeout == 0 {
+		return pollErrTimeout
+	} else if timeout > 0 {
+		deadline = nanotime() + timeout
+	}
+	return netpollWait(fd, mode, deadline, nil)
+}
+
+// Wake up goroutines waiting for fd, which is about to be closed.
+//go:linkname net_runtime_pollUnblock net.runtime_pollUnblock
+func net_runtime_pollUnblock(fd int) {
+	netpollUnblock(fd)
+}
+
+// Wait until fd is ready for reading (mode 'r') or writing (mode 'w'). This is
+// called by the os package before a blocking read or write, so that other
+// goroutines can run while waiting. File descriptors that cannot be polled
+// (such as regular files) are always considered ready.
+//go:linkname os_runtime_pollWait os.runtime_pollWait
+func os_runtime_pollWait(fd, mode int) {
+	if !netpollOthers() || netpollReady(fd, mode) {
+		// Nothing else can run, or the read or write won't block.
+		return
+	}
+	netpollWait(fd, mode, 0, nil)
+}
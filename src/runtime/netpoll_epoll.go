@@ -0,0 +1,11 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi && !386 && !amd64
+// +build linux,!baremetal,!nintendoswitch,!wasi,!386,!amd64
+
+package runtime
+
+// struct epoll_event from sys/epoll.h
+type epollevent struct {
+	events uint32
+	_      uint32
+	data   [8]byte
+}
@@ -0,0 +1,11 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi && (386 || amd64)
+// +build linux,!baremetal,!nintendoswitch,!wasi
+// +build 386 amd64
+
+package runtime
+
+// struct epoll_event from sys/epoll.h, which is packed on x86.
+type epollevent struct {
+	events uint32
+	data   [8]byte // unaligned uint64
+}
@@ -0,0 +1,176 @@
+//go:build linux && !baremetal && !nintendoswitch && !wasi
+// +build linux,!baremetal,!nintendoswitch,!wasi
+
+package runtime
+
+import (
+	"unsafe"
+)
+
+const (
+	_EPOLLIN       = 0x1
+	_EPOLLOUT      = 0x4
+	_EPOLLERR      = 0x8
+	_EPOLLHUP      = 0x10
+	_EPOLLRDHUP    = 0x2000
+	_EPOLL_CTL_ADD = 1
+	_EPOLL_CTL_DEL = 2
+	_EPOLL_CTL_MOD = 3
+	_EPOLL_CLOEXEC = 0x80000
+
+	_POLLIN  = 0x1
+	_POLLOUT = 0x4
+)
+
+// struct pollfd from poll.h
+type pollfd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+// int epoll_create1(int flags);
+//export epoll_create1
+func epoll_create1(flags int32) int32
+
+// int epoll_ctl(int epfd, int op, int fd, struct epoll_event *event);
+//export epoll_ctl
+func epoll_ctl(epfd, op, fd int32, event *epollevent) int32
+
+// int epoll_wait(int epfd, struct epoll_event *events, int maxevents, int timeout);
+//export epoll_wait
+func epoll_wait(epfd int32, events *epollevent, maxevents, timeout int32) int32
+
+// int poll(struct pollfd *fds, nfds_t nfds, int timeout);
+//export poll
+func libc_poll(fds *pollfd, nfds uint, timeout int32) int32
+
+// int *__errno_location(void);
+//export __errno_location
+func libc_errno_location() *int32
+
+// The epoll instance, created on first use.
+var netpollEpfd int32 = -1
+
+// netpollInit creates the epoll instance if needed. It returns false if that
+// failed.
+func netpollInit() bool {
+	if netpollEpfd < 0 {
+		netpollEpfd = epoll_create1(_EPOLL_CLOEXEC)
+	}
+	return netpollEpfd >= 0
+}
+
+// netpollEvents returns the epoll events that the waiting goroutines are
+// interested in for the given file descriptor. An fd is registered in the
+// epoll instance exactly when this is non-zero.
+func netpollEvents(fd int32) uint32 {
+	var events uint32
+	for w := netpollQueue; w != nil; w = w.next {
+		if w.fd == fd {
+			if w.mode == 'r' {
+				events |= _EPOLLIN | _EPOLLRDHUP
+			} else {
+				events |= _EPOLLOUT
+			}
+		}
+	}
+	return events
+}
+
+// netpollUpdate updates the registration of fd in the epoll instance after the
+// wait queue has changed. The old events are the events returned by
+// netpollEvents before the change. It returns false if fd cannot be polled.
+func netpollUpdate(fd int32, old uint32) bool {
+	events := netpollEvents(fd)
+	if events == old {
+		return true
+	}
+	if !netpollInit() {
+		return false
+	}
+	op := int32(_EPOLL_CTL_MOD)
+	if old == 0 {
+		op = _EPOLL_CTL_ADD
+	} else if events == 0 {
+		op = _EPOLL_CTL_DEL
+	}
+	ev := epollevent{events: events}
+	*(*int32)(unsafe.Pointer(&ev.data)) = fd
+	return epoll_ctl(netpollEpfd, op, fd, &ev) == 0
+}
+
+// netpollPoll waits for at most timeout nanoseconds (or forever if negative)
+// until one of the file descriptors in the wait queue is ready, and wakes up
+// the goroutines waiting for the ready file descriptors.
+func netpollPoll(timeout int64) {
+	var events [16]epollevent
+	n := epoll_wait(netpollEpfd, &events[0], int32(len(events)), netpollTimeoutMillis(timeout))
+	for i := int32(0); i < n; i++ {
+		ev := &events[i]
+		fd := *(*int32)(unsafe.Pointer(&ev.data))
+		for w := netpollQueue; w != nil; {
+			next := w.next
+			if w.fd == fd {
+				ready := ev.events&(_EPOLLERR|_EPOLLHUP) != 0
+				if w.mode == 'r' {
+					ready = ready || ev.events&(_EPOLLIN|_EPOLLRDHUP) != 0
+				} else {
+					ready = ready || ev.events&_EPOLLOUT != 0
+				}
+				if ready {
+					netpollWake(w, pollNoError)
+				}
+			}
+			w = next
+		}
+	}
+}
+
+// netpollReady returns whether a read (mode 'r') or write (mode 'w') on fd
+// won't block, without waiting. An error condition on the file descriptor
+// counts as ready as well: the error is returned by the read or write.
+func netpollReady(fd, mode int) bool {
+	pfd := pollfd{fd: int32(fd), events: _POLLIN}
+	if mode == 'w' {
+		pfd.events = _POLLOUT
+	}
+	return libc_poll(&pfd, 1, 0) > 0
+}
+
+// netpollCanPoll returns zero if fd can be used with epoll, or an errno value
+// otherwise (for example, EPERM for regular files).
+func netpollCanPoll(fd int) int {
+	if !netpollInit() {
+		return int(*libc_errno_location())
+	}
+	if netpollEvents(int32(fd)) != 0 {
+		// Already registered.
+		return 0
+	}
+	ev := epollevent{events: _EPOLLIN}
+	if epoll_ctl(netpollEpfd, _EPOLL_CTL_ADD, int32(fd), &ev) != 0 {
+		return int(*libc_errno_location())
+	}
+	epoll_ctl(netpollEpfd, _EPOLL_CTL_DEL, int32(fd), &ev)
+	return 0
+}
+
+// netpollTimeoutMillis converts a timeout in nanoseconds (or negative for no
+// timeout) to milliseconds for epoll_wait.
+func netpollTimeoutMillis(timeout int64) int32 {
+	if timeout < 0 {
+		return -1
+	}
+	// Round up, to avoid spinning for timeouts below a millisecond.
+	timeout = (timeout + 999999) / 1000000
+	if timeout > 1<<30 {
+		timeout = 1 << 30
+	}
+	return int32(timeout)
+}
+
+// netpollIsDescriptor returns whether fd is used by the netpoller itself.
+func netpollIsDescriptor(fd int) bool {
+	return int32(fd) == netpollEpfd
+}
@@ -0,0 +1,16 @@
+//go:build !linux || baremetal || nintendoswitch
+// +build !linux baremetal nintendoswitch
+
+package runtime
+
+// There is no netpoller on this system (see netpoll.go).
+
+func netpollPending() bool {
+	return false
+}
+
+func netpoll(timeout int64) {
+}
+
+func netpollCheck() {
+}
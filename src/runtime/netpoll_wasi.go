@@ -0,0 +1,124 @@
+//go:build wasi
+// +build wasi
+
+package runtime
+
+import (
+	"unsafe"
+)
+
+// Subscriptions and events for poll_oneoff, reused between calls.
+var (
+	netpollSubscriptions []__wasi_subscription_t
+	netpollEventBuf      []__wasi_event_t
+)
+
+// netpollEvents is only needed for epoll: poll_oneoff receives the list of
+// file descriptors on every call.
+func netpollEvents(fd int32) uint32 {
+	return 0
+}
+
+// netpollUpdate does nothing, see netpollEvents.
+func netpollUpdate(fd int32, old uint32) bool {
+	return true
+}
+
+// netpollPoll waits for at most timeout nanoseconds (or forever if negative)
+// until one of the file descriptors in the wait queue is ready, and wakes up
+// the goroutines waiting for the ready file descriptors.
+func netpollPoll(timeout int64) {
+	// Subscribe to all file descriptors in the wait queue. The user data of
+	// each subscription is a pointer to the waiter, which is kept alive by
+	// the wait queue.
+	subscriptions := netpollSubscriptions[:0]
+	for w := netpollQueue; w != nil; w = w.next {
+		subscriptions = append(subscriptions, netpollSubscription(int(w.fd), int(w.mode), uint64(uintptr(unsafe.Pointer(w)))))
+	}
+	if timeout >= 0 {
+		subscriptions = append(subscriptions, __wasi_subscription_t{
+			u: __wasi_subscription_u_t{
+				tag: __wasi_eventtype_t_clock,
+				u: __wasi_subscription_clock_t{
+					timeout:   uint64(timeout),
+					precision: timePrecisionNanoseconds,
+				},
+			},
+		})
+	}
+	netpollSubscriptions = subscriptions
+	if len(netpollEventBuf) < len(subscriptions) {
+		netpollEventBuf = make([]__wasi_event_t, len(subscriptions))
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	var nevents uint32
+	if poll_oneoff(&subscriptions[0], &netpollEventBuf[0], uint32(len(subscriptions)), &nevents) != 0 {
+		return
+	}
+	for _, event := range netpollEventBuf[:nevents] {
+		if event.userData == 0 {
+			continue // clock
+		}
+		// Also wake up the goroutine in case of an error: the error is
+		// returned by the next read or write.
+		w := (*netpollWaiter)(unsafe.Pointer(uintptr(event.userData)))
+		if !w.done {
+			netpollWake(w, pollNoError)
+		}
+	}
+}
+
+// netpollReady returns whether a read (mode 'r') or write (mode 'w') on fd
+// won't block, without waiting.
+func netpollReady(fd, mode int) bool {
+	subscriptions := [2]__wasi_subscription_t{
+		netpollSubscription(fd, mode, 1),
+		{
+			u: __wasi_subscription_u_t{
+				tag: __wasi_eventtype_t_clock,
+				u: __wasi_subscription_clock_t{
+					precision: timePrecisionNanoseconds,
+				},
+			},
+		},
+	}
+	var events [2]__wasi_event_t
+	var nevents uint32
+	if poll_oneoff(&subscriptions[0], &events[0], 2, &nevents) != 0 {
+		// Let the caller try the read or write, which will report the error.
+		return true
+	}
+	for _, event := range events[:nevents] {
+		if event.userData == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// netpollSubscription returns a subscription for reading (mode 'r') or writing
+// (mode 'w') on the given file descriptor.
+func netpollSubscription(fd, mode int, userData uint64) __wasi_subscription_t {
+	s := __wasi_subscription_t{userData: userData}
+	s.u.tag = __wasi_eventtype_t_fd_read
+	if mode == 'w' {
+		s.u.tag = __wasi_eventtype_t_fd_write
+	}
+	s.u.u.id = uint32(fd)
+	return s
+}
+
+// netpollIsDescriptor returns whether fd is used by the netpoller itself,
+// which is never the case in WASI.
+func netpollIsDescriptor(fd int) bool {
+	return false
+}
+
+// netpollCanPoll returns zero: whether a file descriptor can be polled is only
+// known when calling poll_oneoff.
+func netpollCanPoll(fd int) int {
+	return 0
+}
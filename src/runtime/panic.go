@@ -1,5 +1,10 @@
 package runtime
 
+import (
+	"internal/task"
+	"unsafe"
+)
+
 // trap is a compiler hint that this function cannot be executed. It is
 // translated into either a trap instruction or a call to abort().
 //export llvm.trap
@@ -10,6 +15,7 @@ func _panic(message interface{}) {
 	printstring("panic: ")
 	printitf(message)
 	printnl()
+	printBacktrace()
 	abort()
 }
 
@@ -17,9 +23,85 @@ func _panic(message interface{}) {
 func runtimePanic(msg string) {
 	printstring("panic: runtime error: ")
 	println(msg)
+	printBacktrace()
 	abort()
 }
 
+// stackOverflow is called by the scheduler (or the HardFault handler) when a
+// goroutine overflowed its stack. It prints the function the goroutine was
+// started with and its stack size, which is the size printed by -print-stacks
+// if it could be determined at compile time and the default stack size
+// otherwise.
+func stackOverflow(t *task.Task, fn uintptr, stackSize uintptr) {
+	printstring("panic: runtime error: goroutine stack overflow\ngoroutine ")
+	printhex(uintptr(unsafe.Pointer(t)))
+	printstring(" started at ")
+	if loc, ok := lookupPC(fn); ok {
+		printstring(loc.name)
+	} else {
+		printhex(fn)
+	}
+	printstring(" with a stack of ")
+	printint64(int64(stackSize))
+	printstring(" bytes\n")
+	abort()
+}
+
+// printBacktrace prints the call stack of the current goroutine, starting at
+// the function that called printBacktrace. Functions are printed by name and
+// source location if they are known (see lookupPC), and by program counter
+// otherwise. It does not allocate, as it may be called when the heap is in a
+// bad state.
+//
+// Without symbol information (for example on Cortex-M, unless built with
+// -tags=noframepointers), only a compact list of return addresses is printed on
+// a single line:
+//
+//     backtrace: 0x2b9 0x1f3 0x151
+//
+// These addresses can be decoded on the host with tinygo addr2line.
+//go:noinline
+func printBacktrace() {
+	var pcs [32]uintptr
+	n := callers(1, pcs[:])
+	printCallers(pcs[:n])
+}
+
+// printCallers prints a list of return addresses as obtained by callers. The
+// function names and source locations are only printed when they are known.
+func printCallers(pcs []uintptr) {
+	if len(pcs) == 0 {
+		return
+	}
+	if _, ok := lookupPC(pcs[0] - 1); !ok {
+		printstring("backtrace:")
+		for _, pc := range pcs {
+			printstring(" ")
+			printhex(pc)
+		}
+		printnl()
+		return
+	}
+	printnl()
+	for _, pc := range pcs {
+		// The return address points just after the call instruction.
+		pc--
+		if loc, ok := lookupPC(pc); ok {
+			printstring(loc.name)
+			printstring("()\n\t")
+			printstring(loc.file)
+			printstring(":")
+			printint64(int64(loc.line))
+			printstring(" +")
+			printhex(pc - loc.entry)
+		} else {
+			printstring("?()\n\t")
+			printhex(pc)
+		}
+		printnl()
+	}
+}
+
 // Try to recover a panicking goroutine.
 func _recover() interface{} {
 	// Deferred functions are currently not executed during panic, so there is
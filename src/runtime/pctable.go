@@ -0,0 +1,135 @@
+//go:build !tinygo.wasm || (!gc.conservative && !gc.precise)
+// +build !tinygo.wasm !gc.conservative,!gc.precise
+
+package runtime
+
+// PC table lookup. The builder stores a table in the binary that maps program
+// counters to function names and source locations, see builder/pctable.go for
+// details. This table is only included on some systems (see
+// compileopts.Config.PCTable). On other systems the table is empty and program
+// counters cannot be symbolized.
+
+import "unsafe"
+
+// pcTableHeader is the start of the PC table. It is directly followed by a
+// sorted list of numFuncs function addresses and a list of numFuncs offsets
+// (from the start of the table) to the function records.
+//
+// Each function record consists of the following varints: the function size,
+// the name (as string table offset), the file (as string table offset), the
+// start line and the number of line table entries. Each line table entry is a
+// varint with the distance in bytes from the previous entry, and a varint with
+// the zigzag encoded line delta shifted left by one. The lowest bit indicates
+// that the entry is followed by a varint with the new file offset.
+//
+// Each string in the string table consists of a varint length and the string
+// bytes.
+type pcTableHeader struct {
+	address       uintptr // address of the table when linking
+	numFuncs      uintptr // number of functions in the table
+	stringsOffset uintptr // offset of the string table from the table start
+}
+
+//go:extern runtime.pcTable
+var pcTable pcTableHeader
+
+// lookupPC returns the function name and source location of the given program
+// counter, if it is known.
+func lookupPC(pc uintptr) (fn funcLocation, ok bool) {
+	numFuncs := pcTable.numFuncs
+	if numFuncs == 0 {
+		return // no PC table available
+	}
+	const wordSize = unsafe.Sizeof(uintptr(0))
+	table := uintptr(unsafe.Pointer(&pcTable))
+	addresses := table + unsafe.Sizeof(pcTable)
+	offsets := addresses + numFuncs*wordSize
+	strings := table + pcTable.stringsOffset
+
+	// The program may have been loaded at a different address than it was
+	// linked at.
+	bias := table - pcTable.address
+	pc -= bias
+
+	// Find the last function that starts at or before pc.
+	low, high := uintptr(0), numFuncs
+	for low < high {
+		mid := low + (high-low)/2
+		if *(*uintptr)(unsafe.Pointer(addresses + mid*wordSize)) <= pc {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	if low == 0 {
+		return // before the first function
+	}
+	index := low - 1
+	entry := *(*uintptr)(unsafe.Pointer(addresses + index*wordSize))
+	p := table + *(*uintptr)(unsafe.Pointer(offsets + index*wordSize))
+
+	// Read the function record.
+	var size, nameOffset, fileOffset, line, count uintptr
+	size, p = readUvarint(p)
+	if pc >= entry+size {
+		return // in the padding between two functions
+	}
+	nameOffset, p = readUvarint(p)
+	fileOffset, p = readUvarint(p)
+	line, p = readUvarint(p)
+	count, p = readUvarint(p)
+
+	// Walk the line table until pc has been reached.
+	offset := uintptr(0)
+	for i := uintptr(0); i < count; i++ {
+		var delta, change uintptr
+		delta, p = readUvarint(p)
+		change, p = readUvarint(p)
+		offset += delta
+		if entry+offset > pc {
+			break
+		}
+		zigzag := change >> 1
+		if zigzag&1 != 0 {
+			line -= (zigzag >> 1) + 1
+		} else {
+			line += zigzag >> 1
+		}
+		if change&1 != 0 {
+			fileOffset, p = readUvarint(p)
+		}
+	}
+
+	return funcLocation{
+		name:  readPCTableString(strings + nameOffset),
+		file:  readPCTableString(strings + fileOffset),
+		line:  int(line),
+		entry: entry + bias,
+	}, true
+}
+
+// readUvarint reads an unsigned varint at the given address and returns the
+// value and the address just after it.
+func readUvarint(p uintptr) (value, next uintptr) {
+	shift := uintptr(0)
+	for {
+		b := *(*byte)(unsafe.Pointer(p))
+		p++
+		value |= uintptr(b&0x7f) << shift
+		if b < 0x80 {
+			return value, p
+		}
+		shift += 7
+	}
+}
+
+// readPCTableString returns the string stored in the string table at the
+// given address. The string is not copied.
+func readPCTableString(p uintptr) string {
+	length, p := readUvarint(p)
+	s := _string{
+		ptr:    (*byte)(unsafe.Pointer(p)),
+		length: length,
+	}
+	return *(*string)(unsafe.Pointer(&s))
+}
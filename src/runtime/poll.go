@@ -1,23 +1,147 @@
+//go:build linux && !baremetal && !nintendoswitch
+// +build linux,!baremetal,!nintendoswitch
+
 package runtime
 
-// This file implements stub functions for internal/poll.
+// This file implements the runtime side of internal/poll, on top of the
+// netpoller (see netpoll.go).
+
+import (
+	"unsafe"
+)
+
+// pollDesc is a file descriptor registered by internal/poll.
+type pollDesc struct {
+	next    *pollDesc
+	fd      int
+	closing bool
+	rd      int64 // read deadline in nanotime, 0 for none or -1 if expired
+	wd      int64 // write deadline in nanotime, 0 for none or -1 if expired
+}
+
+// All registered file descriptors. internal/poll only stores a uintptr, so
+// this list keeps them alive.
+var pollDescs *pollDesc
+
+// check returns the error for an operation in the given mode ('r' or 'w'), if
+// any, without waiting.
+func (pd *pollDesc) check(mode int) int {
+	if pd.closing {
+		return pollErrClosing
+	}
+	if (mode == 'r' && pd.rd < 0) || (mode == 'w' && pd.wd < 0) {
+		return pollErrTimeout
+	}
+	return pollNoError
+}
 
 //go:linkname poll_runtime_pollServerInit internal/poll.runtime_pollServerInit
 func poll_runtime_pollServerInit() {
-	panic("todo: runtime_pollServerInit")
+	// The netpoller is initialized on first use.
+}
+
+//go:linkname poll_runtime_isPollServerDescriptor internal/poll.runtime_isPollServerDescriptor
+func poll_runtime_isPollServerDescriptor(fd uintptr) bool {
+	return netpollIsDescriptor(int(fd))
 }
 
 //go:linkname poll_runtime_pollOpen internal/poll.runtime_pollOpen
 func poll_runtime_pollOpen(fd uintptr) (uintptr, int) {
-	panic("todo: runtime_pollOpen")
+	if errno := netpollCanPoll(int(fd)); errno != 0 {
+		return 0, errno
+	}
+	pd := &pollDesc{
+		next: pollDescs,
+		fd:   int(fd),
+	}
+	pollDescs = pd
+	return uintptr(unsafe.Pointer(pd)), 0
 }
 
 //go:linkname poll_runtime_pollClose internal/poll.runtime_pollClose
 func poll_runtime_pollClose(ctx uintptr) {
-	panic("todo: runtime_pollClose")
+	pd := (*pollDesc)(unsafe.Pointer(ctx))
+	for p := &pollDescs; *p != nil; p = &(*p).next {
+		if *p == pd {
+			*p = pd.next
+			break
+		}
+	}
+}
+
+//go:linkname poll_runtime_pollReset internal/poll.runtime_pollReset
+func poll_runtime_pollReset(ctx uintptr, mode int) int {
+	pd := (*pollDesc)(unsafe.Pointer(ctx))
+	return pd.check(mode)
+}
+
+//go:linkname poll_runtime_pollWait internal/poll.runtime_pollWait
+func poll_runtime_pollWait(ctx uintptr, mode int) int {
+	pd := (*pollDesc)(unsafe.Pointer(ctx))
+	if result := pd.check(mode); result != pollNoError {
+		return result
+	}
+	deadline := pd.rd
+	if mode == 'w' {
+		deadline = pd.wd
+	}
+	result := netpollWait(pd.fd, mode, deadline, pd)
+	if result == pollErrTimeout {
+		// Mark the deadline as expired, unless it was changed while waiting.
+		if mode == 'r' && pd.rd == deadline {
+			pd.rd = -1
+		} else if mode == 'w' && pd.wd == deadline {
+			pd.wd = -1
+		}
+	}
+	return result
+}
+
+// Set the read (mode 'r'), write (mode 'w') or both (mode 'r'+'w') deadlines.
+// The deadline d is relative to now: zero means no deadline and a negative
+// value means the deadline has already passed.
+//go:linkname poll_runtime_pollSetDeadline internal/poll.runtime_pollSetDeadline
+func poll_runtime_pollSetDeadline(ctx uintptr, d int64, mode int) {
+	pd := (*pollDesc)(unsafe.Pointer(ctx))
+	if d > 0 {
+		d += nanotime()
+	} else if d < 0 {
+		d = -1
+	}
+	if mode == 'r' || mode == 'r'+'w' {
+		pd.rd = d
+	}
+	if mode == 'w' || mode == 'r'+'w' {
+		pd.wd = d
+	}
+
+	// Apply the new deadlines to goroutines that are already waiting.
+	for w := netpollQueue; w != nil; {
+		next := w.next
+		if w.pd == pd {
+			deadline := pd.rd
+			if w.mode == 'w' {
+				deadline = pd.wd
+			}
+			if deadline < 0 {
+				netpollWake(w, pollErrTimeout)
+			} else {
+				w.deadline = deadline
+			}
+		}
+		w = next
+	}
 }
 
 //go:linkname poll_runtime_pollUnblock internal/poll.runtime_pollUnblock
 func poll_runtime_pollUnblock(ctx uintptr) {
-	panic("todo: runtime_pollUnblock")
+	pd := (*pollDesc)(unsafe.Pointer(ctx))
+	pd.closing = true
+	for w := netpollQueue; w != nil; {
+		next := w.next
+		if w.pd == pd {
+			netpollWake(w, pollErrClosing)
+		}
+		w = next
+	}
 }
@@ -0,0 +1,26 @@
+//go:build !linux || baremetal || nintendoswitch
+// +build !linux baremetal nintendoswitch
+
+package runtime
+
+// This file implements stub functions for internal/poll.
+
+//go:linkname poll_runtime_pollServerInit internal/poll.runtime_pollServerInit
+func poll_runtime_pollServerInit() {
+	panic("todo: runtime_pollServerInit")
+}
+
+//go:linkname poll_runtime_pollOpen internal/poll.runtime_pollOpen
+func poll_runtime_pollOpen(fd uintptr) (uintptr, int) {
+	panic("todo: runtime_pollOpen")
+}
+
+//go:linkname poll_runtime_pollClose internal/poll.runtime_pollClose
+func poll_runtime_pollClose(ctx uintptr) {
+	panic("todo: runtime_pollClose")
+}
+
+//go:linkname poll_runtime_pollUnblock internal/poll.runtime_pollUnblock
+func poll_runtime_pollUnblock(ctx uintptr) {
+	panic("todo: runtime_pollUnblock")
+}
@@ -1,29 +1,209 @@
+// Package pprof writes runtime profiling data in the format expected by the
+// pprof visualization tool.
+//
+// TinyGo supports a CPU profile (on Linux) and a heap profile (on hosted
+// systems with the conservative or precise garbage collector). The other
+// predefined profiles can be looked up, but writing them returns
+// ErrUnimplemented.
 package pprof
 
-// TinyGo does not implement pprof. However, a dummy shell is needed for the
-// testing package (and testing/internal/pprof).
-
 import (
 	"errors"
 	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
 )
 
 var ErrUnimplemented = errors.New("runtime/pprof: unimplemented")
 
+// Implemented in the runtime.
+func startCPUProfile(hz int) bool
+func stopCPUProfile()
+func readCPUProfile(fn func(count int64, stack []uintptr)) (lost int64)
+func readHeapProfileLost() (lost, untracked int64)
+
+// A Profile is a collection of stack traces showing the call sequences that
+// led to instances of a particular event. Only the "heap" and "allocs"
+// profiles are supported. The other predefined profiles exist, but they are
+// always empty and cannot be written.
 type Profile struct {
+	name        string
+	unsupported bool
 }
 
-func StartCPUProfile(w io.Writer) error {
+var (
+	allocsProfile       = &Profile{name: "allocs"}
+	blockProfile        = &Profile{name: "block", unsupported: true}
+	goroutineProfile    = &Profile{name: "goroutine", unsupported: true}
+	heapProfile         = &Profile{name: "heap"}
+	mutexProfile        = &Profile{name: "mutex", unsupported: true}
+	threadcreateProfile = &Profile{name: "threadcreate", unsupported: true}
+)
+
+// Lookup returns the profile with the given name, or nil if no such profile
+// exists.
+func Lookup(name string) *Profile {
+	for _, p := range Profiles() {
+		if p.name == name {
+			return p
+		}
+	}
 	return nil
 }
 
-func StopCPUProfile() {
+// Profiles returns a slice of all the known profiles, sorted by name.
+func Profiles() []*Profile {
+	return []*Profile{allocsProfile, blockProfile, goroutineProfile, heapProfile, mutexProfile, threadcreateProfile}
 }
 
-func Lookup(name string) *Profile {
-	return nil
+// Name returns this profile's name, which can be passed to Lookup to reobtain
+// the profile.
+func (p *Profile) Name() string {
+	return p.name
+}
+
+// Count returns the number of execution stacks currently in the profile.
+func (p *Profile) Count() int {
+	if p.unsupported {
+		return 0
+	}
+	n, _ := runtime.MemProfile(nil, true)
+	return n
 }
 
+// WriteTo writes a pprof-formatted snapshot of the profile to w. Only the
+// protocol buffer format (debug=0) of the heap and allocs profiles is
+// supported.
 func (p *Profile) WriteTo(w io.Writer, debug int) error {
-	return ErrUnimplemented
+	if p.unsupported || debug != 0 {
+		return ErrUnimplemented
+	}
+	return writeHeap(w, p.name == "allocs")
+}
+
+// WriteHeapProfile is shorthand for Lookup("heap").WriteTo(w, 0).
+func WriteHeapProfile(w io.Writer) error {
+	return writeHeap(w, false)
+}
+
+// writeHeap writes the heap profile. The allocs profile is the same, except
+// that it shows allocated space by default instead of space in use.
+func writeHeap(w io.Writer, allocs bool) error {
+	var records []runtime.MemProfileRecord
+	n, _ := runtime.MemProfile(nil, true)
+	for {
+		// Allocate room for some extra records, in case the profile grows in
+		// the meantime.
+		records = make([]runtime.MemProfileRecord, n+50)
+		var ok bool
+		n, ok = runtime.MemProfile(records, true)
+		if ok {
+			records = records[:n]
+			break
+		}
+	}
+
+	rate := int64(runtime.MemProfileRate)
+	b := newProfileBuilder()
+	b.sampleType("alloc_objects", "count")
+	b.sampleType("alloc_space", "bytes")
+	b.sampleType("inuse_objects", "count")
+	b.sampleType("inuse_space", "bytes")
+	b.period("space", "bytes", rate)
+	if allocs {
+		b.defaultSampleType("alloc_space")
+	}
+	// The heap profile has a fixed size, so report when it overflowed
+	// instead of silently returning incomplete data.
+	lost, untracked := readHeapProfileLost()
+	if lost != 0 {
+		b.comment(strconv.FormatInt(lost, 10) + " samples were lost because there were too many different allocation stacks")
+	}
+	if untracked != 0 {
+		b.comment(strconv.FormatInt(untracked, 10) + " sampled objects were not tracked, their frees are not included")
+	}
+	for i := range records {
+		r := &records[i]
+		allocObjects, allocBytes := scaleHeapSample(r.AllocObjects, r.AllocBytes, rate)
+		inuseObjects, inuseBytes := scaleHeapSample(r.InUseObjects(), r.InUseBytes(), rate)
+		b.sample(r.Stack(), allocObjects, allocBytes, inuseObjects, inuseBytes)
+	}
+	_, err := w.Write(b.finish())
+	return err
+}
+
+// scaleHeapSample adjusts the data from a heap sample to account for its
+// probability of appearing in the collected data. Allocations are sampled
+// once every rate bytes, so an allocation of size bytes has a probability of
+// 1-exp(-size/rate) of being sampled.
+func scaleHeapSample(count, size, rate int64) (int64, int64) {
+	if count == 0 || size == 0 {
+		return 0, 0
+	}
+	if rate <= 1 {
+		// If rate==1 all samples were collected so no adjustment is needed.
+		return count, size
+	}
+	avgSize := float64(size) / float64(count)
+	scale := 1 / (1 - math.Exp(-avgSize/float64(rate)))
+	return int64(float64(count) * scale), int64(float64(size) * scale)
+}
+
+// The sample rate of the CPU profiler, in Hz.
+const cpuProfileRate = 100
+
+var cpu struct {
+	sync.Mutex
+	profiling bool
+	w         io.Writer
+	start     time.Time
+}
+
+// StartCPUProfile enables CPU profiling for the current process. While
+// profiling, the profile will be buffered and written to w when
+// StopCPUProfile is called. StartCPUProfile returns an error if profiling is
+// already enabled or not supported on this system.
+func StartCPUProfile(w io.Writer) error {
+	cpu.Lock()
+	defer cpu.Unlock()
+	if cpu.profiling {
+		return errors.New("cpu profiling already in use")
+	}
+	if !startCPUProfile(cpuProfileRate) {
+		return ErrUnimplemented
+	}
+	cpu.profiling = true
+	cpu.w = w
+	cpu.start = time.Now()
+	return nil
+}
+
+// StopCPUProfile stops the current CPU profile, if any, and writes the
+// profile to the writer passed to StartCPUProfile.
+func StopCPUProfile() {
+	cpu.Lock()
+	defer cpu.Unlock()
+	if !cpu.profiling {
+		return
+	}
+	stopCPUProfile()
+	cpu.profiling = false
+
+	const period = int64(time.Second / cpuProfileRate)
+	b := newProfileBuilder()
+	b.sampleType("samples", "count")
+	b.sampleType("cpu", "nanoseconds")
+	b.period("cpu", "nanoseconds", period)
+	b.time(cpu.start, time.Since(cpu.start))
+	lost := readCPUProfile(func(count int64, stack []uintptr) {
+		b.sample(stack, count, count*period)
+	})
+	if lost != 0 {
+		b.sample(nil, lost, lost*period)
+	}
+	cpu.w.Write(b.finish())
+	cpu.w = nil
 }
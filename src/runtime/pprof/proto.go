@@ -0,0 +1,256 @@
+package pprof
+
+// This file writes profiles in the protocol buffer format described in
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+// The output is not compressed, which is accepted by go tool pprof as well.
+
+import (
+	"runtime"
+	"time"
+)
+
+// Field numbers from profile.proto.
+const (
+	tagProfile_SampleType        = 1
+	tagProfile_Sample            = 2
+	tagProfile_Mapping           = 3
+	tagProfile_Location          = 4
+	tagProfile_Function          = 5
+	tagProfile_StringTable       = 6
+	tagProfile_TimeNanos         = 9
+	tagProfile_DurationNanos     = 10
+	tagProfile_PeriodType        = 11
+	tagProfile_Period            = 12
+	tagProfile_Comment           = 13
+	tagProfile_DefaultSampleType = 14
+
+	tagValueType_Type = 1
+	tagValueType_Unit = 2
+
+	tagSample_Location = 1
+	tagSample_Value    = 2
+
+	tagMapping_ID              = 1
+	tagMapping_HasFunctions    = 7
+	tagMapping_HasFilenames    = 8
+	tagMapping_HasLineNumbers  = 9
+	tagMapping_HasInlineFrames = 10
+
+	tagLocation_ID        = 1
+	tagLocation_MappingID = 2
+	tagLocation_Address   = 3
+	tagLocation_Line      = 4
+
+	tagLine_FunctionID = 1
+	tagLine_Line       = 2
+
+	tagFunction_ID         = 1
+	tagFunction_Name       = 2
+	tagFunction_SystemName = 3
+	tagFunction_Filename   = 4
+)
+
+// protobuf is a minimal protocol buffer encoder.
+type protobuf struct {
+	data []byte
+}
+
+func (b *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protobuf) key(tag, wireType int) {
+	b.varint(uint64(tag)<<3 | uint64(wireType))
+}
+
+func (b *protobuf) uint64(tag int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(tag, 0)
+	b.varint(x)
+}
+
+func (b *protobuf) int64(tag int, x int64) {
+	b.uint64(tag, uint64(x))
+}
+
+func (b *protobuf) bool(tag int, x bool) {
+	if x {
+		b.uint64(tag, 1)
+	}
+}
+
+func (b *protobuf) bytes(tag int, data []byte) {
+	b.key(tag, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protobuf) string(tag int, s string) {
+	b.key(tag, 2)
+	b.varint(uint64(len(s)))
+	b.data = append(b.data, s...)
+}
+
+func (b *protobuf) message(tag int, m *protobuf) {
+	b.bytes(tag, m.data)
+}
+
+// uint64s writes a packed repeated field.
+func (b *protobuf) uint64s(tag int, x []uint64) {
+	var packed protobuf
+	for _, v := range x {
+		packed.varint(v)
+	}
+	b.bytes(tag, packed.data)
+}
+
+// profileBuilder builds a single profile. Locations and functions are
+// symbolized using the runtime, so go tool pprof doesn't need the binary.
+type profileBuilder struct {
+	pb         protobuf // the profile itself
+	funcs      protobuf // all function messages
+	locs       protobuf // all location messages
+	strings    []string
+	stringMap  map[string]int64
+	locations  map[uintptr]uint64
+	functions  map[string]uint64
+	symbolized bool // whether all locations have function information
+}
+
+func newProfileBuilder() *profileBuilder {
+	b := &profileBuilder{
+		stringMap:  make(map[string]int64),
+		locations:  make(map[uintptr]uint64),
+		functions:  make(map[string]uint64),
+		symbolized: true,
+	}
+	b.stringIndex("") // index 0 must be the empty string
+	return b
+}
+
+func (b *profileBuilder) stringIndex(s string) int64 {
+	if index, ok := b.stringMap[s]; ok {
+		return index
+	}
+	index := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.stringMap[s] = index
+	return index
+}
+
+func (b *profileBuilder) valueType(tag int, typ, unit string) {
+	var m protobuf
+	m.int64(tagValueType_Type, b.stringIndex(typ))
+	m.int64(tagValueType_Unit, b.stringIndex(unit))
+	b.pb.message(tag, &m)
+}
+
+func (b *profileBuilder) sampleType(typ, unit string) {
+	b.valueType(tagProfile_SampleType, typ, unit)
+}
+
+func (b *profileBuilder) defaultSampleType(typ string) {
+	b.pb.int64(tagProfile_DefaultSampleType, b.stringIndex(typ))
+}
+
+func (b *profileBuilder) period(typ, unit string, period int64) {
+	b.valueType(tagProfile_PeriodType, typ, unit)
+	b.pb.int64(tagProfile_Period, period)
+}
+
+// comment adds a free-form note to the profile, which is shown by pprof.
+func (b *profileBuilder) comment(s string) {
+	b.pb.int64(tagProfile_Comment, b.stringIndex(s))
+}
+
+func (b *profileBuilder) time(start time.Time, duration time.Duration) {
+	b.pb.int64(tagProfile_TimeNanos, start.UnixNano())
+	b.pb.int64(tagProfile_DurationNanos, int64(duration))
+}
+
+// sample adds a single sample. The stack contains return addresses, as
+// returned by runtime.Callers.
+func (b *profileBuilder) sample(stack []uintptr, values ...int64) {
+	ids := make([]uint64, len(stack))
+	for i, pc := range stack {
+		ids[i] = b.locationID(pc)
+	}
+	valuesPacked := make([]uint64, len(values))
+	for i, v := range values {
+		valuesPacked[i] = uint64(v)
+	}
+	var m protobuf
+	m.uint64s(tagSample_Location, ids)
+	m.uint64s(tagSample_Value, valuesPacked)
+	b.pb.message(tagProfile_Sample, &m)
+}
+
+// locationID returns the ID of the location for the given return address,
+// adding it to the profile if needed.
+func (b *profileBuilder) locationID(pc uintptr) uint64 {
+	if id, ok := b.locations[pc]; ok {
+		return id
+	}
+	id := uint64(len(b.locations) + 1)
+	b.locations[pc] = id
+
+	// Look up the call instruction, not the instruction after it.
+	var m protobuf
+	m.uint64(tagLocation_ID, id)
+	m.uint64(tagLocation_MappingID, 1)
+	m.uint64(tagLocation_Address, uint64(pc-1))
+	if f := runtime.FuncForPC(pc - 1); f != nil {
+		file, line := f.FileLine(pc - 1)
+		var l protobuf
+		l.uint64(tagLine_FunctionID, b.functionID(f.Name(), file))
+		l.int64(tagLine_Line, int64(line))
+		m.message(tagLocation_Line, &l)
+	} else {
+		b.symbolized = false
+	}
+	b.locs.message(tagProfile_Location, &m)
+	return id
+}
+
+// functionID returns the ID of the given function, adding it to the profile
+// if needed.
+func (b *profileBuilder) functionID(name, file string) uint64 {
+	if id, ok := b.functions[name]; ok {
+		return id
+	}
+	id := uint64(len(b.functions) + 1)
+	b.functions[name] = id
+	var m protobuf
+	m.uint64(tagFunction_ID, id)
+	m.int64(tagFunction_Name, b.stringIndex(name))
+	m.int64(tagFunction_SystemName, b.stringIndex(name))
+	m.int64(tagFunction_Filename, b.stringIndex(file))
+	b.funcs.message(tagProfile_Function, &m)
+	return id
+}
+
+// finish returns the encoded profile.
+func (b *profileBuilder) finish() []byte {
+	// A single mapping for the whole program. The locations are already
+	// symbolized (if possible), so pprof doesn't need to look at the binary.
+	var mapping protobuf
+	mapping.uint64(tagMapping_ID, 1)
+	mapping.bool(tagMapping_HasFunctions, b.symbolized)
+	mapping.bool(tagMapping_HasFilenames, b.symbolized)
+	mapping.bool(tagMapping_HasLineNumbers, b.symbolized)
+	mapping.bool(tagMapping_HasInlineFrames, b.symbolized)
+	b.pb.message(tagProfile_Mapping, &mapping)
+
+	b.pb.data = append(b.pb.data, b.locs.data...)
+	b.pb.data = append(b.pb.data, b.funcs.data...)
+	for _, s := range b.strings {
+		b.pb.string(tagProfile_StringTable, s)
+	}
+	return b.pb.data
+}
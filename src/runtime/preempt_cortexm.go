@@ -0,0 +1,52 @@
+//go:build preempt
+// +build preempt
+
+package runtime
+
+import (
+	"device/arm"
+	"internal/task"
+)
+
+// preemptTimeSlice is the time slice in microseconds, as passed with the
+// -preempt flag.
+var preemptTimeSlice string
+
+// startPreemption starts preempting goroutines that run longer than the time
+// slice. The SysTick timer counts CPU cycles, so the CPU frequency is measured
+// first.
+func startPreemption() {
+	var slice uint64
+	for i := 0; i < len(preemptTimeSlice); i++ {
+		slice = slice*10 + uint64(preemptTimeSlice[i]-'0')
+	}
+
+	task.StartPreemption(uint64(cyclesPerMillisecond()) * slice / 1000)
+}
+
+// cyclesPerMillisecond measures the number of SysTick (CPU) cycles in one
+// millisecond of the runtime clock.
+func cyclesPerMillisecond() uint32 {
+	// Let SysTick count down from its maximum value, without interrupts.
+	arm.SYST.SYST_RVR.Set(arm.SYST_RVR_RELOAD_Msk)
+	arm.SYST.SYST_CVR.Set(0)
+	arm.SYST.SYST_CSR.Set(arm.SYST_CSR_ENABLE | arm.SYST_CSR_CLKSOURCE)
+
+	// Wait for the start of a new tick, for a more precise measurement.
+	start := ticks()
+	for ticks() == start {
+		if arm.SYST.SYST_CSR.HasBits(arm.SYST_CSR_COUNTFLAG) {
+			// The runtime clock doesn't advance by itself (as in QEMU). Use
+			// the SysTick calibration value instead, which is the number of
+			// cycles in 10ms.
+			return (arm.SYST.SYST_CALIB.Get() & arm.SYST_CALIB_TENMS_Msk) / 10
+		}
+	}
+
+	start = ticks()
+	startCount := arm.SYST.SYST_CVR.Get()
+	period := nanosecondsToTicks(1e6)
+	for ticks()-start < period {
+	}
+	return (startCount - arm.SYST.SYST_CVR.Get()) & arm.SYST_CVR_CURRENT_Msk
+}
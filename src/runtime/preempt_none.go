@@ -0,0 +1,7 @@
+//go:build !preempt
+// +build !preempt
+
+package runtime
+
+// startPreemption does nothing: goroutines only switch cooperatively.
+func startPreemption() {}
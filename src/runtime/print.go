@@ -365,6 +365,29 @@ func printptr(ptr uintptr) {
 	}
 }
 
+// printhex prints an unsigned integer in hexadecimal notation, without leading
+// zeroes.
+func printhex(n uintptr) {
+	putchar('0')
+	putchar('x')
+	shift := unsafe.Sizeof(n)*8 - 4
+	for shift > 0 && n>>shift == 0 {
+		shift -= 4
+	}
+	for {
+		nibble := byte(n>>shift) & 0xf
+		if nibble < 10 {
+			putchar(nibble + '0')
+		} else {
+			putchar(nibble - 10 + 'a')
+		}
+		if shift == 0 {
+			break
+		}
+		shift -= 4
+	}
+}
+
 func printbool(b bool) {
 	if b {
 		printstring("true")
@@ -0,0 +1,346 @@
+# GDB extension to inspect goroutines of TinyGo programs.
+#
+# It is loaded automatically by `tinygo gdb`. To load it in a different GDB
+# session, use:
+#
+#     source <tinygoroot>/src/runtime/runtime-gdb.py
+#
+# It adds the following commands:
+#
+#     info goroutines
+#         List the running goroutine(s), the goroutines in the runqueue and the
+#         goroutines in the sleep queue. With -tags=goroutinedebug, goroutines
+#         that are blocked on something else are listed too.
+#     info channel <expr>
+#         Print the state of a channel, including the goroutines that are
+#         blocked on it.
+#     goroutine <addr> <cmd>
+#         Run a GDB command (such as `bt` or `info locals`) on the saved stack
+#         of a paused goroutine, identified by its *internal/task.Task address.
+#
+# Goroutines that are waiting on something else (a channel, sync.Mutex, etc)
+# are only part of a global list when the program is built with
+# -tags=goroutinedebug. Without it, they can't be listed by `info goroutines`,
+# but they can still be inspected with `info channel` or by passing the task
+# address found in the relevant data structure to `goroutine`.
+#
+# Switching to the stack of a goroutine is supported with -scheduler=tasks (or
+# -scheduler=cores) on Cortex-M, RISC-V and amd64.
+
+import struct
+
+import gdb
+
+# Goroutine states, as shown in `info goroutines`.
+STATE_RUNNING = 'running'
+STATE_RUNNABLE = 'runnable'
+STATE_SLEEPING = 'sleeping'
+STATE_BLOCKED = 'blocked'
+
+# Wait reasons recorded with -tags=goroutinedebug, see WaitReason in
+# src/internal/task/debug.go.
+WAIT_REASONS = [
+    'waiting',
+    'chan send',
+    'chan receive',
+    'select',
+    'sleep',
+    'sync.Mutex.Lock',
+    'sync.RWMutex.Lock',
+    'sync.RWMutex.RLock',
+    'sync.Cond.Wait',
+    'sync.WaitGroup.Wait',
+    'interrupt',
+    'IO wait',
+    'blocked forever',
+]
+
+# Channel states, see chanState in src/runtime/chan.go.
+CHAN_STATES = ['empty', 'recv', 'send', 'buffered', 'closed']
+
+
+def lookup_global(name):
+    """Return the value of a Go global variable, or None if it doesn't exist
+    (for example because it was optimized away)."""
+    try:
+        return gdb.parse_and_eval("'" + name + "'")
+    except gdb.error:
+        return None
+
+
+def symbol_address(name):
+    """Return the address of a (non-Go) symbol, or None if it doesn't exist."""
+    try:
+        return int(gdb.parse_and_eval("(unsigned long)&'" + name + "'"))
+    except gdb.error:
+        return None
+
+
+def pointer_size():
+    return gdb.lookup_type('void').pointer().sizeof
+
+
+def read_words(addr, count):
+    """Read a number of pointer-sized little-endian words from memory."""
+    size = pointer_size()
+    fmt = '<' + ('I' if size == 4 else 'Q') * count
+    data = gdb.selected_inferior().read_memory(addr, size * count)
+    return list(struct.unpack(fmt, bytes(data)))
+
+
+def task_list(head):
+    """Iterate over a linked list of tasks, linked through Task.Next."""
+    seen = set()
+    t = head
+    while int(t) != 0 and int(t) not in seen:
+        seen.add(int(t))
+        yield t
+        t = t.dereference()['Next']
+
+
+def current_tasks():
+    """Return the goroutines that are currently running (one per core)."""
+    current = lookup_global('internal/task.currentTask')
+    if current is None:
+        return []
+    if current.type.strip_typedefs().code != gdb.TYPE_CODE_ARRAY:
+        return [current] if int(current) != 0 else []
+    low, high = current.type.strip_typedefs().range()
+    return [current[i] for i in range(low, high + 1) if int(current[i]) != 0]
+
+
+def debug_tasks():
+    """Return the list of all goroutines kept with -tags=goroutinedebug, or
+    None if the program wasn't built with it."""
+    allTasks = lookup_global('internal/task.allTasks')
+    if allTasks is None:
+        return None
+    tasks = []
+    seen = set()
+    t = allTasks
+    while int(t) != 0 and int(t) not in seen:
+        seen.add(int(t))
+        tasks.append(t)
+        t = t.dereference()['debug']['next']
+    return tasks
+
+
+def wait_reason(task):
+    """Return a description of what a blocked goroutine waits for, as recorded
+    with -tags=goroutinedebug."""
+    debug = task.dereference()['debug']
+    reason = int(debug['Reason'])
+    name = WAIT_REASONS[reason] if reason < len(WAIT_REASONS) else str(reason)
+    if int(debug['Object']) != 0:
+        name += ' 0x%x' % int(debug['Object'])
+    return name
+
+
+def all_goroutines():
+    """Return a list of (task, state) tuples of all known goroutines."""
+    goroutines = []
+    for t in current_tasks():
+        goroutines.append((t, STATE_RUNNING))
+    runqueue = lookup_global('runtime.runqueue')
+    if runqueue is not None:
+        for t in task_list(runqueue['head']):
+            goroutines.append((t, STATE_RUNNABLE))
+    sleepQueue = lookup_global('runtime.sleepQueue')
+    if sleepQueue is not None:
+        for t in task_list(sleepQueue):
+            goroutines.append((t, STATE_SLEEPING))
+    tasks = debug_tasks()
+    if tasks is not None:
+        # All other goroutines are blocked on a channel, mutex, etc.
+        seen = set(int(t) for t, state in goroutines)
+        for t in tasks:
+            if int(t) not in seen:
+                goroutines.append((t, STATE_BLOCKED))
+    return goroutines
+
+
+class StackSwitcher:
+    """Temporarily load the registers of a paused goroutine, so that GDB
+    commands (like `bt`) operate on its stack. The original registers are
+    restored on exit."""
+
+    def __init__(self, task):
+        self.task = task
+        self.saved = {}
+
+    def saved_registers(self):
+        """Return a dict with the registers that were saved when the
+        goroutine paused, as stored by tinygo_swapTask."""
+        sp = int(self.task.dereference()['state']['sp'])
+        if sp == 0:
+            raise gdb.GdbError('goroutine has no saved stack (is it running?)')
+        arch = gdb.selected_frame().architecture().name()
+        if arch.startswith('arm') and symbol_address('tinygo_switchToScheduler') is not None:
+            # Cortex-M: {r4-r11, pc}
+            words = read_words(sp, 9)
+            regs = {'r%d' % (i + 4): words[i] for i in range(8)}
+            preemptResume = symbol_address('tinygo_preemptResume')
+            if preemptResume is not None and words[8] & ~1 == preemptResume & ~1:
+                # The goroutine was preempted (-preempt). Above the saved
+                # registers is the EXC_RETURN value, a padding word and the
+                # exception frame {r0-r3, r12, lr, pc, xPSR}.
+                frame = read_words(sp + 44, 8)
+                regs.update({'r0': frame[0], 'r1': frame[1], 'r2': frame[2], 'r3': frame[3], 'r12': frame[4], 'lr': frame[5], 'pc': frame[6]})
+                regs['sp'] = sp + 44 + 32 + (4 if frame[7] & (1 << 9) else 0)
+            else:
+                regs['pc'] = words[8] & ~1
+                regs['lr'] = words[8]
+                regs['sp'] = sp + 36
+            return regs
+        if arch.startswith('riscv'):
+            # {s0-s11, ra}
+            words = read_words(sp, 13)
+            regs = {'s%d' % i: words[i] for i in range(12)}
+            regs['pc'] = words[12]
+            regs['ra'] = words[12]
+            regs['sp'] = sp + 13 * pointer_size()
+            return regs
+        if arch == 'i386:x86-64':
+            # {rbx, rbp, r12-r15, return address}
+            words = read_words(sp, 7)
+            regs = dict(zip(['rbx', 'rbp', 'r12', 'r13', 'r14', 'r15'], words))
+            regs['rip'] = words[6]
+            regs['rsp'] = sp + 7 * 8
+            return regs
+        raise gdb.GdbError('switching to a goroutine stack is not supported on ' + arch)
+
+    def __enter__(self):
+        regs = self.saved_registers()
+        # Registers can only be changed this way in the innermost frame.
+        frame = gdb.newest_frame()
+        frame.select()
+        for name in regs:
+            self.saved[name] = int(frame.read_register(name))
+        try:
+            for name, value in regs.items():
+                gdb.execute('set $%s = %d' % (name, value), to_string=True)
+        except gdb.error:
+            self.restore()
+            raise
+        return self
+
+    def __exit__(self, *args):
+        self.restore()
+        return False
+
+    def restore(self):
+        gdb.newest_frame().select()
+        for name, value in self.saved.items():
+            gdb.execute('set $%s = %d' % (name, value), to_string=True)
+
+
+def user_location():
+    """Return a description of the innermost frame that is not part of the
+    runtime or the scheduler."""
+    frame = gdb.newest_frame()
+    while frame is not None:
+        name = frame.name() or ''
+        if not (name.startswith('runtime.') or name.startswith('internal/task.') or name.startswith('tinygo_')):
+            break
+        frame = frame.older()
+    if frame is None:
+        return '??'
+    sal = frame.find_sal()
+    location = frame.name() or '0x%x' % frame.pc()
+    if sal.symtab is not None:
+        location += ' at %s:%d' % (sal.symtab.filename, sal.line)
+    return location
+
+
+def goroutine_location(task, state):
+    try:
+        if state == STATE_RUNNING:
+            return user_location()
+        with StackSwitcher(task):
+            return user_location()
+    except gdb.error as e:
+        return '(%s)' % e
+    except gdb.GdbError as e:
+        return '(%s)' % e
+
+
+class InfoGoroutines(gdb.Command):
+    """List the goroutines that are running, runnable or sleeping, and with
+-tags=goroutinedebug also the goroutines that are blocked.
+Usage: info goroutines"""
+
+    def __init__(self):
+        super(InfoGoroutines, self).__init__('info goroutines', gdb.COMMAND_STACK, gdb.COMPLETE_NONE)
+
+    def invoke(self, arg, from_tty):
+        goroutines = all_goroutines()
+        if not goroutines:
+            print('No goroutines found (is this a TinyGo program that uses a scheduler?)')
+            return
+        for t, state in goroutines:
+            marker = '*' if state == STATE_RUNNING else ' '
+            location = goroutine_location(t, state)
+            if state == STATE_BLOCKED:
+                location = '[%s] %s' % (wait_reason(t), location)
+            print('%s 0x%x %-8s %s' % (marker, int(t), state, location))
+        if debug_tasks() is None:
+            print('(goroutines blocked on a channel, mutex, etc are only listed with -tags=goroutinedebug)')
+
+
+class InfoChannel(gdb.Command):
+    """Print the state of a channel and the goroutines that are blocked on it.
+Usage: info channel <expr>"""
+
+    def __init__(self):
+        super(InfoChannel, self).__init__('info channel', gdb.COMMAND_DATA, gdb.COMPLETE_EXPRESSION)
+
+    def invoke(self, arg, from_tty):
+        if not arg:
+            raise gdb.GdbError('usage: info channel <expr>')
+        ch = gdb.parse_and_eval(arg)
+        if int(ch) == 0:
+            print('nil channel')
+            return
+        ch = ch.cast(gdb.lookup_type('runtime.channel').pointer()).dereference()
+        state = int(ch['state'])
+        stateName = CHAN_STATES[state] if state < len(CHAN_STATES) else str(state)
+        print('state: %s, buffered: %d/%d' % (stateName, int(ch['bufUsed']), int(ch['bufSize'])))
+        op = ch['blocked']
+        while int(op) != 0:
+            t = op.dereference()['t']
+            if int(op.dereference()['s']) != 0:
+                kind = 'select'
+            elif stateName == 'send':
+                kind = 'send'
+            else:
+                kind = 'recv'
+            print('  0x%x %-8s %-6s %s' % (int(t), STATE_BLOCKED, kind, goroutine_location(t, STATE_BLOCKED)))
+            op = op.dereference()['next']
+
+
+class Goroutine(gdb.Command):
+    """Run a command on the stack of a paused goroutine.
+Usage: goroutine <task address> <command>
+For example: goroutine 0x20000a10 bt"""
+
+    def __init__(self):
+        super(Goroutine, self).__init__('goroutine', gdb.COMMAND_STACK, gdb.COMPLETE_COMMAND)
+
+    def invoke(self, arg, from_tty):
+        parts = arg.split(None, 1)
+        if len(parts) != 2:
+            raise gdb.GdbError('usage: goroutine <task address> <command>')
+        taskType = gdb.lookup_type('internal/task.Task').pointer()
+        t = gdb.parse_and_eval(parts[0]).cast(taskType)
+        for running in current_tasks():
+            if int(running) == int(t):
+                # Already on the stack of this goroutine.
+                gdb.execute(parts[1], from_tty)
+                return
+        with StackSwitcher(t):
+            gdb.execute(parts[1], from_tty)
+
+
+InfoGoroutines()
+InfoChannel()
+Goroutine()
@@ -5,6 +5,7 @@ package runtime
 
 import (
 	"device/arm"
+	"internal/task"
 	"unsafe"
 )
 
@@ -23,6 +24,15 @@ func handleHardFault(sp *interruptStack) {
 	fault := GetFaultStatus()
 	spValid := !fault.Bus().ImpreciseDataBusError()
 
+	// Check whether a goroutine ran into its stack guard region. If the fault
+	// happened while pushing an exception frame, there is no fault address,
+	// but the goroutine stack pointer will be in or right below the guard.
+	if addr, ok := fault.Mem().Address(); ok {
+		task.CheckStackGuard(addr)
+	} else if fault.Mem().WileStackingException() {
+		task.CheckStackGuard(arm.AsmFull("mrs {}, PSP", nil))
+	}
+
 	print("fatal error: ")
 	if spValid && uintptr(unsafe.Pointer(sp)) < 0x20000000 {
 		print("stack overflow? ")
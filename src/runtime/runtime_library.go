@@ -0,0 +1,25 @@
+//go:build tinygo.library
+// +build tinygo.library
+
+package runtime
+
+// This file contains the entry point of libraries built with
+// -buildmode=c-archive or -buildmode=c-shared. There is no main function that
+// is called on startup. Instead, the C program must call tinygo_init once
+// before calling any exported Go function.
+
+var libraryInitialized bool
+
+// libraryInit initializes the heap and runs the initializers of all packages.
+// Calling it more than once has no effect.
+//
+//export tinygo_init
+func libraryInit() {
+	if libraryInitialized {
+		return
+	}
+	libraryInitialized = true
+	libraryPreinit()
+	initHeap()
+	initAll()
+}
@@ -84,6 +84,16 @@ func ticks() timeUnit {
 	return timeUnit(ticksToNanoseconds(timeUnit(getArmSystemTick())))
 }
 
+// There is no real-time clock available, so the time starts at the epoch when
+// the system boots.
+//go:linkname now time.now
+func now() (sec int64, nsec int32, mono int64) {
+	mono = nanotime()
+	sec = mono / (1000 * 1000 * 1000)
+	nsec = int32(mono - sec*(1000*1000*1000))
+	return
+}
+
 var stdoutBuffer = make([]byte, 120)
 var position = 0
 
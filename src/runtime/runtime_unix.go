@@ -112,6 +112,15 @@ func os_runtime_args() []string {
 	return args
 }
 
+// libraryPreinit is called from tinygo_init in programs built as a library.
+// The garbage collector scans the stack up to the stack pointer of that call,
+// so tinygo_init should be called close to the start of the thread that calls
+// into Go (for example, from main). Other threads must not call Go functions.
+func libraryPreinit() {
+	preinit()
+	stackTop = getCurrentStackPointer()
+}
+
 // Must be a separate function to get the correct stack pointer.
 //go:noinline
 func runMain() {
@@ -8,7 +8,8 @@ package runtime
 //
 // The scheduler is used both for the asyncify based scheduler and for the task
 // based scheduler. In both cases, the 'internal/task.Task' type is used to represent one
-// goroutine.
+// goroutine. The scheduler loop itself is in scheduler_singlecore.go, or in
+// scheduler_cores.go when goroutines are run on multiple cores.
 
 import (
 	"internal/task"
@@ -29,6 +30,10 @@ var (
 	sleepQueueBaseTime timeUnit
 )
 
+// secondaryStackTops contains the top of the system stack of the cores other
+// than the first, when running goroutines on multiple cores.
+var secondaryStackTops [task.NumCPU - 1]uintptr
+
 // Simple logging, for debugging.
 func scheduleLog(msg string) {
 	if schedulerDebug {
@@ -57,6 +62,13 @@ func scheduleLogChan(msg string, ch *channel, t *task.Task) {
 //     select{}
 //go:noinline
 func deadlock() {
+	blockForever(task.WaitForever)
+}
+
+// blockForever pauses the current goroutine without ever waking it up again.
+// The reason is only used for debugging, see task.SetWaitReason.
+func blockForever(reason task.WaitReason) {
+	task.SetWaitReason(reason, nil)
 	// call yield without requesting a wakeup
 	task.Pause()
 	panic("unreachable")
@@ -112,62 +124,6 @@ func addSleepTask(t *task.Task, duration timeUnit) {
 	*q = t
 }
 
-// Run the scheduler until all tasks have finished.
-func scheduler() {
-	// Main scheduler loop.
-	var now timeUnit
-	for !schedulerDone {
-		scheduleLog("")
-		scheduleLog("  schedule")
-		if sleepQueue != nil {
-			now = ticks()
-		}
-
-		// Add tasks that are done sleeping to the end of the runqueue so they
-		// will be executed soon.
-		if sleepQueue != nil && now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
-			t := sleepQueue
-			scheduleLogTask("  awake:", t)
-			sleepQueueBaseTime += timeUnit(t.Data)
-			sleepQueue = t.Next
-			t.Next = nil
-			runqueue.Push(t)
-		}
-
-		t := runqueue.Pop()
-		if t == nil {
-			if sleepQueue == nil {
-				if asyncScheduler {
-					// JavaScript is treated specially, see below.
-					return
-				}
-				waitForEvents()
-				continue
-			}
-			timeLeft := timeUnit(sleepQueue.Data) - (now - sleepQueueBaseTime)
-			if schedulerDebug {
-				println("  sleeping...", sleepQueue, uint(timeLeft))
-				for t := sleepQueue; t != nil; t = t.Next {
-					println("    task sleeping:", t, timeUnit(t.Data))
-				}
-			}
-			sleepTicks(timeLeft)
-			if asyncScheduler {
-				// The sleepTicks function above only sets a timeout at which
-				// point the scheduler will be called again. It does not really
-				// sleep. So instead of sleeping, we return and expect to be
-				// called again.
-				break
-			}
-			continue
-		}
-
-		// Run the given task.
-		scheduleLogTask("  run:", t)
-		t.Resume()
-	}
-}
-
 // This horrible hack exists to make WASM work properly.
 // When a WASM program calls into JS which calls back into WASM, the event with which we called back in needs to be handled before returning.
 // Thus there are two copies of the scheduler running at once.
@@ -187,6 +143,7 @@ func minSched() {
 }
 
 func Gosched() {
+	task.DisablePreemption()
 	runqueue.Push(task.Current())
 	task.Pause()
 }
@@ -12,7 +12,11 @@ func sleep(duration int64) {
 		return
 	}
 
+	i := lockScheduler()
 	addSleepTask(task.Current(), nanosecondsToTicks(duration))
+	task.SetWaitReason(task.WaitSleep, nil)
+	task.DisablePreemption()
+	unlockScheduler(i)
 	task.Pause()
 }
 
@@ -20,6 +24,7 @@ func sleep(duration int64) {
 // With a scheduler, init and the main function are invoked in a goroutine before starting the scheduler.
 func run() {
 	initHeap()
+	startPreemption()
 	go func() {
 		initAll()
 		callMain()
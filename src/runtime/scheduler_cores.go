@@ -0,0 +1,152 @@
+//go:build scheduler.cores
+// +build scheduler.cores
+
+package runtime
+
+// This file implements the scheduler loop for -scheduler=cores, where
+// goroutines are run on all cores of a chip. Every core runs the same loop and
+// takes goroutines from the shared runqueue, so a goroutine may continue on a
+// different core every time it is resumed.
+//
+// All shared scheduler state (the runqueue, the sleep queue and channels) is
+// protected by spinlocks. They are only held for short periods of time, and
+// interrupts are disabled on the current core while they are held.
+
+import (
+	"internal/task"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// Spinlocks protecting shared runtime state.
+var (
+	schedulerLock task.Spinlock // channels, the sleep queue and package sync
+	atomicsLock   task.Spinlock // atomics that are implemented in software
+	heapLock      task.Spinlock // the heap
+)
+
+// The scheduler lock may be taken recursively on the same core, as channel
+// operations call each other with the lock held. These variables are only
+// modified by the core holding the lock.
+var (
+	schedulerLockOwner uint32 // CPU number plus one, or zero if not locked
+	schedulerLockDepth uint32
+)
+
+// lockScheduler protects the scheduler state (such as channels and the sleep
+// queue) against concurrent modification from interrupts and other cores.
+func lockScheduler() interrupt.State {
+	i := interrupt.Disable()
+	cpu := uint32(task.CPU()) + 1
+	if volatile.LoadUint32(&schedulerLockOwner) != cpu {
+		schedulerLock.Lock()
+		volatile.StoreUint32(&schedulerLockOwner, cpu)
+	}
+	schedulerLockDepth++
+	return i
+}
+
+// unlockScheduler releases the lock obtained with lockScheduler.
+func unlockScheduler(i interrupt.State) {
+	schedulerLockDepth--
+	if schedulerLockDepth == 0 {
+		volatile.StoreUint32(&schedulerLockOwner, 0)
+		schedulerLock.Unlock()
+	}
+	interrupt.Restore(i)
+}
+
+// lockAtomics protects the atomic operations that are not natively supported
+// by the CPU, see atomics_critical.go.
+func lockAtomics() interrupt.State {
+	i := interrupt.Disable()
+	atomicsLock.Lock()
+	return i
+}
+
+// unlockAtomics releases the lock obtained with lockAtomics.
+func unlockAtomics(i interrupt.State) {
+	atomicsLock.Unlock()
+	interrupt.Restore(i)
+}
+
+// lockHeap makes sure only one core at a time allocates memory or runs the GC.
+// While waiting, this core stops for a GC cycle if the core holding the lock
+// requested that.
+func lockHeap() {
+	for !heapLock.TryLock() {
+		gcSafePoint()
+	}
+}
+
+// unlockHeap releases the lock obtained with lockHeap.
+func unlockHeap() {
+	heapLock.Unlock()
+}
+
+// Run the scheduler until all tasks have finished. This is called on the first
+// core, and starts the scheduler on the other cores as well.
+func scheduler() {
+	startSecondaryCores()
+	schedulerLoop()
+}
+
+// secondaryCoreMain is called from the startup code of the other cores, on
+// their own system stack.
+//export tinygo_secondaryCoreMain
+func secondaryCoreMain() {
+	schedulerLoop()
+
+	// The main goroutine has exited, and the first core will exit the program
+	// soon. Nothing else to do.
+	for {
+		gcSafePoint()
+		task.WaitForEvent()
+	}
+}
+
+// schedulerLoop runs goroutines on the current core until the main goroutine
+// exits.
+func schedulerLoop() {
+	// Let the other cores stop this core for the GC.
+	enableCoreInterrupt()
+
+	for volatile.LoadUint8((*uint8)(unsafe.Pointer(&schedulerDone))) == 0 {
+		// Stop here if another core is running the GC.
+		gcSafePoint()
+
+		// Add tasks that are done sleeping to the end of the runqueue so they
+		// will be executed soon.
+		i := lockScheduler()
+		sleeping := sleepQueue != nil
+		if sleepQueue != nil {
+			now := ticks()
+			if now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
+				t := sleepQueue
+				scheduleLogTask("  awake:", t)
+				sleepQueueBaseTime += timeUnit(t.Data)
+				sleepQueue = t.Next
+				t.Next = nil
+				runqueue.Push(t)
+			}
+		}
+		unlockScheduler(i)
+
+		t := runqueue.Pop()
+		if t == nil {
+			// Nothing to run on this core. Wait until a goroutine is pushed to
+			// the runqueue (by another core or an interrupt), or until the GC
+			// needs this core to stop. Sleeping goroutines are woken up by
+			// checking the time, so keep polling while there are any.
+			if !sleeping {
+				task.WaitForEvent()
+			}
+			continue
+		}
+
+		// Run the given task.
+		scheduleLogTask("  run:", t)
+		t.Resume()
+	}
+}
@@ -0,0 +1,109 @@
+//go:build scheduler.cores && rp2040
+// +build scheduler.cores,rp2040
+
+package runtime
+
+import (
+	"device/arm"
+	"internal/task"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// Defined in scheduler_cores_rp2040.S.
+//go:extern tinygo_core1Start
+var core1Start [0]uint8
+
+//go:extern tinygo_core1StackTop
+var core1StackTop [0]uint8
+
+// Inter-core FIFO registers of the SIO block.
+var (
+	sioFIFOStatus = (*volatile.Register32)(unsafe.Pointer(uintptr(0xd0000050)))
+	sioFIFOWrite  = (*volatile.Register32)(unsafe.Pointer(uintptr(0xd0000054)))
+	sioFIFORead   = (*volatile.Register32)(unsafe.Pointer(uintptr(0xd0000058)))
+)
+
+const (
+	sioFIFOStatusVLD = 1 << 0 // the read FIFO contains data
+	sioFIFOStatusRDY = 1 << 1 // the write FIFO has room for more data
+	sioFIFOStatusWOF = 1 << 2 // the write FIFO overflowed (write to clear)
+	sioFIFOStatusROE = 1 << 3 // the read FIFO was read while empty (write to clear)
+)
+
+// Inter-core FIFO interrupts. Every core has its own interrupt, which is
+// raised while its read FIFO contains data.
+const (
+	irqSIOProc0 = 15
+	irqSIOProc1 = 16
+)
+
+// Vector table offset register, shared with the boot ROM of the second core.
+var scbVTOR = (*volatile.Register32)(unsafe.Pointer(uintptr(0xe000ed08)))
+
+// startSecondaryCores starts the second core, which is waiting in the boot ROM
+// for a command over the inter-core FIFO. It follows the same protocol as
+// multicore_launch_core1 in the Pico SDK: the sequence is echoed back value by
+// value and restarted if the second core sends something else.
+func startSecondaryCores() {
+	secondaryStackTops[0] = uintptr(unsafe.Pointer(&core1StackTop))
+	sequence := [...]uint32{0, 0, 1, scbVTOR.Get(), uint32(secondaryStackTops[0]), uint32(uintptr(unsafe.Pointer(&core1Start)))}
+	for i := 0; i < len(sequence); {
+		cmd := sequence[i]
+		if cmd == 0 {
+			// Drain the FIFO before sending a zero, and wake up the other
+			// core in case it was waiting for space in the FIFO.
+			for sioFIFOStatus.HasBits(sioFIFOStatusVLD) {
+				sioFIFORead.Get()
+			}
+			arm.Asm("sev")
+		}
+		for !sioFIFOStatus.HasBits(sioFIFOStatusRDY) {
+		}
+		sioFIFOWrite.Set(cmd)
+		arm.Asm("sev")
+		for !sioFIFOStatus.HasBits(sioFIFOStatusVLD) {
+			arm.Asm("wfe")
+		}
+		if sioFIFORead.Get() == cmd {
+			i++
+		} else {
+			i = 0
+		}
+	}
+}
+
+// enableCoreInterrupt enables the inter-core FIFO interrupt on the current
+// core. It must only be called once the second core has been started, as the
+// FIFO is used to start it.
+func enableCoreInterrupt() {
+	// Every core has its own NVIC, so this only enables the interrupt on the
+	// current core.
+	if task.CPU() == 0 {
+		interrupt.New(irqSIOProc0, handleCoreInterrupt).Enable()
+	} else {
+		interrupt.New(irqSIOProc1, handleCoreInterrupt).Enable()
+	}
+}
+
+// gcInterruptCores interrupts the other core by sending it a word over the
+// inter-core FIFO, so that it stops for the GC right away.
+func gcInterruptCores() {
+	if sioFIFOStatus.HasBits(sioFIFOStatusRDY) {
+		sioFIFOWrite.Set(0)
+	}
+	// If the FIFO is full, the other core has not yet handled an earlier
+	// interrupt and will see this GC cycle when it does.
+}
+
+// handleCoreInterrupt is called when the other core sent a word over the
+// inter-core FIFO, see gcInterruptCores.
+func handleCoreInterrupt(interrupt.Interrupt) {
+	// The interrupt stays pending while there is data in the FIFO.
+	for sioFIFOStatus.HasBits(sioFIFOStatusVLD) {
+		sioFIFORead.Get()
+	}
+	sioFIFOStatus.Set(sioFIFOStatusWOF | sioFIFOStatusROE)
+	gcSafePoint()
+}
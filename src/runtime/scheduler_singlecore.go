@@ -0,0 +1,119 @@
+//go:build !scheduler.cores
+// +build !scheduler.cores
+
+package runtime
+
+// This file contains the scheduler loop and locking functions for when
+// goroutines only run on a single core. The only source of concurrency is
+// interrupts.
+
+import (
+	"internal/task"
+	"runtime/interrupt"
+)
+
+// Run the scheduler until all tasks have finished.
+func scheduler() {
+	// Main scheduler loop.
+	var now timeUnit
+	for !schedulerDone {
+		scheduleLog("")
+		scheduleLog("  schedule")
+		if sleepQueue != nil {
+			now = ticks()
+		}
+
+		// Add tasks that are done sleeping to the end of the runqueue so they
+		// will be executed soon.
+		if sleepQueue != nil && now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
+			t := sleepQueue
+			scheduleLogTask("  awake:", t)
+			sleepQueueBaseTime += timeUnit(t.Data)
+			sleepQueue = t.Next
+			t.Next = nil
+			runqueue.Push(t)
+		}
+
+		t := runqueue.Pop()
+		if t == nil {
+			if sleepQueue == nil {
+				if asyncScheduler {
+					// JavaScript is treated specially, see below.
+					return
+				}
+				if netpollPending() {
+					// Wait until a file descriptor is ready.
+					netpoll(-1)
+					continue
+				}
+				reportBlockedGoroutines()
+				waitForEvents()
+				continue
+			}
+			timeLeft := timeUnit(sleepQueue.Data) - (now - sleepQueueBaseTime)
+			if schedulerDebug {
+				println("  sleeping...", sleepQueue, uint(timeLeft))
+				for t := sleepQueue; t != nil; t = t.Next {
+					println("    task sleeping:", t, timeUnit(t.Data))
+				}
+			}
+			if netpollPending() {
+				// Wait until a file descriptor is ready or the next goroutine
+				// wakes up.
+				netpoll(ticksToNanoseconds(timeLeft))
+			} else {
+				sleepTicks(timeLeft)
+			}
+			if asyncScheduler {
+				// The sleepTicks function above only sets a timeout at which
+				// point the scheduler will be called again. It does not really
+				// sleep. So instead of sleeping, we return and expect to be
+				// called again.
+				break
+			}
+			continue
+		}
+
+		// Don't let goroutines waiting for I/O starve.
+		netpollCheck()
+
+		// Run the given task.
+		scheduleLogTask("  run:", t)
+		t.Resume()
+	}
+}
+
+// lockScheduler protects the scheduler state (such as channels, the sleep queue
+// and package sync) against concurrent modification from interrupts. It may be
+// called recursively.
+func lockScheduler() interrupt.State {
+	return interrupt.Disable()
+}
+
+// unlockScheduler releases the lock obtained with lockScheduler.
+func unlockScheduler(i interrupt.State) {
+	interrupt.Restore(i)
+}
+
+// lockAtomics protects the atomic operations that are not natively supported
+// by the CPU, see atomics_critical.go.
+func lockAtomics() interrupt.State {
+	return interrupt.Disable()
+}
+
+// unlockAtomics releases the lock obtained with lockAtomics.
+func unlockAtomics(i interrupt.State) {
+	interrupt.Restore(i)
+}
+
+// lockHeap makes sure the current goroutine isn't preempted while it allocates
+// memory or runs the GC. Interrupts may not allocate memory, so nothing else is
+// needed on a single core.
+func lockHeap() {
+	task.LockPreemption()
+}
+
+// unlockHeap releases the lock obtained with lockHeap.
+func unlockHeap() {
+	task.UnlockPreemption()
+}
@@ -1,5 +1,5 @@
-//go:build scheduler.tasks
-// +build scheduler.tasks
+//go:build scheduler.tasks || scheduler.cores
+// +build scheduler.tasks scheduler.cores
 
 package runtime
 
@@ -1,24 +1,121 @@
 package runtime
 
+// Func represents a function in the running binary.
 type Func struct {
+	loc funcLocation
 }
 
+// FuncForPC returns a *Func describing the function that contains the given
+// program counter address, or else nil.
 func FuncForPC(pc uintptr) *Func {
-	return nil
+	loc, ok := lookupPC(pc)
+	if !ok {
+		return nil
+	}
+	return &Func{loc: loc}
 }
 
+// Name returns the name of the function.
 func (f *Func) Name() string {
-	return ""
+	if f == nil {
+		return ""
+	}
+	return f.loc.name
 }
 
+// Entry returns the entry address of the function.
+func (f *Func) Entry() uintptr {
+	if f == nil {
+		return 0
+	}
+	return f.loc.entry
+}
+
+// FileLine returns the file name and line number of the source code
+// corresponding to the program counter pc.
 func (f *Func) FileLine(pc uintptr) (file string, line int) {
-	return "", 0
+	loc, ok := lookupPC(pc)
+	if !ok {
+		return "", 0
+	}
+	return loc.file, loc.line
 }
 
+// Caller reports file and line number information about function invocations
+// on the calling goroutine's stack. The argument skip is the number of stack
+// frames to ascend, with 0 identifying the caller of Caller.
+//go:noinline
 func Caller(skip int) (pc uintptr, file string, line int, ok bool) {
-	return 0, "", 0, false
+	var pcs [1]uintptr
+	if callers(skip+1, pcs[:]) == 0 {
+		return 0, "", 0, false
+	}
+	pc = pcs[0] - 1
+	loc, ok := lookupPC(pc)
+	return pc, loc.file, loc.line, ok
 }
 
+// Stack formats a stack trace of the calling goroutine into buf and returns the
+// number of bytes written to buf. Stack traces of other goroutines are not
+// supported, so all is ignored.
+//go:noinline
 func Stack(buf []byte, all bool) int {
-	return 0
+	var pcs [32]uintptr
+	frames := CallersFrames(pcs[:callers(1, pcs[:])])
+	n := 0
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "" && frame.PC == 0 {
+			break
+		}
+		if frame.Function != "" {
+			n += copy(buf[n:], frame.Function)
+			n += copy(buf[n:], "()\n\t")
+			n += copy(buf[n:], frame.File)
+			n += copy(buf[n:], ":")
+			n += copy(buf[n:], itoa(uint64(frame.Line)))
+		} else {
+			n += copy(buf[n:], "?\n\t")
+			n += copy(buf[n:], hexString(uint64(frame.PC)))
+		}
+		n += copy(buf[n:], "\n")
+		if !more {
+			break
+		}
+	}
+	return n
+}
+
+// itoa converts an unsigned integer to a decimal string.
+func itoa(n uint64) string {
+	var buf [20]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = byte('0' + n%10)
+		n /= 10
+		if n == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
+
+// hexString converts an unsigned integer to a hexadecimal string with a 0x
+// prefix.
+func hexString(n uint64) string {
+	var buf [18]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = "0123456789abcdef"[n%16]
+		n /= 16
+		if n == 0 {
+			break
+		}
+	}
+	i -= 2
+	buf[i] = '0'
+	buf[i+1] = 'x'
+	return string(buf[i:])
 }
@@ -1,21 +1,70 @@
 package runtime
 
+// funcLocation is the information known about a single program counter.
+type funcLocation struct {
+	name  string
+	file  string
+	line  int
+	entry uintptr
+}
+
+// Frames may be used to get function/file/line information for a slice of PC
+// values returned by Callers.
 type Frames struct {
-	//
+	callers []uintptr
 }
 
+// Frame is the information returned by Frames for each call frame.
 type Frame struct {
+	// PC is the program counter for the location in this frame.
+	PC uintptr
+
+	// Func is the Func value of this call frame. This may be nil if the
+	// function is not known.
+	Func *Func
+
+	// Function is the package path-qualified function name of this call
+	// frame. If non-empty, this string uniquely identifies a single function
+	// in the program.
 	Function string
 
+	// File and Line are the file name and line number of the location in
+	// this frame. These may be empty or zero if not known.
 	File string
 	Line int
-	PC   uintptr
+
+	// Entry point program counter for the function; may be zero if not
+	// known.
+	Entry uintptr
 }
 
+// CallersFrames takes a slice of PC values returned by Callers and prepares to
+// return function/file/line information. Do not change the slice until you are
+// done with the Frames.
 func CallersFrames(callers []uintptr) *Frames {
-	return nil
+	return &Frames{callers: callers}
 }
 
+// Next returns a Frame representing the next call frame in the slice of PC
+// values, and reports whether there are more frames after it.
+//
+// Note that unlike the standard Go runtime, inlined functions are not reported
+// as separate frames.
 func (ci *Frames) Next() (frame Frame, more bool) {
-	return Frame{}, false
+	if len(ci.callers) == 0 {
+		return Frame{}, false
+	}
+	// The program counters returned by Callers are return addresses, which
+	// point to the instruction after the call. Subtract one to get the
+	// location of the call itself.
+	frame.PC = ci.callers[0] - 1
+	ci.callers = ci.callers[1:]
+	if loc, ok := lookupPC(frame.PC); ok {
+		frame.Func = &Func{loc: loc}
+		frame.Function = loc.name
+		frame.File = loc.file
+		frame.Line = loc.line
+		frame.Entry = loc.entry
+	}
+	return frame, len(ci.callers) != 0
 }
@@ -0,0 +1,84 @@
+//go:build (amd64 || arm64 || 386 || arm) && !windows && !avr && !xtensa && !tinygo.riscv && (!baremetal || cortexm || framepointers) && !noframepointers
+// +build amd64 arm64 386 arm
+// +build !windows
+// +build !avr
+// +build !xtensa
+// +build !tinygo.riscv
+// +build !baremetal cortexm framepointers
+// +build !noframepointers
+
+package runtime
+
+// Stack unwinding using frame pointers. The compiler keeps the frame pointer in
+// every function on these architectures (see compileopts.Config.FramePointers),
+// so the stack forms a linked list of frames. In every frame, the frame
+// pointer points to the saved frame pointer of the parent frame, directly
+// followed by the return address:
+//
+//     fp -> [previous fp]
+//           [return address]
+//
+// This is true for amd64 (rbp), arm64 (x29), 386 (ebp) and ARM (r11 in ARM
+// mode, r7 in Thumb mode).
+//
+// Baremetal systems other than Cortex-M only keep frame pointers with
+// -tags=framepointers. They can be removed with -tags=noframepointers.
+
+import "unsafe"
+
+//export llvm.frameaddress.p0i8
+func frameAddress(level int32) unsafe.Pointer
+
+// callers stores the return addresses of the functions on the current stack in
+// pc. The first return address is the one into the caller of callers, so a
+// skip of 0 starts with the function that called callers.
+//
+// The frame pointer chain is terminated by a zero frame pointer: the C startup
+// code on Linux sets it to zero and new goroutines start with a zeroed register
+// state. On baremetal systems the frame pointer is not initialized on reset, so
+// the walk is also stopped at the top of the system stack.
+//go:noinline
+func callers(skip int, pc []uintptr) int {
+	fp := uintptr(frameAddress(0))
+	top := ^uintptr(0)
+	if fp < stackTop {
+		top = stackTop
+	}
+	return callersFrom(fp, fp, top, skip, pc)
+}
+
+// callersFrom is like callers, but starts walking the stack at the given frame
+// pointer. Every frame must lie within the stack between bottom and top, so
+// that a corrupted frame pointer (for example in a signal handler that
+// interrupted a function prologue) doesn't cause an invalid memory access.
+func callersFrom(fp, bottom, top uintptr, skip int, pc []uintptr) int {
+	const frameSize = unsafe.Sizeof(uintptr(0)) * 2
+	n := 0
+	for fp != 0 && n < len(pc) {
+		if fp%unsafe.Alignof(uintptr(0)) != 0 {
+			break // corrupted frame pointer
+		}
+		if fp < bottom || fp >= top || top-fp < frameSize {
+			break // outside the stack
+		}
+		next := *(*uintptr)(unsafe.Pointer(fp))
+		returnAddress := *(*uintptr)(unsafe.Pointer(fp + unsafe.Sizeof(uintptr(0))))
+		if returnAddress == 0 {
+			break
+		}
+		if skip > 0 {
+			skip--
+		} else {
+			pc[n] = returnAddress
+			n++
+		}
+		if next <= fp {
+			// The stack grows down, so parent frames must be at a higher
+			// address. Anything else is the end of the chain (or a corrupted
+			// stack).
+			break
+		}
+		fp = next
+	}
+	return n
+}
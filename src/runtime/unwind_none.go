@@ -0,0 +1,15 @@
+//go:build ((!amd64 && !arm64 && !386 && !arm) || windows || avr || xtensa || tinygo.riscv || (baremetal && !cortexm && !framepointers) || noframepointers) && (!tinygo.wasm || (!gc.conservative && !gc.precise))
+// +build !amd64,!arm64,!386,!arm windows avr xtensa tinygo.riscv baremetal,!cortexm,!framepointers noframepointers
+// +build !tinygo.wasm !gc.conservative,!gc.precise
+
+package runtime
+
+// callers is not supported on this architecture (or frame pointers were not
+// kept): there is no way to walk the stack.
+func callers(skip int, pc []uintptr) int {
+	return 0
+}
+
+func callersFrom(fp, bottom, top uintptr, skip int, pc []uintptr) int {
+	return 0
+}
@@ -0,0 +1,73 @@
+//go:build tinygo.wasm && (gc.conservative || gc.precise)
+// +build tinygo.wasm
+// +build gc.conservative gc.precise
+
+package runtime
+
+// Stack unwinding on WebAssembly. It is not possible to inspect the call stack
+// in WebAssembly, but there is already a linked list of stack objects for the
+// garbage collector (see gc_stack_portable.go). When callers is used, the
+// compiler stores a pointer to the function name and source location directly
+// after the stack slots of each stack object, which is used here as a program
+// counter.
+//
+// Note that only functions that keep pointers on the stack have a stack
+// object, so a stack trace on WebAssembly only contains those functions and
+// only has the line number where the function starts.
+
+import "unsafe"
+
+// stackFuncInfo is the function information created by the compiler for each
+// stack object (see transform.MakeGCStackSlots).
+type stackFuncInfo struct {
+	name string
+	file string
+	line uintptr
+}
+
+// callers stores pointers to the function information of the functions on the
+// current stack in pc. These pointers are incremented by one, so that they
+// behave like return addresses (which point just past the call instruction).
+// The callers and Callers functions do not have a stack object themselves, so
+// the skip count is decremented by one to be compatible with other
+// architectures.
+func callers(skip int, pc []uintptr) int {
+	skip--
+	n := 0
+	for obj := stackChainStart; obj != nil && n < len(pc); obj = obj.parent {
+		funcInfo := stackObjectFuncInfo(obj)
+		if funcInfo == nil {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		pc[n] = uintptr(unsafe.Pointer(funcInfo)) + 1
+		n++
+	}
+	return n
+}
+
+// stackObjectFuncInfo returns the function information stored after the stack
+// slots of the given stack object.
+func stackObjectFuncInfo(obj *stackChainObject) *stackFuncInfo {
+	start := uintptr(unsafe.Pointer(obj)) + unsafe.Sizeof(uintptr(0))*2
+	end := start + obj.numSlots*unsafe.Alignof(uintptr(0))
+	return *(**stackFuncInfo)(unsafe.Pointer(end))
+}
+
+// lookupPC returns the function information for the given "program counter",
+// which is a pointer to a stackFuncInfo.
+func lookupPC(pc uintptr) (fn funcLocation, ok bool) {
+	if pc == 0 || pc%unsafe.Alignof(uintptr(0)) != 0 {
+		return
+	}
+	info := (*stackFuncInfo)(unsafe.Pointer(pc))
+	return funcLocation{
+		name:  info.name,
+		file:  info.file,
+		line:  int(info.line),
+		entry: pc,
+	}, true
+}
@@ -1,6 +1,9 @@
 package sync
 
-import "internal/task"
+import (
+	"internal/task"
+	"unsafe"
+)
 
 type Cond struct {
 	L Locker
@@ -20,6 +23,8 @@ func NewCond(l Locker) *Cond {
 	return &Cond{L: l}
 }
 
+// trySignal signals one waiter, if there is any. It must be called with the
+// scheduler lock held.
 func (c *Cond) trySignal() bool {
 	// Pop a blocked task off of the stack, and schedule it if applicable.
 	t := c.blocked.Pop()
@@ -40,21 +45,27 @@ func (c *Cond) trySignal() bool {
 }
 
 func (c *Cond) Signal() {
+	i := lockScheduler()
 	c.trySignal()
+	unlockScheduler(i)
 }
 
 func (c *Cond) Broadcast() {
 	// Signal everything.
+	i := lockScheduler()
 	for c.trySignal() {
 	}
+	unlockScheduler(i)
 }
 
 func (c *Cond) Wait() {
 	// Add an earlySignal frame to the stack so we can be signalled while unlocking.
+	i := lockScheduler()
 	early := earlySignal{
 		next: c.unlocking,
 	}
 	c.unlocking = &early
+	unlockScheduler(i)
 
 	// Temporarily unlock L.
 	c.L.Unlock()
@@ -63,7 +74,9 @@ func (c *Cond) Wait() {
 	defer c.L.Lock()
 
 	// If we were signaled while unlocking, immediately complete.
+	i = lockScheduler()
 	if early.signaled {
+		unlockScheduler(i)
 		return
 	}
 
@@ -80,5 +93,8 @@ func (c *Cond) Wait() {
 
 	// Wait for a signal.
 	c.blocked.Push(task.Current())
+	task.SetWaitReason(task.WaitCond, unsafe.Pointer(c))
+	task.DisablePreemption()
+	unlockScheduler(i)
 	task.Pause()
 }
@@ -2,7 +2,8 @@ package sync
 
 import (
 	"internal/task"
-	_ "unsafe"
+	"runtime/interrupt"
+	"unsafe"
 )
 
 type Mutex struct {
@@ -13,19 +14,34 @@ type Mutex struct {
 //go:linkname scheduleTask runtime.runqueuePushBack
 func scheduleTask(*task.Task)
 
+// The scheduler lock protects the state of all synchronization primitives in
+// this package against interrupts and, with -scheduler=cores, other cores.
+//go:linkname lockScheduler runtime.lockScheduler
+func lockScheduler() interrupt.State
+
+//go:linkname unlockScheduler runtime.unlockScheduler
+func unlockScheduler(interrupt.State)
+
 func (m *Mutex) Lock() {
+	i := lockScheduler()
 	if m.locked {
 		// Push self onto stack of blocked tasks, and wait to be resumed.
 		m.blocked.Push(task.Current())
+		task.SetWaitReason(task.WaitMutex, unsafe.Pointer(m))
+		task.DisablePreemption()
+		unlockScheduler(i)
 		task.Pause()
 		return
 	}
 
 	m.locked = true
+	unlockScheduler(i)
 }
 
 func (m *Mutex) Unlock() {
+	i := lockScheduler()
 	if !m.locked {
+		unlockScheduler(i)
 		panic("sync: unlock of unlocked Mutex")
 	}
 
@@ -35,6 +51,7 @@ func (m *Mutex) Unlock() {
 	} else {
 		m.locked = false
 	}
+	unlockScheduler(i)
 }
 
 type RWMutex struct {
@@ -58,29 +75,37 @@ const (
 )
 
 func (rw *RWMutex) Lock() {
+	i := lockScheduler()
 	if rw.state == 0 {
 		// The mutex is completely unlocked.
 		// Lock without waiting.
 		rw.state = rwMutexStateWLocked
+		unlockScheduler(i)
 		return
 	}
 
 	// Wait for the lock to be released.
 	rw.waitingWriters.Push(task.Current())
+	task.SetWaitReason(task.WaitRWMutexLock, unsafe.Pointer(rw))
+	task.DisablePreemption()
+	unlockScheduler(i)
 	task.Pause()
 }
 
 func (rw *RWMutex) Unlock() {
+	i := lockScheduler()
 	switch rw.state {
 	case rwMutexStateWLocked:
 		// This is correct.
 
 	case rwMutexStateUnlocked:
 		// The mutex is already unlocked.
+		unlockScheduler(i)
 		panic("sync: unlock of unlocked RWMutex")
 
 	default:
 		// The mutex is read-locked instead of write-locked.
+		unlockScheduler(i)
 		panic("sync: write-unlock of read-locked RWMutex")
 	}
 
@@ -95,32 +120,42 @@ func (rw *RWMutex) Unlock() {
 		// Nothing is waiting for the lock.
 		rw.state = rwMutexStateUnlocked
 	}
+	unlockScheduler(i)
 }
 
 func (rw *RWMutex) RLock() {
+	i := lockScheduler()
 	if rw.state == rwMutexStateWLocked {
 		// Wait for the write lock to be released.
 		rw.waitingReaders.Push(task.Current())
+		task.SetWaitReason(task.WaitRWMutexRLock, unsafe.Pointer(rw))
+		task.DisablePreemption()
+		unlockScheduler(i)
 		task.Pause()
 		return
 	}
 
 	if rw.state == rwMutexMaxReaders {
+		unlockScheduler(i)
 		panic("sync: too many readers on RWMutex")
 	}
 
 	// Increase the reader count.
 	rw.state++
+	unlockScheduler(i)
 }
 
 func (rw *RWMutex) RUnlock() {
+	i := lockScheduler()
 	switch rw.state {
 	case rwMutexStateUnlocked:
 		// The mutex is already unlocked.
+		unlockScheduler(i)
 		panic("sync: unlock of unlocked RWMutex")
 
 	case rwMutexStateWLocked:
 		// The mutex is write-locked instead of read-locked.
+		unlockScheduler(i)
 		panic("sync: read-unlock of write-locked RWMutex")
 	}
 
@@ -131,6 +166,7 @@ func (rw *RWMutex) RUnlock() {
 		// Try to unblock a writer.
 		rw.maybeUnblockWriter()
 	}
+	unlockScheduler(i)
 }
 
 func (rw *RWMutex) maybeUnblockReaders() bool {
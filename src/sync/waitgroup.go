@@ -1,6 +1,9 @@
 package sync
 
-import "internal/task"
+import (
+	"internal/task"
+	"unsafe"
+)
 
 type WaitGroup struct {
 	counter uint
@@ -8,6 +11,9 @@ type WaitGroup struct {
 }
 
 func (wg *WaitGroup) Add(delta int) {
+	i := lockScheduler()
+	defer unlockScheduler(i)
+
 	if delta > 0 {
 		// Check for overflow.
 		if uint(delta) > (^uint(0))-wg.counter {
@@ -26,8 +32,8 @@ func (wg *WaitGroup) Add(delta int) {
 		wg.counter -= uint(-delta)
 
 		// If the counter is zero, everything is done and the waiters should be resumed.
-		// This code assumes that the waiters cannot wake up until after this function returns.
-		// In the current implementation, this is always correct.
+		// The waiters may start running on another core right away, but they
+		// can't use the WaitGroup until the lock is released.
 		if wg.counter == 0 {
 			for t := wg.waiters.Pop(); t != nil; t = wg.waiters.Pop() {
 				scheduleTask(t)
@@ -41,13 +47,18 @@ func (wg *WaitGroup) Done() {
 }
 
 func (wg *WaitGroup) Wait() {
+	i := lockScheduler()
 	if wg.counter == 0 {
 		// Everything already finished.
+		unlockScheduler(i)
 		return
 	}
 
 	// Push the current goroutine onto the waiter stack.
 	wg.waiters.Push(task.Current())
+	task.SetWaitReason(task.WaitWaitGroup, unsafe.Pointer(wg))
+	task.DisablePreemption()
+	unlockScheduler(i)
 
 	// Pause until the waiters are awoken by Add/Done.
 	task.Pause()
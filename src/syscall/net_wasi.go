@@ -0,0 +1,203 @@
+//go:build wasi
+// +build wasi
+
+package syscall
+
+// Sockets on WASI.
+//
+// WASI (snapshot preview1) cannot create sockets, but it can accept
+// connections on and send and receive data over sockets that are preopened by
+// the host (for example, with wasmtime --tcplisten). The remaining socket
+// functions exist so that the same code compiles for Linux and WASI, and
+// always return ENOSYS.
+
+// https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__header_sys_socket.h
+const (
+	AF_UNSPEC = 0
+	AF_INET   = 1
+	AF_INET6  = 2
+	AF_UNIX   = 3
+
+	SOCK_DGRAM    = 5
+	SOCK_STREAM   = 6
+	SOCK_NONBLOCK = 0x4000
+	SOCK_CLOEXEC  = 0x2000
+
+	IPPROTO_IP   = 0
+	IPPROTO_TCP  = 6
+	IPPROTO_UDP  = 17
+	IPPROTO_IPV6 = 41
+
+	SOL_SOCKET   = 0x7fffffff
+	SO_REUSEADDR = 2
+	SO_TYPE      = 3
+	SO_ERROR     = 4
+
+	SHUT_RD   = 1
+	SHUT_WR   = 2
+	SHUT_RDWR = SHUT_RD | SHUT_WR
+
+	MSG_PEEK    = 1
+	MSG_WAITALL = 2
+
+	// WASI has no signals, so this flag only exists for compatibility.
+	MSG_NOSIGNAL = 0
+)
+
+type Sockaddr interface {
+	sockaddr() // marker method
+}
+
+type SockaddrInet4 struct {
+	Port int
+	Addr [4]byte
+}
+
+func (*SockaddrInet4) sockaddr() {}
+
+type SockaddrInet6 struct {
+	Port   int
+	ZoneId uint32
+	Addr   [16]byte
+}
+
+func (*SockaddrInet6) sockaddr() {}
+
+func Socket(domain, typ, proto int) (fd int, err error) {
+	return -1, ENOSYS
+}
+
+func Bind(fd int, sa Sockaddr) (err error) {
+	return ENOSYS
+}
+
+func Connect(fd int, sa Sockaddr) (err error) {
+	return ENOSYS
+}
+
+func Listen(fd int, backlog int) (err error) {
+	return ENOSYS
+}
+
+func Getsockname(fd int) (sa Sockaddr, err error) {
+	return nil, ENOSYS
+}
+
+func Getpeername(fd int) (sa Sockaddr, err error) {
+	return nil, ENOSYS
+}
+
+func GetsockoptInt(fd, level, opt int) (value int, err error) {
+	return 0, ENOSYS
+}
+
+func SetsockoptInt(fd, level, opt int, value int) (err error) {
+	return ENOSYS
+}
+
+func Sendto(fd int, p []byte, flags int, to Sockaddr) (err error) {
+	return ENOSYS
+}
+
+// Accept accepts a connection on a preopened socket. The address of the peer
+// is not known in WASI, so the returned address is always nil.
+func Accept(fd int) (nfd int, sa Sockaddr, err error) {
+	var newfd uint32
+	if errno := sock_accept(uint32(fd), 0, &newfd); errno != 0 {
+		return -1, nil, Errno(errno)
+	}
+	return int(newfd), nil, nil
+}
+
+// Recvfrom receives data from a socket. The address of the sender is not known
+// in WASI, so the returned address is always nil.
+func Recvfrom(fd int, p []byte, flags int) (n int, from Sockaddr, err error) {
+	buf, count := splitSlice(p)
+	iov := __wasi_iovec_t{buf: buf, bufLen: count}
+	var nread uint32
+	var roflags uint16
+	if errno := sock_recv(uint32(fd), &iov, 1, uint16(flags), &nread, &roflags); errno != 0 {
+		return 0, nil, Errno(errno)
+	}
+	return int(nread), nil, nil
+}
+
+// SendmsgN sends data over a connected socket. Sending to a given address or
+// sending ancillary data is not supported.
+func SendmsgN(fd int, p, oob []byte, to Sockaddr, flags int) (n int, err error) {
+	if to != nil || len(oob) != 0 {
+		return 0, ENOSYS
+	}
+	buf, count := splitSlice(p)
+	iov := __wasi_iovec_t{buf: buf, bufLen: count}
+	var nwritten uint32
+	if errno := sock_send(uint32(fd), &iov, 1, 0, &nwritten); errno != 0 {
+		return 0, Errno(errno)
+	}
+	return int(nwritten), nil
+}
+
+func Shutdown(fd int, how int) (err error) {
+	if errno := sock_shutdown(uint32(fd), uint8(how)); errno != 0 {
+		return Errno(errno)
+	}
+	return nil
+}
+
+func SetNonblock(fd int, nonblocking bool) (err error) {
+	var stat __wasi_fdstat_t
+	if errno := fd_fdstat_get(uint32(fd), &stat); errno != 0 {
+		return Errno(errno)
+	}
+	flags := stat.flags &^ __WASI_FDFLAGS_NONBLOCK
+	if nonblocking {
+		flags |= __WASI_FDFLAGS_NONBLOCK
+	}
+	if errno := fd_fdstat_set_flags(uint32(fd), flags); errno != 0 {
+		return Errno(errno)
+	}
+	return nil
+}
+
+// CloseOnExec does nothing: WASI cannot start other processes.
+func CloseOnExec(fd int) {}
+
+const __WASI_FDFLAGS_NONBLOCK = 4
+
+// https://github.com/WebAssembly/WASI/blob/main/phases/snapshot/docs.md#-iovec-record
+type __wasi_iovec_t struct {
+	buf    *byte
+	bufLen uintptr
+}
+
+// https://github.com/WebAssembly/WASI/blob/main/phases/snapshot/docs.md#-fdstat-record
+type __wasi_fdstat_t struct {
+	filetype         uint8
+	flags            uint16
+	rightsBase       uint64
+	rightsInheriting uint64
+}
+
+//go:wasm-module wasi_snapshot_preview1
+//export sock_accept
+func sock_accept(fd uint32, flags uint16, newfd *uint32) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export sock_recv
+func sock_recv(fd uint32, riData *__wasi_iovec_t, riDataLen uint32, riFlags uint16, roDataLen *uint32, roFlags *uint16) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export sock_send
+func sock_send(fd uint32, siData *__wasi_iovec_t, siDataLen uint32, siFlags uint16, soDataLen *uint32) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export sock_shutdown
+func sock_shutdown(fd uint32, how uint8) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export fd_fdstat_get
+func fd_fdstat_get(fd uint32, stat *__wasi_fdstat_t) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export fd_fdstat_set_flags
+func fd_fdstat_set_flags(fd uint32, flags uint16) (errno uint16)
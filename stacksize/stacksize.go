@@ -71,12 +71,19 @@ func (n *CallNode) String() string {
 
 // CallGraph parses the ELF file and reads DWARF call frame information to
 // determine frame sizes for each function, as far as that's possible. Because
-// at this point it is not possible to determine indirect calls, a list of
-// indirect function calling functions needs to be supplied separately.
+// at this point it is not possible to determine indirect calls, the functions
+// that call a function pointer need to be supplied separately: indirectCalls
+// maps the name of each such function to the names of all functions it may
+// call indirectly. If this list is nil, the possible callees are not known.
+//
+// Some stack sizes may be known in advance, for example because they have
+// been annotated with //go:stacksize. They are passed in knownStackSizes (by
+// function name) and are used as the stack size of the function including all
+// the functions it calls.
 //
 // This function does not attempt to determine the stack size for functions.
 // This is done by calling StackSize on a function in the call graph.
-func CallGraph(f *elf.File, callsIndirectFunction []string) (map[string][]*CallNode, error) {
+func CallGraph(f *elf.File, indirectCalls map[string][]string, knownStackSizes map[string]uint64) (map[string][]*CallNode, error) {
 	// Sanity check that there is exactly one symbol table.
 	// Multiple symbol tables are possible, but aren't yet supported below.
 	numSymbolTables := 0
@@ -232,12 +239,32 @@ func CallGraph(f *elf.File, callsIndirectFunction []string) (map[string][]*CallN
 		}
 	}
 
-	// Mark functions that do indirect calls (which cannot be determined
-	// directly from ELF/DWARF information).
-	for _, name := range callsIndirectFunction {
+	// Add indirect calls (which cannot be determined directly from ELF/DWARF
+	// information) to the call graph, or mark the calling function if the
+	// callees are not known.
+	for name, callees := range indirectCalls {
+		for _, fn := range symbolNames[name] {
+			if callees == nil {
+				fn.stackSizeType = IndirectCall
+				fn.missingFrameInfo = fn
+				continue
+			}
+			for _, callee := range callees {
+				// Functions that are not in the symbol table have been
+				// removed by the linker, so can't be called.
+				fn.Children = append(fn.Children, symbolNames[callee]...)
+			}
+		}
+	}
+
+	// Use stack sizes that are known in advance. These also stop the
+	// recursion in determineStackSize, so they may be used to limit the stack
+	// size of recursive functions.
+	for name, size := range knownStackSizes {
 		for _, fn := range symbolNames[name] {
-			fn.stackSizeType = IndirectCall
-			fn.missingFrameInfo = fn
+			fn.stackSize = size
+			fn.stackSizeType = Bounded
+			fn.missingFrameInfo = nil
 		}
 	}
 
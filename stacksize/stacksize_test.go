@@ -0,0 +1,91 @@
+package stacksize_test
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/builder"
+	"github.com/tinygo-org/tinygo/stacksize"
+)
+
+// Test the call graph and stack sizes of testdata/callgraph.s, with and
+// without information about indirect calls and known stack sizes.
+func TestCallGraph(t *testing.T) {
+	t.Parallel()
+
+	object := filepath.Join(t.TempDir(), "callgraph.o")
+	err := builder.RunTool("clang", "--target=thumbv7m-unknown-unknown-eabi", "-c", "-o", object, filepath.Join("testdata", "callgraph.s"))
+	if err != nil {
+		t.Fatal("could not assemble testdata/callgraph.s:", err)
+	}
+
+	type result struct {
+		stackSize uint64
+		sizeType  stacksize.SizeType
+		missing   string // function responsible for an unknown stack size
+	}
+
+	for _, tc := range []struct {
+		name            string
+		indirectCalls   map[string][]string
+		knownStackSizes map[string]uint64
+		results         map[string]result
+	}{
+		{
+			name: "no-info",
+			results: map[string]result{
+				"leaf":         {0, stacksize.Bounded, ""},
+				"bigLeaf":      {72, stacksize.Bounded, ""},
+				"callsPointer": {8, stacksize.Bounded, ""},
+				"recursive":    {0, stacksize.Recursive, "recursive"},
+				"main":         {0, stacksize.Recursive, "recursive"},
+			},
+		},
+		{
+			name: "indirect-calls",
+			indirectCalls: map[string][]string{
+				// Functions that are not in the executable are ignored.
+				"callsPointer": {"leaf", "bigLeaf", "removedByLinker"},
+				"callsUnknown": nil,
+			},
+			knownStackSizes: map[string]uint64{
+				"recursive": 100,
+			},
+			results: map[string]result{
+				"callsPointer": {80, stacksize.Bounded, ""},
+				"callsUnknown": {0, stacksize.IndirectCall, "callsUnknown"},
+				"recursive":    {100, stacksize.Bounded, ""},
+				"main":         {108, stacksize.Bounded, ""},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := elf.Open(object)
+			if err != nil {
+				t.Fatal("could not open ELF file:", err)
+			}
+			defer f.Close()
+			functions, err := stacksize.CallGraph(f, tc.indirectCalls, tc.knownStackSizes)
+			if err != nil {
+				t.Fatal("could not determine call graph:", err)
+			}
+			for name, expected := range tc.results {
+				if len(functions[name]) != 1 {
+					t.Errorf("expected one function named %s, got %d", name, len(functions[name]))
+					continue
+				}
+				size, sizeType, missing := functions[name][0].StackSize()
+				missingName := ""
+				if missing != nil {
+					missingName = missing.String()
+				}
+				if sizeType != expected.sizeType || missingName != expected.missing || (sizeType == stacksize.Bounded && size != expected.stackSize) {
+					t.Errorf("%s: expected stack size %d (%s, missing %q), got %d (%s, missing %q)", name, expected.stackSize, expected.sizeType, expected.missing, size, sizeType, missingName)
+				}
+			}
+		})
+	}
+}
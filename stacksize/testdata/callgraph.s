@@ -0,0 +1,103 @@
+@ Call graph used by stacksize_test.go, which assembles it with the clang that
+@ comes with TinyGo.
+
+	.syntax unified
+	.thumb
+	.cfi_sections .debug_frame
+	.text
+
+@ Frames at address 0 are ignored (see parseFrames), so start with padding.
+	nop
+	nop
+
+@ main calls callsPointer and recursive.
+	.globl main
+	.type main,%function
+	.thumb_func
+main:
+	.cfi_startproc
+	push {r7, lr}
+	.cfi_def_cfa_offset 8
+	.cfi_offset lr, -4
+	.cfi_offset r7, -8
+	bl callsPointer
+	bl recursive
+	pop {r7, pc}
+	.cfi_endproc
+	.size main, .-main
+
+@ callsPointer calls a function pointer, which may be leaf or bigLeaf.
+	.globl callsPointer
+	.type callsPointer,%function
+	.thumb_func
+callsPointer:
+	.cfi_startproc
+	push {r7, lr}
+	.cfi_def_cfa_offset 8
+	.cfi_offset lr, -4
+	.cfi_offset r7, -8
+	blx r0
+	pop {r7, pc}
+	.cfi_endproc
+	.size callsPointer, .-callsPointer
+
+@ callsUnknown calls a function pointer of which the callees are not known.
+	.globl callsUnknown
+	.type callsUnknown,%function
+	.thumb_func
+callsUnknown:
+	.cfi_startproc
+	push {r7, lr}
+	.cfi_def_cfa_offset 8
+	.cfi_offset lr, -4
+	.cfi_offset r7, -8
+	blx r0
+	pop {r7, pc}
+	.cfi_endproc
+	.size callsUnknown, .-callsUnknown
+
+@ recursive calls itself.
+	.globl recursive
+	.type recursive,%function
+	.thumb_func
+recursive:
+	.cfi_startproc
+	push {r7, lr}
+	.cfi_def_cfa_offset 8
+	.cfi_offset lr, -4
+	.cfi_offset r7, -8
+	subs r0, #1
+	beq 1f
+	bl recursive
+1:
+	pop {r7, pc}
+	.cfi_endproc
+	.size recursive, .-recursive
+
+@ leaf doesn't use the stack.
+	.globl leaf
+	.type leaf,%function
+	.thumb_func
+leaf:
+	.cfi_startproc
+	bx lr
+	.cfi_endproc
+	.size leaf, .-leaf
+
+@ bigLeaf uses 72 bytes of stack.
+	.globl bigLeaf
+	.type bigLeaf,%function
+	.thumb_func
+bigLeaf:
+	.cfi_startproc
+	push {r7, lr}
+	.cfi_def_cfa_offset 8
+	.cfi_offset lr, -4
+	.cfi_offset r7, -8
+	sub sp, #64
+	.cfi_def_cfa_offset 72
+	add sp, #64
+	.cfi_def_cfa_offset 8
+	pop {r7, pc}
+	.cfi_endproc
+	.size bigLeaf, .-bigLeaf
@@ -0,0 +1,38 @@
+package main
+
+// This test is only run on the host, where stack traces can be symbolized
+// using the PC table.
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+func main() {
+	pc, file, line, ok := runtime.Caller(0)
+	println("Caller(0):", filepath.Base(file), line, ok)
+	println("FuncForPC:", runtime.FuncForPC(pc).Name())
+	a()
+}
+
+//go:noinline
+func a() {
+	b()
+}
+
+//go:noinline
+func b() {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(1, pcs)
+	println("Callers:", n > 3)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		println("frame:", frame.Function, filepath.Base(frame.File), frame.Line)
+		if !more || frame.Function == "main.main" {
+			break
+		}
+	}
+	_, file, line, _ := runtime.Caller(1)
+	println("Caller(1):", filepath.Base(file), line)
+}
@@ -0,0 +1,125 @@
+package main
+
+// Test starting processes with os/exec. The test runs its own binary as the
+// child process, so it doesn't depend on any other programs being installed.
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	if mode := os.Getenv("EXEC_TEST_CHILD"); mode != "" {
+		child(mode)
+		return
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		println("could not find executable:", err.Error())
+		return
+	}
+	command := func(mode string, args ...string) *exec.Cmd {
+		cmd := exec.Command(self, args...)
+		cmd.Env = append(os.Environ(), "EXEC_TEST_CHILD="+mode)
+		return cmd
+	}
+
+	// Arguments and standard output.
+	out, err := command("args", "foo", "bar").Output()
+	println("output:", strings.TrimSpace(string(out)), err == nil)
+
+	// Exit status and standard error.
+	_, err = command("fail").Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		println("exit code:", exitErr.ExitCode(), err.Error())
+		println("stderr:", strings.TrimSpace(string(exitErr.Stderr)))
+	} else {
+		println("unexpected error:", err)
+	}
+
+	// Standard input from a reader.
+	cmd := command("upper")
+	cmd.Stdin = strings.NewReader("hello")
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	err = cmd.Run()
+	println("stdin:", buf.String(), err == nil)
+
+	// Pipes.
+	cmd = command("upper")
+	stdin, _ := cmd.StdinPipe()
+	stdout, _ := cmd.StdoutPipe()
+	if err := cmd.Start(); err != nil {
+		println("could not start:", err.Error())
+		return
+	}
+	io.WriteString(stdin, "piped")
+	stdin.Close()
+	data, _ := ioutil.ReadAll(stdout)
+	err = cmd.Wait()
+	println("pipes:", string(data), err == nil, cmd.ProcessState.Success())
+
+	// Working directory and combined output.
+	cmd = command("pwd")
+	cmd.Dir = "/"
+	out, err = cmd.CombinedOutput()
+	println("dir:", string(out), err == nil)
+
+	// Signals.
+	cmd = command("wait")
+	stdin, _ = cmd.StdinPipe()
+	cmd.Start()
+	err = cmd.Process.Signal(syscall.SIGTERM)
+	println("signal sent:", err == nil)
+	err = cmd.Wait()
+	println("signal:", err.Error())
+	println("signal after exit:", cmd.Process.Signal(syscall.SIGTERM) == os.ErrProcessDone)
+
+	// Files opened by the parent are not inherited.
+	f, err := os.Open(self)
+	if err != nil {
+		println("could not open executable:", err.Error())
+		return
+	}
+	out, err = command("fd", strconv.Itoa(int(f.Fd()))).Output()
+	println("inherited file:", string(out), err == nil)
+	f.Close()
+
+	// Nonexistent commands.
+	_, err = exec.Command("tinygo-nonexistent-command").Output()
+	println("not found:", err != nil)
+}
+
+func child(mode string) {
+	switch mode {
+	case "args":
+		os.Stdout.WriteString(strings.Join(os.Args[1:], " ") + "\n")
+	case "fail":
+		os.Stderr.WriteString("something went wrong\n")
+		os.Exit(3)
+	case "upper":
+		data, _ := ioutil.ReadAll(os.Stdin)
+		os.Stdout.WriteString(strings.ToUpper(string(data)))
+	case "pwd":
+		wd, _ := os.Getwd()
+		os.Stdout.WriteString(wd)
+	case "wait":
+		// Wait until stdin is closed, or until killed.
+		ioutil.ReadAll(os.Stdin)
+	case "fd":
+		fd, _ := strconv.Atoi(os.Args[1])
+		var stat syscall.Stat_t
+		if syscall.Fstat(fd, &stat) == nil {
+			os.Stdout.WriteString("open")
+		} else {
+			os.Stdout.WriteString("closed")
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+// Test that the GC scans goroutine stacks. The only references to the linked
+// lists below are stored in local variables while the GC runs, so they must
+// not be freed.
+
+import "runtime"
+
+type node struct {
+	next  *node
+	value int
+}
+
+func main() {
+	done := make(chan int)
+	go func() {
+		done <- checkList("goroutine")
+	}()
+	println("goroutine sum:", <-done)
+	println("main sum:", checkList("main"))
+}
+
+//go:noinline
+func checkList(name string) int {
+	runtime.GC()
+	list := makeList(100)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	println(name, "list collected:", after.Frees-before.Frees >= 100)
+
+	// Reuse freed memory, which would overwrite the list if it was freed
+	// after all.
+	makeList(200)
+	return sumList(list)
+}
+
+//go:noinline
+func makeList(n int) *node {
+	var list *node
+	for i := 0; i < n; i++ {
+		list = &node{next: list, value: i}
+	}
+	return list
+}
+
+//go:noinline
+func sumList(list *node) int {
+	sum := 0
+	for n := list; n != nil; n = n.next {
+		sum += n.value
+	}
+	return sum
+}
@@ -0,0 +1,50 @@
+package main
+
+// This program is run under GDB by TestGDBGoroutines, which stops at
+// breakpoint() and checks the output of `info goroutines`.
+
+import (
+	"sync"
+	"time"
+)
+
+var mu sync.Mutex
+
+func main() {
+	ch := make(chan int)
+	done := make(chan struct{})
+
+	// Blocked on a channel receive.
+	go func() {
+		<-ch
+		done <- struct{}{}
+	}()
+
+	// Blocked on a mutex.
+	mu.Lock()
+	go func() {
+		mu.Lock()
+		mu.Unlock()
+		done <- struct{}{}
+	}()
+
+	// Sleeping.
+	go func() {
+		time.Sleep(time.Hour)
+	}()
+
+	// Let all goroutines reach their blocking point.
+	time.Sleep(10 * time.Millisecond)
+	breakpoint()
+
+	ch <- 1
+	mu.Unlock()
+	<-done
+	<-done
+	println("done")
+}
+
+//go:noinline
+func breakpoint() {
+	println("breakpoint")
+}
@@ -0,0 +1,114 @@
+package main
+
+// Test generic functions and types, introduced in Go 1.18.
+
+type Number interface {
+	int | int8 | uint16 | float32 | float64
+}
+
+func Sum[T Number](values ...T) T {
+	var sum T
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func Map[T, U any](values []T, f func(T) U) []U {
+	result := make([]U, len(values))
+	for i, v := range values {
+		result[i] = f(v)
+	}
+	return result
+}
+
+// Ring is a simple fixed-size ring buffer, of the kind that is often used in
+// drivers.
+type Ring[T any] struct {
+	buf  [4]T
+	head int
+	size int
+}
+
+func (r *Ring[T]) Put(v T) bool {
+	if r.size == len(r.buf) {
+		return false
+	}
+	r.buf[(r.head+r.size)%len(r.buf)] = v
+	r.size++
+	return true
+}
+
+func (r *Ring[T]) Get() (T, bool) {
+	var zero T
+	if r.size == 0 {
+		return zero, false
+	}
+	v := r.buf[r.head]
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return v, true
+}
+
+func (r *Ring[T]) Len() int {
+	return r.size
+}
+
+type Lener interface {
+	Len() int
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func (p Pair[K, V]) String() string {
+	return "pair"
+}
+
+func main() {
+	println("sum int:", Sum(1, 2, 3))
+	println("sum int8:", Sum[int8](100, 27))
+	println("sum uint16:", Sum[uint16](60000, 5535))
+	println("sum float64:", int(Sum(1.5, 2.25)*4))
+
+	lengths := Map([]string{"a", "bb", "ccc"}, func(s string) int {
+		return len(s)
+	})
+	println("map:", len(lengths), lengths[0], lengths[1], lengths[2])
+
+	var ints Ring[int]
+	for i := 0; i < 5; i++ {
+		println("put:", i, ints.Put(i))
+	}
+	v, ok := ints.Get()
+	println("get:", v, ok)
+
+	var strings Ring[string]
+	strings.Put("hello")
+	s, ok := strings.Get()
+	println("get:", s, ok)
+	s, ok = strings.Get()
+	println("get:", s, ok)
+
+	// Instantiated types must have their own type code and method set.
+	var itf interface{} = &ints
+	if l, ok := itf.(Lener); ok {
+		println("len int ring:", l.Len())
+	}
+	itf = &strings
+	if l, ok := itf.(Lener); ok {
+		println("len string ring:", l.Len())
+	}
+	_, isIntRing := itf.(*Ring[int])
+	println("string ring is int ring:", isIntRing)
+
+	var p interface{} = Pair[string, int]{"one", 1}
+	switch p := p.(type) {
+	case Pair[int, string]:
+		println("wrong pair type")
+	case Pair[string, int]:
+		println("pair:", p.Key, p.Value, p.String())
+	}
+}
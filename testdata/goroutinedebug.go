@@ -0,0 +1,106 @@
+package main
+
+// Test goroutine debugging with -tags=goroutinedebug. The goroutine lists are
+// printed by a child process (this same binary) so that the deadlock can be
+// tested too, and addresses are replaced as they differ between runs.
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	if mode := os.Getenv("GOROUTINEDEBUG_TEST_CHILD"); mode != "" {
+		child(mode)
+		return
+	}
+
+	// NumGoroutine counts all goroutines that haven't exited.
+	println("goroutines at start:", runtime.NumGoroutine())
+	ch := make(chan int)
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			<-ch
+			done <- struct{}{}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	println("goroutines while blocked:", runtime.NumGoroutine())
+	ch <- 1
+	ch <- 2
+	<-done
+	<-done
+	time.Sleep(time.Millisecond)
+	println("goroutines after exit:", runtime.NumGoroutine())
+
+	// PrintGoroutines lists every goroutine with what it is waiting for.
+	runChild("print")
+
+	// When all goroutines are blocked, they are printed before the deadlock
+	// panic.
+	runChild("deadlock")
+}
+
+var addressRegexp = regexp.MustCompile(`0x[0-9a-f]+`)
+
+func runChild(mode string) {
+	self, err := os.Executable()
+	if err != nil {
+		println("could not find executable:", err.Error())
+		return
+	}
+	cmd := exec.Command(self)
+	cmd.Env = append(os.Environ(), "GOROUTINEDEBUG_TEST_CHILD="+mode)
+	out, err := cmd.CombinedOutput()
+	println(mode + ":")
+	for _, line := range strings.Split(string(out), "\n") {
+		// Only print the goroutine lines themselves, not the call stacks
+		// which contain file paths and line numbers.
+		if i := strings.Index(line, ", backtrace:"); i >= 0 {
+			line = line[:i]
+		}
+		if strings.HasPrefix(line, "all goroutines") || strings.HasPrefix(line, "goroutine ") || strings.HasPrefix(line, "panic:") {
+			println(" ", addressRegexp.ReplaceAllString(line, "0x?"))
+		}
+	}
+	println("  exited with error:", err != nil)
+}
+
+var mu sync.Mutex
+
+func child(mode string) {
+	switch mode {
+	case "print":
+		ch := make(chan int)
+		go func() {
+			<-ch
+		}()
+		mu.Lock()
+		go func() {
+			mu.Lock()
+		}()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			wg.Wait()
+		}()
+		go func() {
+			time.Sleep(time.Hour)
+		}()
+		time.Sleep(time.Millisecond)
+		runtime.PrintGoroutines()
+	case "deadlock":
+		mu.Lock()
+		go func() {
+			mu.Lock()
+		}()
+		ch := make(chan int)
+		<-ch
+	}
+}
@@ -0,0 +1,11 @@
+#include <stdio.h>
+#include "libtest.h"
+
+int main(void) {
+	tinygo_init();
+	printf("add: %d\n", (int)add(2, 3));
+	printf("next: %d\n", (int)next());
+	printf("next: %d\n", (int)next());
+	printf("allocate: %d\n", (int)allocate(100));
+	return 0;
+}
@@ -0,0 +1,43 @@
+package main
+
+// Library used by TestLibrary, built with -buildmode=c-archive and called from
+// main.c.
+
+import "runtime"
+
+var counter int32
+
+func init() {
+	counter = 10
+}
+
+//export add
+func add(a, b int32) int32 {
+	return a + b
+}
+
+//export next
+func next() int32 {
+	counter++
+	return counter
+}
+
+// allocate allocates a number of objects that are only referenced from the
+// stack while the GC runs, and returns their sum.
+//export allocate
+func allocate(n int32) int32 {
+	values := make([]*int32, n)
+	for i := range values {
+		value := int32(i)
+		values[i] = &value
+	}
+	runtime.GC()
+	sum := int32(0)
+	for _, value := range values {
+		sum += *value
+	}
+	return sum
+}
+
+func main() {
+}
@@ -0,0 +1,31 @@
+package main
+
+import "runtime"
+
+var sinks [10][]byte
+
+func main() {
+	var before, after, collected runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 10; i++ {
+		sinks[i] = make([]byte, 100)
+	}
+	runtime.ReadMemStats(&after)
+	println("mallocs:", after.Mallocs-before.Mallocs >= 10)
+	println("total alloc:", after.TotalAlloc-before.TotalAlloc >= 1000)
+	println("heap alloc:", after.HeapAlloc >= 100, after.HeapAlloc <= after.HeapSys)
+	println("heap objects:", after.HeapObjects == after.Mallocs-after.Frees)
+
+	sinks = [10][]byte{}
+	runtime.GC()
+	runtime.ReadMemStats(&collected)
+	if collected.NumGC > after.NumGC {
+		println("freed:", collected.Frees > after.Frees)
+		println("last GC:", collected.LastGC != 0)
+	} else {
+		// The leaking GC never frees memory.
+		println("freed:", collected.Frees == 0 && collected.HeapAlloc >= after.HeapAlloc)
+		println("last GC:", collected.LastGC == 0)
+	}
+	println("pause total:", collected.PauseTotalNs >= after.PauseTotalNs)
+}
@@ -0,0 +1,109 @@
+package main
+
+// Test the net package. On Linux this uses real sockets (unless built with
+// -tags=netloopback), elsewhere it uses the in-memory loopback network.
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		println("listen:", err.Error())
+		return
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	println("listening on:", addr.IP.String(), addr.Port != 0)
+	port := strconv.Itoa(addr.Port)
+
+	done := make(chan struct{})
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			println("accept:", err.Error())
+			return
+		}
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		println("server received:", string(buf[:n]), err == nil)
+		conn.Write([]byte("pong"))
+		conn.Close()
+		close(done)
+	}()
+
+	conn, err := net.Dial("tcp", "localhost:"+port)
+	if err != nil {
+		println("dial:", err.Error())
+		return
+	}
+	println("remote address:", conn.RemoteAddr().String() == ln.Addr().String())
+	conn.Write([]byte("ping"))
+	data, err := io.ReadAll(conn)
+	println("client received:", string(data), err == nil)
+	<-done
+
+	// Read deadlines.
+	conn2, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		println("dial:", err.Error())
+		return
+	}
+	conn2.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn2.Read(make([]byte, 1))
+	if err, ok := err.(net.Error); ok {
+		println("read timeout:", err.Timeout())
+	}
+	conn2.Close()
+
+	// Dial timeouts and deadlines.
+	conn3, err := net.DialTimeout("tcp", "127.0.0.1:"+port, time.Second)
+	println("dial with timeout:", err == nil)
+	if err == nil {
+		conn3.Close()
+	}
+	dialer := net.Dialer{Deadline: time.Now().Add(-time.Second)}
+	_, err = dialer.Dial("tcp", "127.0.0.1:"+port)
+	if err, ok := err.(net.Error); ok {
+		println("dial deadline exceeded:", err.Timeout())
+	}
+
+	ln.Close()
+	_, err = net.Dial("tcp", "127.0.0.1:"+port)
+	println("dial closed port:", err != nil)
+
+	// UDP.
+	udpAddr, err := net.ResolveUDPAddr("udp", "127.0.0.1:5353")
+	println("resolve UDP:", udpAddr.String(), err == nil)
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		println("listen packet:", err.Error())
+		return
+	}
+	client, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		println("dial UDP:", err.Error())
+		return
+	}
+	client.Write([]byte("hello"))
+	buf := make([]byte, 64)
+	n, from, err := pc.ReadFrom(buf)
+	println("packet received:", string(buf[:n]), from.String() == client.LocalAddr().String(), err == nil)
+	pc.WriteTo([]byte("world"), from)
+	n, err = client.Read(buf)
+	println("reply received:", string(buf[:n]), err == nil)
+	client.Close()
+	pc.Close()
+
+	// Host name lookups. On Linux, localhost is found in /etc/hosts and the
+	// invalid name is rejected before any name server is asked.
+	ips, err := net.LookupIP("localhost")
+	println("lookup localhost:", len(ips) == 1 && ips[0].String() == "127.0.0.1", err == nil)
+	_, err = net.LookupIP("invalid host!")
+	if err, ok := err.(*net.DNSError); ok {
+		println("lookup invalid host:", err.Error())
+	}
+}
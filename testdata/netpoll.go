@@ -0,0 +1,54 @@
+package main
+
+// Test that goroutines waiting for a file descriptor don't block other
+// goroutines.
+
+import (
+	"os"
+	"time"
+)
+
+func main() {
+	r, w, err := os.Pipe()
+	if err != nil {
+		println("pipe:", err.Error())
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		println("reader: waiting")
+		buf := make([]byte, 16)
+		n, err := r.Read(buf)
+		println("reader: received", string(buf[:n]), err == nil)
+		close(done)
+	}()
+
+	// The reader is now blocked on the pipe, but this goroutine keeps running.
+	time.Sleep(10 * time.Millisecond)
+	println("main: still running")
+	w.Write([]byte("hello"))
+	<-done
+
+	// Multiple goroutines waiting at the same time, woken up in a different
+	// order than they started waiting.
+	r2, w2, err := os.Pipe()
+	if err != nil {
+		println("pipe:", err.Error())
+		return
+	}
+	results := make(chan string)
+	for _, f := range []*os.File{r, r2} {
+		f := f
+		go func() {
+			buf := make([]byte, 16)
+			n, _ := f.Read(buf)
+			results <- string(buf[:n])
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	w2.Write([]byte("second"))
+	println("received:", <-results)
+	w.Write([]byte("first"))
+	println("received:", <-results)
+}
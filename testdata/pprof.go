@@ -0,0 +1,61 @@
+package main
+
+// This test is only run on Linux, where both the CPU and heap profile are
+// supported.
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+var objects [][]byte
+
+//go:noinline
+func allocate() {
+	objects = append(objects, make([]byte, 100))
+}
+
+func main() {
+	runtime.MemProfileRate = 1
+
+	objects = make([][]byte, 0, 10)
+	for i := 0; i < 10; i++ {
+		allocate()
+	}
+
+	// Find the allocations made in allocate.
+	records := make([]runtime.MemProfileRecord, 1000)
+	n, ok := runtime.MemProfile(records, true)
+	println("MemProfile:", n > 0, ok)
+	for _, r := range records[:n] {
+		stack := r.Stack()
+		if len(stack) == 0 || runtime.FuncForPC(stack[0]-1).Name() != "main.allocate" {
+			continue
+		}
+		println("allocate:", r.AllocObjects, r.AllocBytes, r.InUseObjects(), r.InUseBytes())
+	}
+
+	var buf bytes.Buffer
+	err := pprof.Lookup("heap").WriteTo(&buf, 0)
+	println("heap profile:", buf.Len() > 0, err == nil)
+
+	// Other profiles exist, but can't be written.
+	for _, name := range []string{"goroutine", "block", "mutex", "threadcreate"} {
+		buf.Reset()
+		err = pprof.Lookup(name).WriteTo(&buf, 0)
+		println(name, "profile:", err == pprof.ErrUnimplemented)
+	}
+	println("unknown profile:", pprof.Lookup("unknown") == nil)
+
+	// Keep the CPU busy for a bit.
+	buf.Reset()
+	err = pprof.StartCPUProfile(&buf)
+	println("start CPU profile:", err == nil)
+	start := time.Now()
+	for time.Since(start) < 50*time.Millisecond {
+	}
+	pprof.StopCPUProfile()
+	println("CPU profile:", buf.Len() > 0)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var stop uint32
+
+// spin never pauses by itself, so it only stops running when it is preempted.
+func spin(done chan struct{}) {
+	for atomic.LoadUint32(&stop) == 0 {
+	}
+	done <- struct{}{}
+}
+
+func main() {
+	done := make(chan struct{})
+	go spin(done)
+	runtime.Gosched()
+	println("main is running again")
+	atomic.StoreUint32(&stop, 1)
+	<-done
+	println("spin stopped")
+
+	// Increment a counter from multiple goroutines that are preempted while
+	// holding the lock, or while waiting for it.
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < 20000; j++ {
+				mu.Lock()
+				counter++
+				mu.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	println("counter:", counter)
+}
@@ -0,0 +1,130 @@
+package main
+
+// This test is compiled with -reflect-methods, which keeps exported methods
+// around so they can be called using reflection.
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+type Counter struct {
+	n int
+}
+
+func (c Counter) Get() int {
+	return c.n
+}
+
+func (c *Counter) Add(delta int) {
+	c.n += delta
+}
+
+func (c Counter) Div(x int) (int, error) {
+	if x == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return c.n / x, nil
+}
+
+func (c Counter) Format(prefix string, values ...int) string {
+	s := prefix + strconv.Itoa(c.n)
+	for _, v := range values {
+		s += " " + strconv.Itoa(v)
+	}
+	return s
+}
+
+func (c Counter) unexported() {
+}
+
+type Stringer interface {
+	String() string
+}
+
+type Name string
+
+func (n Name) String() string {
+	return "name:" + string(n)
+}
+
+func (n Name) Describe(v interface{}) string {
+	return string(n) + " is " + reflect.TypeOf(v).Kind().String()
+}
+
+type Holder struct {
+	S Stringer
+}
+
+func main() {
+	println("methods:")
+	c := &Counter{n: 10}
+	showMethods(reflect.TypeOf(*c))
+	showMethods(reflect.TypeOf(c))
+	showMethods(reflect.TypeOf(Name("")))
+	showMethods(reflect.TypeOf(3))
+
+	println("\nsignatures:")
+	f := reflect.ValueOf(c).MethodByName("Format")
+	println("Format:", f.Kind().String(), f.Type().NumIn(), f.Type().NumOut(), f.Type().IsVariadic())
+	println("  in:", f.Type().In(0).Kind().String(), f.Type().In(1).Kind().String(), f.Type().In(1).Elem().Kind().String())
+	println("  out:", f.Type().Out(0).Kind().String())
+	m, ok := reflect.TypeOf(c).MethodByName("Div")
+	println("Div:", m.Name, m.Index, ok)
+	_, ok = reflect.TypeOf(c).MethodByName("unexported")
+	println("unexported:", ok)
+	println("missing:", reflect.ValueOf(c).MethodByName("Missing").IsValid())
+
+	println("\ncalls:")
+	v := reflect.ValueOf(c)
+	v.MethodByName("Add").Call([]reflect.Value{reflect.ValueOf(5)})
+	println("after Add:", c.n)
+	println("Get:", v.MethodByName("Get").Call(nil)[0].Int())
+	println("Get (by index):", v.Method(3).Call(nil)[0].Int())
+	println("Get (value receiver):", reflect.ValueOf(*c).MethodByName("Get").Call(nil)[0].Int())
+	results := v.MethodByName("Div").Call([]reflect.Value{reflect.ValueOf(4)})
+	println("Div:", results[0].Int(), results[1].IsNil())
+	results = v.MethodByName("Div").Call([]reflect.Value{reflect.ValueOf(0)})
+	println("Div by zero:", results[0].Int(), results[1].Interface().(error).Error())
+	println("Format:", f.Call([]reflect.Value{reflect.ValueOf("n=")})[0].String())
+	println("Format:", f.Call([]reflect.Value{reflect.ValueOf("n="), reflect.ValueOf(1), reflect.ValueOf(2)})[0].String())
+	println("Format:", f.CallSlice([]reflect.Value{reflect.ValueOf("n="), reflect.ValueOf([]int{3, 4})})[0].String())
+	println("Describe:", reflect.ValueOf(Name("x")).MethodByName("Describe").Call([]reflect.Value{reflect.ValueOf(1.5)})[0].String())
+
+	println("\ninterfaces:")
+	h := reflect.ValueOf(Holder{S: Name("bob")}).Field(0)
+	println("kind:", h.Kind().String(), h.NumMethod())
+	println("String:", h.Method(0).Call(nil)[0].String())
+	println("Describe:", h.MethodByName("Describe").IsValid())
+
+	println("\ndispatch:")
+	for _, call := range []string{"Get", "Add", "Get", "Unknown"} {
+		println(call+":", dispatch(c, call, 1))
+	}
+}
+
+func showMethods(t reflect.Type) {
+	println(t.Kind().String(), t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		println(" ", m.Index, m.Name)
+	}
+}
+
+// dispatch calls a method by name, the way a simple RPC server might do it.
+func dispatch(receiver interface{}, name string, arg int) string {
+	m := reflect.ValueOf(receiver).MethodByName(name)
+	if !m.IsValid() {
+		return "unknown method"
+	}
+	var args []reflect.Value
+	if m.Type().NumIn() == 1 {
+		args = append(args, reflect.ValueOf(arg))
+	}
+	results := m.Call(args)
+	if len(results) == 0 {
+		return "ok"
+	}
+	return strconv.Itoa(int(results[0].Int()))
+}
@@ -343,6 +343,12 @@ func main() {
 	println("\nv.Interface() method")
 	testInterfaceMethod()
 
+	println("\nmaps")
+	testMaps()
+
+	println("\nslices")
+	testSlices()
+
 	// Test reflect.DeepEqual.
 	var selfref1, selfref2 selfref
 	selfref1.x = &selfref1
@@ -436,7 +442,7 @@ func showValue(rv reflect.Value, indent string) {
 			showValue(rv.Elem(), indent+"  ")
 		}
 	case reflect.Map:
-		println(indent + "  map")
+		println(indent+"  map:", rt.Key().Kind().String(), rt.Elem().Kind().String(), rv.Len())
 		println(indent+"  nil:", rv.IsNil())
 	case reflect.Ptr:
 		println(indent+"  pointer:", rv.Pointer() != 0, rt.Elem().Kind().String())
@@ -544,6 +550,117 @@ func testInterfaceMethod() {
 	}
 }
 
+// Test map operations through reflect, for the various ways in which map keys
+// can be stored.
+func testMaps() {
+	// String keys.
+	m1 := map[string]int{"one": 1, "two": 2}
+	rv := reflect.ValueOf(m1)
+	println("string key:", rv.MapIndex(reflect.ValueOf("two")).Int())
+	println("missing key valid:", rv.MapIndex(reflect.ValueOf("three")).IsValid())
+	rv.SetMapIndex(reflect.ValueOf("three"), reflect.ValueOf(3))
+	rv.SetMapIndex(reflect.ValueOf("one"), reflect.Value{})
+	println("after update:", len(m1), m1["three"], m1["one"])
+
+	// Binary keys.
+	m2 := map[point]string{{1, 2}: "a", {3, 4}: "b"}
+	rv = reflect.ValueOf(m2)
+	println("binary key:", rv.MapIndex(reflect.ValueOf(point{3, 4})).String())
+	rv.SetMapIndex(reflect.ValueOf(point{5, 6}), reflect.ValueOf("c"))
+	println("after update:", len(m2), m2[point{5, 6}])
+
+	// Keys that are stored as an interface.
+	m3 := map[float64]myint{1.5: 3, 2.5: 5}
+	rv = reflect.ValueOf(m3)
+	println("float key:", rv.MapIndex(reflect.ValueOf(2.5)).Int())
+	rv.SetMapIndex(reflect.ValueOf(3.5), reflect.ValueOf(myint(7)))
+	println("after update:", len(m3), m3[3.5])
+	sum := 0.0
+	for _, key := range rv.MapKeys() {
+		sum += key.Float()
+	}
+	println("sum of keys:", int(sum*10))
+
+	// Interface keys and values.
+	m4 := map[interface{}]interface{}{"x": 1, 2: "y"}
+	rv = reflect.ValueOf(m4)
+	println("interface key:", rv.MapIndex(reflect.ValueOf(2)).Elem().String())
+	rv.SetMapIndex(reflect.ValueOf(true), reflect.ValueOf(3))
+	println("after update:", len(m4), m4[true].(int))
+
+	// Iterate over a map.
+	sumValues := int64(0)
+	it := reflect.ValueOf(map[myint]int8{1: 10, 2: 20, 3: 30}).MapRange()
+	for it.Next() {
+		if it.Key().Type() != reflect.TypeOf(myint(0)) {
+			println("unexpected key type")
+		}
+		sumValues += it.Key().Int() * it.Value().Int()
+	}
+	println("sum of values:", sumValues)
+
+	// Create a new map.
+	rv = reflect.MakeMap(reflect.TypeOf(map[string][]byte{}))
+	rv.SetMapIndex(reflect.ValueOf("foo"), reflect.ValueOf([]byte("bar")))
+	m5 := rv.Interface().(map[string][]byte)
+	println("new map:", len(m5), string(m5["foo"]))
+}
+
+// Test creating and modifying slices through reflect.
+func testSlices() {
+	// MakeSlice and Append.
+	rv := reflect.MakeSlice(reflect.TypeOf([]int16{}), 2, 3)
+	rv.Index(1).SetInt(5)
+	rv = reflect.Append(rv, reflect.ValueOf(int16(7)), reflect.ValueOf(int16(9)))
+	s1 := rv.Interface().([]int16)
+	println("append:", len(s1), s1[0], s1[1], s1[2], s1[3])
+
+	// Append to a slice of interfaces.
+	rv = reflect.Append(reflect.ValueOf([]interface{}{}), reflect.ValueOf("foo"), reflect.ValueOf(3))
+	s2 := rv.Interface().([]interface{})
+	println("append interface:", len(s2), s2[0].(string), s2[1].(int))
+
+	// Slicing.
+	rv = reflect.ValueOf([]string{"a", "b", "c", "d", "e"})
+	sub := rv.Slice(1, 3)
+	println("slice:", sub.Len(), sub.Cap(), sub.Index(0).String(), sub.Index(1).String())
+	sub = rv.Slice3(2, 3, 4)
+	println("slice3:", sub.Len(), sub.Cap(), sub.Index(0).String())
+	println("slice string:", reflect.ValueOf("hello world").Slice(6, 11).String())
+	arr := [4]uint32{1, 2, 3, 4}
+	sub = reflect.ValueOf(&arr).Elem().Slice(1, 3)
+	sub.Index(0).SetUint(20)
+	println("slice array:", sub.Type() == reflect.TypeOf([]uint32{}), sub.Len(), sub.Cap(), arr[1])
+
+	// SetLen and SetCap.
+	s3 := make([]byte, 2, 10)
+	rv = reflect.ValueOf(&s3).Elem()
+	rv.SetLen(5)
+	rv.SetCap(8)
+	println("setlen/setcap:", len(s3), cap(s3))
+
+	// Bytes and SetBytes.
+	rv.SetBytes([]byte("xyz"))
+	println("bytes:", string(rv.Bytes()), len(s3))
+
+	// Copy.
+	dst := make([]int, 3)
+	n := reflect.Copy(reflect.ValueOf(dst), reflect.ValueOf([]int{7, 8, 9, 10}))
+	println("copy:", n, dst[0], dst[1], dst[2])
+	buf := make([]byte, 8)
+	n = reflect.Copy(reflect.ValueOf(buf), reflect.ValueOf("copied"))
+	println("copy string:", n, string(buf[:n]))
+	var dstArr [2]int
+	n = reflect.Copy(reflect.ValueOf(&dstArr).Elem(), reflect.ValueOf([]int{4, 5, 6}))
+	println("copy array:", n, dstArr[0], dstArr[1])
+
+	// Zero values.
+	println("zero int:", reflect.Zero(reflect.TypeOf(0)).Int())
+	println("zero string:", reflect.Zero(reflect.TypeOf("")).String() == "")
+	println("zero slice:", reflect.Zero(reflect.TypeOf([]int{})).IsNil())
+	println("zero struct:", reflect.Zero(reflect.TypeOf(point{})).Field(1).Int())
+}
+
 var xorshift32State uint32 = 1
 
 func xorshift32(x uint32) uint32 {
@@ -99,6 +99,17 @@ func MakeGCStackSlots(mod llvm.Module) bool {
 	stackChainStartType := stackChainStart.Type().ElementType()
 	stackChainStart.SetInitializer(llvm.ConstNull(stackChainStartType))
 
+	// When runtime.Callers is used, each stack object ends with a pointer to
+	// the function name and source location, which is used to walk the stack.
+	// It is stored after the stack slots so that programs that don't use
+	// runtime.Callers don't pay for it.
+	i8ptrType := llvm.PointerType(ctx.Int8Type(), 0)
+	stringType := mod.GetTypeByName("runtime._string")
+	var funcInfoType llvm.Type
+	if !mod.NamedFunction("runtime.callers").IsNil() && !stringType.IsNil() {
+		funcInfoType = ctx.StructType([]llvm.Type{stringType, stringType, uintptrType}, false)
+	}
+
 	// Iterate until runtime.trackPointer has no uses left.
 	for use := trackPointer.FirstUse(); !use.IsNil(); use = trackPointer.FirstUse() {
 		// Pick the first use of runtime.trackPointer.
@@ -214,13 +225,25 @@ func MakeGCStackSlots(mod llvm.Module) bool {
 		for _, ptr := range pointers {
 			fields = append(fields, ptr.Type())
 		}
+		if !funcInfoType.IsNil() {
+			fields = append(fields, i8ptrType) // Function information.
+		}
 		stackObjectType := ctx.StructType(fields, false)
 
 		// Create the stack object at the function entry.
 		builder.SetInsertPointBefore(fn.EntryBasicBlock().FirstInstruction())
 		stackObject := builder.CreateAlloca(stackObjectType, "gc.stackobject")
 		initialStackObject := llvm.ConstNull(stackObjectType)
-		numSlots := (targetData.TypeAllocSize(stackObjectType) - uint64(targetData.PointerSize())*2) / uint64(targetData.ABITypeAlignment(uintptrType))
+		slotsEnd := targetData.TypeAllocSize(stackObjectType)
+		if !funcInfoType.IsNil() {
+			// The function information directly follows the stack slots.
+			funcInfoIndex := len(fields) - 1
+			slotsEnd = targetData.ElementOffset(stackObjectType, funcInfoIndex)
+			funcInfo := makeFuncInfo(mod, fn, funcInfoType)
+			funcInfo = llvm.ConstBitCast(funcInfo, i8ptrType)
+			initialStackObject = llvm.ConstInsertValue(initialStackObject, funcInfo, []uint32{uint32(funcInfoIndex)})
+		}
+		numSlots := (slotsEnd - uint64(targetData.PointerSize())*2) / uint64(targetData.ABITypeAlignment(uintptrType))
 		numSlotsValue := llvm.ConstInt(uintptrType, numSlots, false)
 		initialStackObject = llvm.ConstInsertValue(initialStackObject, numSlotsValue, []uint32{1})
 		builder.CreateStore(initialStackObject, stackObject)
@@ -440,6 +463,35 @@ func getPointerBitmap(targetData llvm.TargetData, typ llvm.Type, name string) *b
 	}
 }
 
+// makeFuncInfo creates a global with the function name and source location
+// of the given function, as read by runtime.callers on WebAssembly.
+func makeFuncInfo(mod llvm.Module, fn llvm.Value, funcInfoType llvm.Type) llvm.Value {
+	pos := getPosition(fn)
+	stringType := funcInfoType.StructElementTypes()[0]
+	uintptrType := funcInfoType.StructElementTypes()[2]
+	makeString := func(name, value string) llvm.Value {
+		buf := llvm.AddGlobal(mod, llvm.ArrayType(mod.Context().Int8Type(), len(value)), name)
+		buf.SetInitializer(mod.Context().ConstString(value, false))
+		buf.SetLinkage(llvm.PrivateLinkage)
+		buf.SetGlobalConstant(true)
+		buf.SetUnnamedAddr(true)
+		zero := llvm.ConstInt(mod.Context().Int32Type(), 0, false)
+		ptr := llvm.ConstInBoundsGEP(buf, []llvm.Value{zero, zero})
+		length := llvm.ConstInt(uintptrType, uint64(len(value)), false)
+		return llvm.ConstNamedStruct(stringType, []llvm.Value{ptr, length})
+	}
+	funcInfo := llvm.AddGlobal(mod, funcInfoType, fn.Name()+"$funcinfo")
+	funcInfo.SetInitializer(mod.Context().ConstStruct([]llvm.Value{
+		makeString(fn.Name()+"$funcinfo.name", fn.Name()),
+		makeString(fn.Name()+"$funcinfo.file", pos.Filename),
+		llvm.ConstInt(uintptrType, uint64(pos.Line), false),
+	}, false))
+	funcInfo.SetLinkage(llvm.PrivateLinkage)
+	funcInfo.SetGlobalConstant(true)
+	funcInfo.SetUnnamedAddr(true)
+	return funcInfo
+}
+
 // markParentFunctions traverses all parent function calls (recursively) and
 // adds them to the set of marked functions. It only considers function calls:
 // any other uses of such a function is ignored.
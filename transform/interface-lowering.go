@@ -248,6 +248,10 @@ func (p *lowerInterfacesPass) run() error {
 		p.defineInterfaceMethodFunc(fn, itf, signature)
 	}
 
+	// Mark the functions that may be called through a func value, now that
+	// interface methods are only called directly from the invoke thunks.
+	p.markFuncValues()
+
 	// Define all interface type assert functions.
 	for _, fn := range interfaceAssertFunctions {
 		methodsAttr := fn.GetStringAttributeAtIndex(-1, "tinygo-methods")
@@ -520,6 +524,31 @@ func (p *lowerInterfacesPass) defineInterfaceMethodFunc(fn llvm.Value, itf *inte
 	p.builder.CreateUnreachable()
 }
 
+// markFuncValues adds the "tinygo-funcvalue" attribute to every function that
+// is used in a func value. Func values store the function pointer as a raw
+// void() pointer (see createFuncValue in the compiler), so these are the
+// functions that are bitcast to that type. After interface lowering, calls
+// through a func value are the only indirect calls in Go code, so this is the
+// set of possible callees that the stack size analysis uses for them.
+func (p *lowerInterfacesPass) markFuncValues() {
+	for fn := p.mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		for _, use := range getUses(fn) {
+			if use.IsAConstantExpr().IsNil() || use.Opcode() != llvm.BitCast {
+				continue
+			}
+			ptrType := use.Type()
+			if ptrType.TypeKind() != llvm.PointerTypeKind || ptrType.ElementType().TypeKind() != llvm.FunctionTypeKind {
+				continue
+			}
+			fnType := ptrType.ElementType()
+			if fnType.ReturnType().TypeKind() == llvm.VoidTypeKind && fnType.ParamTypesCount() == 0 {
+				fn.AddFunctionAttr(p.ctx.CreateStringAttribute("tinygo-funcvalue", ""))
+				break
+			}
+		}
+	}
+}
+
 func (p *lowerInterfacesPass) getDIFile(file string) llvm.Metadata {
 	difile, ok := p.difiles[file]
 	if !ok {
@@ -80,6 +80,7 @@ type typeCodeAssignmentState struct {
 	// type codes that are not yet fully supported otherwise by the reflect
 	// package (or are simply unused in the compiled program).
 	fallbackIndex int
+	fallbackTypes map[string]int
 
 	// This is the length of an uintptr. Only used occasionally to know whether
 	// a given number can be encoded as a varint.
@@ -95,6 +96,16 @@ type typeCodeAssignmentState struct {
 	arrayTypesSidetable      []byte
 	needsArrayTypesSidetable bool
 
+	// Map of map types to their type code.
+	mapTypes               map[string]int
+	mapTypesSidetable      []byte
+	needsMapTypesSidetable bool
+
+	// Map of func types to their type code.
+	funcTypes               map[string]int
+	funcTypesSidetable      []byte
+	needsFuncTypesSidetable bool
+
 	// Map of struct types to their type code.
 	structTypes               map[string]int
 	structTypesSidetable      []byte
@@ -135,6 +146,7 @@ func LowerReflect(mod llvm.Module) {
 		typecode llvm.Value
 		name     string
 		numUses  int
+		num      uint64
 	}
 	var types []*typeInfo
 	for global := mod.FirstGlobal(); !global.IsNil(); global = llvm.NextGlobal(global) {
@@ -164,16 +176,21 @@ func LowerReflect(mod llvm.Module) {
 	uintptrType := mod.Context().IntType(llvm.NewTargetData(mod.DataLayout()).PointerSize() * 8)
 	state := typeCodeAssignmentState{
 		fallbackIndex:                    1,
+		fallbackTypes:                    make(map[string]int),
 		uintptrLen:                       llvm.NewTargetData(mod.DataLayout()).PointerSize() * 8,
 		namedBasicTypes:                  make(map[string]int),
 		namedNonBasicTypes:               make(map[string]int),
 		arrayTypes:                       make(map[string]int),
+		mapTypes:                         make(map[string]int),
+		funcTypes:                        make(map[string]int),
 		structTypes:                      make(map[string]int),
 		structNames:                      make(map[string]int),
 		needsNamedNonBasicTypesSidetable: len(getUses(mod.NamedGlobal("reflect.namedNonBasicTypesSidetable"))) != 0,
 		needsStructTypesSidetable:        len(getUses(mod.NamedGlobal("reflect.structTypesSidetable"))) != 0,
 		needsStructNamesSidetable:        len(getUses(mod.NamedGlobal("reflect.structNamesSidetable"))) != 0,
 		needsArrayTypesSidetable:         len(getUses(mod.NamedGlobal("reflect.arrayTypesSidetable"))) != 0,
+		needsMapTypesSidetable:           len(getUses(mod.NamedGlobal("reflect.mapTypesSidetable"))) != 0,
+		needsFuncTypesSidetable:          len(getUses(mod.NamedGlobal("reflect.funcTypesSidetable"))) != 0,
 	}
 	for _, t := range types {
 		num := state.getTypeCodeNum(t.typecode)
@@ -184,6 +201,7 @@ func LowerReflect(mod llvm.Module) {
 			// AVR).
 			panic("compiler: could not store type code number inside interface type code")
 		}
+		t.num = num.Uint64()
 
 		// Replace each use of the type code global with the constant type code.
 		for _, use := range getUses(t.typecode) {
@@ -218,6 +236,18 @@ func LowerReflect(mod llvm.Module) {
 		global.SetUnnamedAddr(true)
 		global.SetGlobalConstant(true)
 	}
+	if state.needsMapTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.mapTypesSidetable", state.mapTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsFuncTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.funcTypesSidetable", state.funcTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
 	if state.needsStructTypesSidetable {
 		global := replaceGlobalIntWithArray(mod, "reflect.structTypesSidetable", state.structTypesSidetable)
 		global.SetLinkage(llvm.InternalLinkage)
@@ -231,12 +261,81 @@ func LowerReflect(mod llvm.Module) {
 		global.SetGlobalConstant(true)
 	}
 
+	// Create the method sidetables, if the reflect package needs them. The
+	// methods sidetable is a sequence of {type code, number of methods,
+	// methods...} terminated by a zero type code, where each method is a
+	// {name length, name, signature type code, function index} tuple. The
+	// function index is an index into the function sidetable plus one, or zero
+	// for interface methods (which have no function).
+	// Method information is only present when compiling with -reflect-methods.
+	needsMethodsSidetable := len(getUses(mod.NamedGlobal("reflect.methodsSidetable"))) != 0
+	needsMethodFunctionsSidetable := len(getUses(mod.NamedGlobal("reflect.methodFunctionsSidetable"))) != 0
+	if needsMethodsSidetable || needsMethodFunctionsSidetable {
+		var buf []byte
+		var functions []llvm.Value
+		for _, t := range types {
+			methods := llvm.ConstExtractValue(t.typecode.Initializer(), []uint32{5})
+			if methods.IsNull() {
+				continue
+			}
+			methodsValue := methods.Operand(0).Initializer()
+			numMethods := methodsValue.Type().ArrayLength()
+			buf = append(buf, makeVarint(t.num)...)
+			buf = append(buf, makeVarint(uint64(numMethods))...)
+			for i := 0; i < numMethods; i++ {
+				method := llvm.ConstExtractValue(methodsValue, []uint32{uint32(i)})
+				name := getGlobalBytes(llvm.ConstExtractValue(method, []uint32{0}).Operand(0))
+				buf = append(buf, makeVarint(uint64(len(name)))...)
+				buf = append(buf, name...)
+				signatureNum := state.getTypeCodeNum(llvm.ConstExtractValue(method, []uint32{1}))
+				if signatureNum.BitLen() > state.uintptrLen || !signatureNum.IsUint64() {
+					// TODO: make this a regular error
+					panic("method signature has a type code that is too big")
+				}
+				buf = append(buf, makeVarint(signatureNum.Uint64())...)
+				wrapper := llvm.ConstExtractValue(method, []uint32{2})
+				if wrapper.IsNull() {
+					buf = append(buf, makeVarint(0)...)
+				} else {
+					functions = append(functions, wrapper)
+					buf = append(buf, makeVarint(uint64(len(functions)))...)
+				}
+			}
+		}
+		buf = append(buf, makeVarint(0)...)
+		if needsMethodsSidetable {
+			global := replaceGlobalIntWithArray(mod, "reflect.methodsSidetable", buf)
+			global.SetLinkage(llvm.InternalLinkage)
+			global.SetUnnamedAddr(true)
+			global.SetGlobalConstant(true)
+		}
+		if needsMethodFunctionsSidetable {
+			// This sidetable contains function pointers (as uintptr) and
+			// can't be created with replaceGlobalIntWithArray.
+			oldGlobal := mod.NamedGlobal("reflect.methodFunctionsSidetable")
+			value := llvm.ConstArray(uintptrType, functions)
+			global := llvm.AddGlobal(mod, value.Type(), "reflect.methodFunctionsSidetable.tmp")
+			global.SetInitializer(value)
+			gep := llvm.ConstGEP(global, []llvm.Value{
+				llvm.ConstInt(mod.Context().Int32Type(), 0, false),
+				llvm.ConstInt(mod.Context().Int32Type(), 0, false),
+			})
+			oldGlobal.ReplaceAllUsesWith(gep)
+			oldGlobal.EraseFromParentAsGlobal()
+			global.SetName("reflect.methodFunctionsSidetable")
+			global.SetLinkage(llvm.InternalLinkage)
+			global.SetUnnamedAddr(true)
+			global.SetGlobalConstant(true)
+		}
+	}
+
 	// Remove most objects created for interface and reflect lowering.
 	// They would normally be removed anyway in later passes, but not always.
 	// It also cleans up the IR for testing.
 	for _, typ := range types {
 		initializer := typ.typecode.Initializer()
 		references := llvm.ConstExtractValue(initializer, []uint32{0})
+		methods := llvm.ConstExtractValue(initializer, []uint32{5})
 		typ.typecode.SetInitializer(llvm.ConstNull(initializer.Type()))
 		if strings.HasPrefix(typ.name, "reflect/types.type:struct:") {
 			// Structs have a 'references' field that is not a typecode but
@@ -245,6 +344,19 @@ func LowerReflect(mod llvm.Module) {
 			// typecode objects cannot be erased.
 			structFields := references.Operand(0)
 			structFields.EraseFromParentAsGlobal()
+		} else if strings.HasPrefix(typ.name, "reflect/types.type:map:") {
+			// Same for maps, which reference a {key, elem} array.
+			mapFields := references.Operand(0)
+			mapFields.EraseFromParentAsGlobal()
+		} else if strings.HasPrefix(typ.name, "reflect/types.type:func:") {
+			// Same for funcs, which reference a {params..., results...} array.
+			funcFields := references.Operand(0)
+			funcFields.EraseFromParentAsGlobal()
+		}
+		if !methods.IsNull() {
+			// Remove the method table, so that the methods it references can
+			// be removed when they are not used otherwise.
+			methods.Operand(0).EraseFromParentAsGlobal()
 		}
 	}
 }
@@ -348,16 +460,37 @@ func (state *typeCodeAssignmentState) getNonBasicTypeCode(class string, typecode
 		// An array is basically a pair of (typecode, length) stored in a
 		// sidetable.
 		return big.NewInt(int64(state.getArrayTypeNum(typecode)))
+	case "map":
+		// A map is a pair of (key typecode, element typecode) stored in a
+		// sidetable.
+		return big.NewInt(int64(state.getMapTypeNum(typecode)))
+	case "func":
+		// A func is a list of parameter and result types stored in a
+		// sidetable.
+		return big.NewInt(int64(state.getFuncTypeNum(typecode)))
 	case "struct":
 		// More complicated type kind. The upper bits contain the index to the
 		// struct type in the struct types sidetable.
 		return big.NewInt(int64(state.getStructTypeNum(typecode)))
+	case "interface":
+		// The empty interface is given number zero, so that the reflect
+		// package can recognize it (and named types based on it). Other
+		// interfaces get a unique number like the types below.
+		if typecode.Name() == "reflect/types.type:interface:{}" {
+			return big.NewInt(0)
+		}
+		fallthrough
 	default:
 		// Type has not yet been implemented, so fall back by using a unique
 		// number.
-		num := big.NewInt(int64(state.fallbackIndex))
+		name := typecode.Name()
+		if num, ok := state.fallbackTypes[name]; ok {
+			return big.NewInt(int64(num))
+		}
+		num := state.fallbackIndex
+		state.fallbackTypes[name] = num
 		state.fallbackIndex++
-		return num
+		return big.NewInt(int64(num))
 	}
 }
 
@@ -429,6 +562,85 @@ func (state *typeCodeAssignmentState) getArrayTypeNum(typecode llvm.Value) int {
 	return index
 }
 
+// getMapTypeNum returns the map type number, which is an index into the
+// reflect.mapTypesSidetable or a unique number for this type if this table is
+// not used.
+func (state *typeCodeAssignmentState) getMapTypeNum(typecode llvm.Value) int {
+	name := typecode.Name()
+	if num, ok := state.mapTypes[name]; ok {
+		// This map type already has an entry in the sidetable. Don't store it
+		// twice.
+		return num
+	}
+
+	if !state.needsMapTypesSidetable {
+		// We don't need map sidetables, so we can just assign monotonically
+		// increasing numbers to each map type.
+		num := len(state.mapTypes)
+		state.mapTypes[name] = num
+		return num
+	}
+
+	// The map side table is a sequence of {key type, element type}.
+	mapFields := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0}).Operand(0).Initializer()
+	var buf []byte
+	for i := 0; i < 2; i++ {
+		typeNum := state.getTypeCodeNum(llvm.ConstExtractValue(mapFields, []uint32{uint32(i)}))
+		if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+			// TODO: make this a regular error
+			panic("map key or element type has a type code that is too big")
+		}
+		buf = append(buf, makeVarint(typeNum.Uint64())...)
+	}
+
+	index := len(state.mapTypesSidetable)
+	state.mapTypes[name] = index
+	state.mapTypesSidetable = append(state.mapTypesSidetable, buf...)
+	return index
+}
+
+// getFuncTypeNum returns the func type number, which is an index into the
+// reflect.funcTypesSidetable or a unique number for this type if this table is
+// not used.
+func (state *typeCodeAssignmentState) getFuncTypeNum(typecode llvm.Value) int {
+	name := typecode.Name()
+	if num, ok := state.funcTypes[name]; ok {
+		// This func type already has an entry in the sidetable. Don't store
+		// it twice.
+		return num
+	}
+
+	if !state.needsFuncTypesSidetable {
+		// We don't need func sidetables, so we can just assign monotonically
+		// increasing numbers to each func type.
+		num := len(state.funcTypes)
+		state.funcTypes[name] = num
+		return num
+	}
+
+	// The func side table starts with the number of parameters (shifted left
+	// by one, with the lowest bit indicating a variadic function) and the
+	// number of results, followed by the parameter and result types.
+	funcFields := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0}).Operand(0).Initializer()
+	numFields := funcFields.Type().ArrayLength()
+	length := llvm.ConstExtractValue(typecode.Initializer(), []uint32{1}).ZExtValue()
+	buf := makeVarint(length)
+	buf = append(buf, makeVarint(uint64(numFields)-(length>>1))...)
+	for i := 0; i < numFields; i++ {
+		typeNum := state.getTypeCodeNum(llvm.ConstExtractValue(funcFields, []uint32{uint32(i)}))
+		if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+			// TODO: make this a regular error
+			panic("func parameter or result type has a type code that is too big")
+		}
+		buf = append(buf, makeVarint(typeNum.Uint64())...)
+	}
+
+	index := len(state.funcTypesSidetable)
+	state.funcTypes[name] = index
+	state.funcTypesSidetable = append(state.funcTypesSidetable, buf...)
+	return index
+}
+
 // getStructTypeNum returns the struct type number, which is an index into
 // reflect.structTypesSidetable or an unique number for every struct if this
 // sidetable is not needed in the to-be-compiled program.
@@ -84,6 +84,13 @@ define i32 @"(Number).Double$invoke"(i8* %receiverPtr, i8* %context) {
   ret i32 %ret
 }
 
+; Functions used in a func value are marked with "tinygo-funcvalue".
+@funcValue = global { i8*, void ()* } { i8* null, void ()* bitcast (void (i32, i8*)* @funcValueTarget to void ()*) }
+
+define void @funcValueTarget(i32 %x, i8* %context) {
+  ret void
+}
+
 declare i32 @"Doubler.Double$invoke"(i8* %receiver, i32 %typecode, i8* %context) #0
 
 declare i1 @Doubler$typeassert(i32 %typecode) #1
@@ -42,6 +42,16 @@ func main() {
 	assertType(make(chan int), (intNum<<5)|prefixChan)
 	assertType(new(int), (intNum<<5)|prefixPtr)
 	assertType([]int{}, (intNum<<5)|prefixSlice)
+
+	// Maps are numbered sequentially when the sidetable isn't used.
+	assertType(map[int]int{}, (0<<5)|prefixMap)
+
+	// Same for funcs.
+	assertType(func(int) {}, (0<<5)|prefixFunc)
+
+	// The empty interface has number zero, other interfaces are numbered
+	// sequentially starting at one.
+	assertType(new(interface{}), (((0<<5)|prefixInterface)<<5)|prefixPtr)
 }
 
 type (
@@ -35,4 +35,7 @@ func AddStandardAttributes(fn llvm.Value, config *compileopts.Config) {
 	if config.Features() != "" {
 		fn.AddFunctionAttr(ctx.CreateStringAttribute("target-features", config.Features()))
 	}
+	if config.FramePointers() {
+		fn.AddFunctionAttr(ctx.CreateStringAttribute("frame-pointer", "all"))
+	}
 }